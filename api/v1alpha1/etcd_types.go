@@ -28,6 +28,7 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -47,8 +48,369 @@ type EtcdSpec struct {
 	// LoginPasswordSecretKeySelector is a selector for a Secret key that holds a password used as a login password of
 	// virtual machines.
 	LoginPasswordSecretKeySelector *corev1.SecretKeySelector `json:"loginPasswordSecretKeySelector,omitempty"`
+
+	// Bootstrap is a specification of how the etcd cluster is bootstrapped.
+	Bootstrap *EtcdBootstrapSpec `json:"bootstrap,omitempty"`
+
+	// DataVolumeClaimTemplate is a template of a PersistentVolumeClaim that is created for each etcd member and mounted
+	// as a data directory of etcd. An etcd member restarts with its data in the volume when its virtual machine is
+	// recreated.
+	DataVolumeClaimTemplate *corev1.PersistentVolumeClaimTemplate `json:"dataVolumeClaimTemplate,omitempty"`
+
+	// RunStrategy is a strategy of running virtual machines of etcd members. If it's specified, each etcd member is
+	// composed of a VirtualMachine so that KubeVirt restarts it. Otherwise, each etcd member is composed of a bare
+	// VirtualMachineInstance.
+	RunStrategy *EtcdNodeRunStrategy `json:"runStrategy,omitempty"`
+
+	// Resources is a specification of compute resources of virtual machines of etcd members.
+	// Changing it replaces etcd members one by one.
+	Resources *EtcdNodeResources `json:"resources,omitempty"`
+
+	// InstanceType is a reference to a KubeVirt instance type and preference that virtual machines of etcd members are
+	// created with. It can be specified only if RunStrategy is specified, and can't be specified with Resources.
+	// Changing it replaces etcd members one by one.
+	InstanceType *EtcdNodeInstanceType `json:"instanceType,omitempty"`
+
+	// NodeSelector is a selector which must match labels of a node that virtual machines of etcd members are
+	// scheduled on.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations are tolerations of virtual machines of etcd members.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity is scheduling constraints of virtual machines of etcd members. If it's not specified, virtual
+	// machines of etcd members prefer to be scheduled on different hosts and zones.
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// TopologySpreadConstraints describe how virtual machines of etcd members are spread across topology domains.
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// CA is a specification of a CA of the etcd cluster. If it's not specified, a self-signed CA is created.
+	CA *EtcdCASpec `json:"ca,omitempty"`
+
+	// MemberCertificateSigner is a signer of server and peer certificates of etcd members.
+	//+kubebuilder:default=Etcdadm
+	MemberCertificateSigner EtcdCertificateSigner `json:"memberCertificateSigner,omitempty"`
+
+	// PeerMTLS is a mode of mutual TLS authentication between etcd members. Required can be specified only if
+	// MemberCertificateSigner is Controller.
+	//+kubebuilder:default=Permissive
+	PeerMTLS EtcdPeerMTLSMode `json:"peerMTLS,omitempty"`
+
+	// BootstrapDriver is a driver which bootstraps etcd members on virtual machines. Systemd can be specified only if
+	// MemberCertificateSigner is Controller. It can't be changed after the etcd cluster is created.
+	//+kubebuilder:default=Etcdadm
+	BootstrapDriver EtcdBootstrapDriver `json:"bootstrapDriver,omitempty"`
+
+	// CertificateRotation is a specification of how certificates of the etcd cluster are rotated.
+	CertificateRotation *EtcdCertificateRotationSpec `json:"certificateRotation,omitempty"`
+
+	// CertificateKeyAlgorithm is an algorithm of private keys of a CA, client and peer certificates of the etcd
+	// cluster. Certificates of etcd members have the same algorithm as the CA. Ed25519 can't be specified. Changing
+	// it takes effect when certificates are rotated.
+	//+kubebuilder:default=RSA4096
+	CertificateKeyAlgorithm EtcdKeyAlgorithm `json:"certificateKeyAlgorithm,omitempty"`
+
+	// SSHKeyAlgorithm is an algorithm of an SSH key-pair to log in to virtual machines of etcd members. Changing it
+	// doesn't affect an existing key-pair.
+	//+kubebuilder:default=RSA4096
+	SSHKeyAlgorithm EtcdKeyAlgorithm `json:"sshKeyAlgorithm,omitempty"`
+
+	// SSH is a specification of SSH connections to virtual machines of etcd members, which are used to provision
+	// etcd members.
+	SSH *EtcdNodeSSHSpec `json:"ssh,omitempty"`
+
+	// Artifacts is a specification of where release artifacts installed on virtual machines of etcd members are
+	// downloaded from.
+	Artifacts *EtcdArtifactsSpec `json:"artifacts,omitempty"`
+
+	// Config is a configuration of etcd servers. Changing it triggers a rolling update of etcd members.
+	Config *EtcdConfig `json:"config,omitempty"`
+
+	// ExtraArgs is a map of names of etcd server flags without leading dashes to their values, e.g.
+	// experimental-initial-corrupt-check: "true". Flags which are managed by the controller or covered by Config
+	// can't be specified. Changing it triggers a rolling update of etcd members.
+	ExtraArgs map[string]string `json:"extraArgs,omitempty"`
+
+	// ExtraCloudConfig is a source of a cloud-config which is merged into cloud-configs generated for virtual machines
+	// of etcd members. Changing the reference triggers a rolling update of etcd members, but changing the content of
+	// the source takes effect only on etcd members created after that.
+	ExtraCloudConfig *EtcdCloudConfigSource `json:"extraCloudConfig,omitempty"`
+
+	// Join is a specification of how etcd members join the etcd cluster. Changing it triggers a rolling update of etcd
+	// members.
+	Join *EtcdJoinSpec `json:"join,omitempty"`
+
+	// Remediation is a policy of replacing etcd members which failed permanently. If it's not specified, failed etcd
+	// members are never replaced automatically.
+	Remediation *EtcdRemediationSpec `json:"remediation,omitempty"`
+}
+
+// EtcdConfig is a configuration of etcd servers. etcd defaults are used for unspecified fields.
+type EtcdConfig struct {
+	// QuotaBackendBytes is a size limit of the backend database of an etcd server.
+	QuotaBackendBytes *resource.Quantity `json:"quotaBackendBytes,omitempty"`
+
+	// AutoCompactionMode is a mode of auto compaction of the key-value store.
+	AutoCompactionMode EtcdAutoCompactionMode `json:"autoCompactionMode,omitempty"`
+
+	// AutoCompactionRetention is a retention of auto compaction. It's a duration, e.g. 1h, or a number of hours in
+	// the Periodic mode, and a number of revisions in the Revision mode.
+	AutoCompactionRetention string `json:"autoCompactionRetention,omitempty"`
+
+	// SnapshotCount is a number of committed transactions to trigger a snapshot to disk.
+	//+kubebuilder:validation:Minimum=1
+	SnapshotCount *int64 `json:"snapshotCount,omitempty"`
+
+	// HeartbeatInterval is an interval of heartbeats from a leader. It's rounded down to milliseconds.
+	HeartbeatInterval *metav1.Duration `json:"heartbeatInterval,omitempty"`
+
+	// ElectionTimeout is a timeout of an election. It must be at least 5 times as long as HeartbeatInterval. It's
+	// rounded down to milliseconds.
+	ElectionTimeout *metav1.Duration `json:"electionTimeout,omitempty"`
+}
+
+// EtcdAutoCompactionMode is a mode of auto compaction of etcd.
+// +kubebuilder:validation:Enum=Periodic;Revision
+type EtcdAutoCompactionMode string
+
+const (
+	// EtcdAutoCompactionModePeriodic means etcd compacts the key-value store periodically.
+	EtcdAutoCompactionModePeriodic EtcdAutoCompactionMode = "Periodic"
+	// EtcdAutoCompactionModeRevision means etcd keeps a number of the latest revisions of the key-value store.
+	EtcdAutoCompactionModeRevision EtcdAutoCompactionMode = "Revision"
+)
+
+// EtcdArtifactsSpec is a specification of where release artifacts of etcdadm and etcd are downloaded from.
+type EtcdArtifactsSpec struct {
+	// MirrorURL is a base URL of a mirror of release artifacts. An etcdadm binary is downloaded from
+	// <MirrorURL>/etcdadm/v<version>/etcdadm-linux-amd64 and an etcd release tarball is downloaded from
+	// <MirrorURL>/etcd/v<version>/etcd-v<version>-linux-amd64.tar.gz. If it's not specified, release artifacts are
+	// downloaded from the artifact server of the controller if it's enabled and the checksums are specified, or from
+	// GitHub otherwise.
+	MirrorURL string `json:"mirrorURL,omitempty"`
+
+	// DisableDefaultMirror disables downloading release artifacts from the artifact server of the controller, so that
+	// they're downloaded from GitHub unless MirrorURL is specified.
+	DisableDefaultMirror bool `json:"disableDefaultMirror,omitempty"`
+
+	// EtcdadmSHA256 is an expected SHA-256 hash of an etcdadm binary. An etcdadm binary is installed only if it
+	// matches. It's required if release artifacts are downloaded from a mirror, unless the Systemd bootstrap driver is
+	// used.
+	EtcdadmSHA256 string `json:"etcdadmSHA256,omitempty"`
+
+	// EtcdSHA256 is an expected SHA-256 hash of an etcd release tarball. An etcd release tarball is installed only if
+	// it matches. It's required if release artifacts are downloaded from a mirror.
+	EtcdSHA256 string `json:"etcdSHA256,omitempty"`
+}
+
+// EtcdCloudConfigSource is a source of an extra cloud-config. Exactly one of ConfigMapKeyRef and SecretKeyRef must be
+// specified. Mappings of the extra cloud-config are merged into a generated cloud-config recursively and lists of it,
+// e.g. write_files, runcmd, packages and users, are appended to ones of a generated cloud-config. It can't override
+// scalar values or paths of write_files of a generated cloud-config.
+type EtcdCloudConfigSource struct {
+	// ConfigMapKeyRef is a reference to a key of a ConfigMap which has an extra cloud-config.
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+
+	// SecretKeyRef is a reference to a key of a Secret which has an extra cloud-config.
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// EtcdKeyAlgorithm is an algorithm of a private key.
+// +kubebuilder:validation:Enum=RSA2048;RSA3072;RSA4096;ECDSAP256;ECDSAP384;Ed25519
+type EtcdKeyAlgorithm string
+
+const (
+	// EtcdKeyAlgorithmRSA2048 means an RSA key of 2048 bits.
+	EtcdKeyAlgorithmRSA2048 EtcdKeyAlgorithm = "RSA2048"
+	// EtcdKeyAlgorithmRSA3072 means an RSA key of 3072 bits.
+	EtcdKeyAlgorithmRSA3072 EtcdKeyAlgorithm = "RSA3072"
+	// EtcdKeyAlgorithmRSA4096 means an RSA key of 4096 bits.
+	EtcdKeyAlgorithmRSA4096 EtcdKeyAlgorithm = "RSA4096"
+	// EtcdKeyAlgorithmECDSAP256 means an ECDSA key on the P-256 curve.
+	EtcdKeyAlgorithmECDSAP256 EtcdKeyAlgorithm = "ECDSAP256"
+	// EtcdKeyAlgorithmECDSAP384 means an ECDSA key on the P-384 curve.
+	EtcdKeyAlgorithmECDSAP384 EtcdKeyAlgorithm = "ECDSAP384"
+	// EtcdKeyAlgorithmEd25519 means an Ed25519 key. It can be used only for SSH keys.
+	EtcdKeyAlgorithmEd25519 EtcdKeyAlgorithm = "Ed25519"
+)
+
+// EtcdBootstrapSpec is a specification of how an etcd cluster is bootstrapped.
+type EtcdBootstrapSpec struct {
+	// FromSnapshot is a source of a snapshot that the first node of the etcd cluster is restored from.
+	FromSnapshot *EtcdSnapshotSource `json:"fromSnapshot,omitempty"`
+
+	// Format is a format of bootstrap data which is passed to virtual machines of etcd members. Ignition can't be used
+	// with ExtraCloudConfig. Changing it triggers a rolling update of etcd members.
+	//+kubebuilder:default=CloudInit
+	Format EtcdBootstrapFormat `json:"format,omitempty"`
+}
+
+// EtcdSnapshotSource is a source of an etcd snapshot. Exactly one of the sources should be specified.
+type EtcdSnapshotSource struct {
+	// EtcdBackupRef is a local reference to a completed EtcdBackup.
+	EtcdBackupRef *corev1.LocalObjectReference `json:"etcdBackupRef,omitempty"`
+
+	// URL is a URL where a snapshot can be downloaded from.
+	URL string `json:"url,omitempty"`
+
+	// SHA256 is an expected SHA-256 hash of a snapshot downloaded from the URL. It's required with the URL.
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// EtcdCASpec is a specification of a CA of an etcd cluster. Only one of the sources can be specified.
+type EtcdCASpec struct {
+	// SecretRef is a local reference to an existing Secret of type kubernetes.io/tls which holds a CA certificate and
+	// a CA private key. The Secret isn't deleted with the etcd cluster.
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// IssuerRef is a reference to a cert-manager Issuer or ClusterIssuer which issues an intermediate CA of the etcd
+	// cluster. Client and peer certificates are requested as cert-manager Certificates signed by the intermediate CA.
+	IssuerRef *EtcdIssuerReference `json:"issuerRef,omitempty"`
+}
+
+// EtcdIssuerReference is a reference to a cert-manager issuer.
+type EtcdIssuerReference struct {
+	// Name is the name of the issuer.
+	Name string `json:"name"`
+
+	// Kind is the kind of the issuer.
+	//+kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	//+kubebuilder:default=Issuer
+	Kind string `json:"kind,omitempty"`
+
+	// Group is the API group of the issuer. Defaults to cert-manager.io.
+	Group string `json:"group,omitempty"`
+}
+
+const (
+	// EtcdRotateCertificatesAnnotation is an annotation to request rotating certificates of an etcd cluster. A new
+	// rotation is started whenever its value is changed.
+	EtcdRotateCertificatesAnnotation = "etcd.kubernetesimal.kkohtaka.org/rotate-certificates"
+
+	// EtcdRecoverAnnotation is an annotation to request recovering an etcd cluster which lost its quorum. A new
+	// recovery is started whenever its value is changed. The etcd cluster is recovered from the healthiest surviving
+	// member with --force-new-cluster unless the recover-from-backup annotation is specified.
+	EtcdRecoverAnnotation = "etcd.kubernetesimal.kkohtaka.org/recover"
+
+	// EtcdRecoverFromBackupAnnotation is an annotation to specify a name of a completed EtcdBackup which an etcd
+	// cluster is recovered from instead of a surviving member.
+	EtcdRecoverFromBackupAnnotation = "etcd.kubernetesimal.kkohtaka.org/recover-from-backup"
+)
+
+// EtcdCertificateRotationSpec is a specification of how certificates of an etcd cluster are rotated.
+type EtcdCertificateRotationSpec struct {
+	// RenewBefore is how long before the expiry of a certificate it's renewed. Defaults to 720h.
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+// EtcdRemediationSpec is a policy of replacing etcd members which failed permanently. A failed etcd member is removed
+// from the etcd cluster and its EtcdNode is deleted so that it's recreated. Only one etcd member is remediated at once,
+// and only if the etcd cluster keeps its quorum.
+type EtcdRemediationSpec struct {
+	// FailureThreshold is the number of consecutive failed probes after which an etcd member which was ready once is
+	// considered failed. Defaults to 3.
+	//+kubebuilder:validation:Minimum=1
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+
+	// Timeout is how long an etcd member can be not ready before it's considered failed. An etcd member which has
+	// never been ready is considered failed once the timeout passes after its EtcdNode is created. Defaults to 10m.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// EtcdCertificatesStatus defines the observed state of certificates of an etcd cluster.
+type EtcdCertificatesStatus struct {
+	// CANotAfter is the time when the CA certificate expires.
+	CANotAfter *metav1.Time `json:"caNotAfter,omitempty"`
+	// ClientNotAfter is the time when the client certificate expires.
+	ClientNotAfter *metav1.Time `json:"clientNotAfter,omitempty"`
+	// PeerNotAfter is the time when the certificate for peer communication expires.
+	PeerNotAfter *metav1.Time `json:"peerNotAfter,omitempty"`
+	// ServerNotAfter is the earliest time when a server or peer certificate of an etcd member expires.
+	ServerNotAfter *metav1.Time `json:"serverNotAfter,omitempty"`
+
+	// RotationID identifies the latest rotation of certificates.
+	RotationID string `json:"rotationID,omitempty"`
+	// LastRotationTime is the time when the latest rotation of certificates was completed.
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+	// ObservedRotationRequest is the value of the rotate-certificates annotation that was observed by the latest
+	// rotation.
+	ObservedRotationRequest string `json:"observedRotationRequest,omitempty"`
 }
 
+// EtcdRestoreStatus defines the observed state of restoring an etcd cluster from a snapshot.
+type EtcdRestoreStatus struct {
+	// Phase indicates phase of the restore.
+	Phase EtcdRestorePhase `json:"phase"`
+
+	// StartTime is the time when the restore was started.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time when the restore was completed.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// RestoredRevision is the revision of the etcd cluster that was restored from a snapshot.
+	RestoredRevision int64 `json:"restoredRevision,omitempty"`
+
+	// Message is a human-readable message indicating details about the last attempt of the restore.
+	Message string `json:"message,omitempty"`
+}
+
+// EtcdRestorePhase is a label for the phase of the restore at the current time.
+// +kubebuilder:validation:Enum=Transferring;Restoring;Completed
+type EtcdRestorePhase string
+
+const (
+	// EtcdRestorePhaseTransferring means a snapshot is being transferred to the first node.
+	EtcdRestorePhaseTransferring EtcdRestorePhase = "Transferring"
+	// EtcdRestorePhaseRestoring means the first node is being restored from a snapshot.
+	EtcdRestorePhaseRestoring EtcdRestorePhase = "Restoring"
+	// EtcdRestorePhaseCompleted means the first node was restored from a snapshot.
+	EtcdRestorePhaseCompleted EtcdRestorePhase = "Completed"
+)
+
+// EtcdRecoveryStatus defines the observed state of recovering an etcd cluster which lost its quorum.
+type EtcdRecoveryStatus struct {
+	// ObservedRequest is the value of the recover annotation that was observed by the recovery.
+	ObservedRequest string `json:"observedRequest"`
+
+	// Phase indicates phase of the recovery.
+	Phase EtcdRecoveryPhase `json:"phase"`
+
+	// SourceNode is the name of an EtcdNode whose etcd member forms a new etcd cluster.
+	SourceNode string `json:"sourceNode,omitempty"`
+
+	// SnapshotSource is a source of a snapshot which the new etcd cluster is restored from. It's set only if the etcd
+	// cluster is recovered from a snapshot.
+	SnapshotSource *EtcdSnapshotSource `json:"snapshotSource,omitempty"`
+
+	// StartTime is the time when the recovery was started.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time when the recovery was completed.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Message is a human-readable message indicating details about the recovery.
+	Message string `json:"message,omitempty"`
+}
+
+// EtcdRecoveryPhase is a label for the phase of a recovery at the current time.
+// +kubebuilder:validation:Enum=ForcingNewCluster;ReplacingMembers;Rebuilding;Completed;Failed
+type EtcdRecoveryPhase string
+
+const (
+	// EtcdRecoveryPhaseForcingNewCluster means the etcd member of the source node is forming a new single-member etcd
+	// cluster.
+	EtcdRecoveryPhaseForcingNewCluster EtcdRecoveryPhase = "ForcingNewCluster"
+	// EtcdRecoveryPhaseReplacingMembers means the other etcd members are being marked and deleted to be replaced.
+	EtcdRecoveryPhaseReplacingMembers EtcdRecoveryPhase = "ReplacingMembers"
+	// EtcdRecoveryPhaseRebuilding means the etcd cluster is being rebuilt to the desired number of replicas.
+	EtcdRecoveryPhaseRebuilding EtcdRecoveryPhase = "Rebuilding"
+	// EtcdRecoveryPhaseCompleted means the etcd cluster was recovered.
+	EtcdRecoveryPhaseCompleted EtcdRecoveryPhase = "Completed"
+	// EtcdRecoveryPhaseFailed means the etcd cluster couldn't be recovered, e.g. no etcd member survived.
+	EtcdRecoveryPhaseFailed EtcdRecoveryPhase = "Failed"
+)
+
 // EtcdStatus defines the observed state of Etcd
 type EtcdStatus struct {
 	// Phase indicates phase of the etcd cluster.
@@ -86,6 +448,20 @@ type EtcdStatus struct {
 	// Total number of ready EtcdNode targeted by this EtcdNodeDeployment.
 	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
 
+	// Restore is the observed state of restoring the etcd cluster from a snapshot.
+	Restore *EtcdRestoreStatus `json:"restore,omitempty"`
+
+	// Recovery is the observed state of the latest recovery of the etcd cluster from a quorum loss.
+	Recovery *EtcdRecoveryStatus `json:"recovery,omitempty"`
+
+	// Version is the version of etcd which all members of the etcd cluster are running.
+	Version string `json:"version,omitempty"`
+	// ClusterVersion is the cluster-wide version of etcd reported by the etcd cluster.
+	ClusterVersion string `json:"clusterVersion,omitempty"`
+
+	// Certificates is the observed state of certificates of the etcd cluster.
+	Certificates *EtcdCertificatesStatus `json:"certificates,omitempty"`
+
 	// Conditions is a list of statuses respected to certain conditions.
 	Conditions []EtcdCondition `json:"conditions,omitempty"`
 }
@@ -122,7 +498,7 @@ type EtcdCondition struct {
 }
 
 // EtcdConditionType represents a type of condition.
-// +kubebuilder:validation:Enum=Ready;MembersHealthy
+// +kubebuilder:validation:Enum=Ready;MembersHealthy;Upgrading;CertificatesRotating;CertificatesReady;CAExpiring;PromotionPending;Recovering
 type EtcdConditionType string
 
 const (
@@ -131,6 +507,26 @@ const (
 
 	// EtcdConditionTypeMembersHealthy indicates whether all EtcdNodes are registered successfully and healthy.
 	EtcdConditionTypeMembersHealthy EtcdConditionType = "MembersHealthy"
+
+	// EtcdConditionTypeUpgrading indicates whether the etcd cluster is being upgraded to another version.
+	EtcdConditionTypeUpgrading EtcdConditionType = "Upgrading"
+
+	// EtcdConditionTypeCertificatesRotating indicates whether certificates of the etcd cluster are being rotated.
+	EtcdConditionTypeCertificatesRotating EtcdConditionType = "CertificatesRotating"
+
+	// EtcdConditionTypeCertificatesReady indicates whether the CA, client and peer certificates are issued.
+	EtcdConditionTypeCertificatesReady EtcdConditionType = "CertificatesReady"
+
+	// EtcdConditionTypeCAExpiring indicates whether the CA certificate of the etcd cluster is near expiry. The CA isn't
+	// rotated automatically, and certificates signed by it don't outlive it.
+	EtcdConditionTypeCAExpiring EtcdConditionType = "CAExpiring"
+
+	// EtcdConditionTypePromotionPending indicates whether any etcd member is a learner waiting to be promoted to a
+	// voting member.
+	EtcdConditionTypePromotionPending EtcdConditionType = "PromotionPending"
+
+	// EtcdConditionTypeRecovering indicates whether the etcd cluster is being recovered from a quorum loss.
+	EtcdConditionTypeRecovering EtcdConditionType = "Recovering"
 )
 
 //+kubebuilder:object:root=true
@@ -140,6 +536,8 @@ const (
 //+kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.spec.version`
 //+kubebuilder:printcolumn:name="Desired Replicas",type=integer,JSONPath=`.spec.replicas`
 //+kubebuilder:printcolumn:name="Current Replicas",type=integer,JSONPath=`.status.replicas`
+//+kubebuilder:printcolumn:name="Cluster Version",type=string,priority=1,JSONPath=`.status.clusterVersion`
+//+kubebuilder:printcolumn:name="Restore",type=string,priority=1,JSONPath=`.status.restore.phase`
 
 // Etcd is the Schema for the etcds API
 type Etcd struct {
@@ -199,6 +597,60 @@ func (status *EtcdStatus) AreMembersHealthy() bool {
 	return false
 }
 
+func (status *EtcdStatus) IsUpgrading() bool {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == EtcdConditionTypeUpgrading {
+			return status.Conditions[i].Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func (status *EtcdStatus) AreCertificatesRotating() bool {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == EtcdConditionTypeCertificatesRotating {
+			return status.Conditions[i].Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func (status *EtcdStatus) AreCertificatesReady() bool {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == EtcdConditionTypeCertificatesReady {
+			return status.Conditions[i].Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func (status *EtcdStatus) IsCAExpiring() bool {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == EtcdConditionTypeCAExpiring {
+			return status.Conditions[i].Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func (status *EtcdStatus) IsPromotionPending() bool {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == EtcdConditionTypePromotionPending {
+			return status.Conditions[i].Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func (status *EtcdStatus) IsRecovering() bool {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == EtcdConditionTypeRecovering {
+			return status.Conditions[i].Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
 func (status *EtcdStatus) WithReady(
 	ready bool,
 	message string,
@@ -221,6 +673,72 @@ func (status *EtcdStatus) WithMembersHealthy(
 	)
 }
 
+func (status *EtcdStatus) WithUpgrading(
+	upgrading bool,
+	message string,
+) *EtcdStatus {
+	return status.WithStatusCondition(
+		EtcdConditionTypeUpgrading,
+		upgrading,
+		message,
+	)
+}
+
+func (status *EtcdStatus) WithCertificatesRotating(
+	rotating bool,
+	message string,
+) *EtcdStatus {
+	return status.WithStatusCondition(
+		EtcdConditionTypeCertificatesRotating,
+		rotating,
+		message,
+	)
+}
+
+func (status *EtcdStatus) WithCertificatesReady(
+	ready bool,
+	message string,
+) *EtcdStatus {
+	return status.WithStatusCondition(
+		EtcdConditionTypeCertificatesReady,
+		ready,
+		message,
+	)
+}
+
+func (status *EtcdStatus) WithCAExpiring(
+	expiring bool,
+	message string,
+) *EtcdStatus {
+	return status.WithStatusCondition(
+		EtcdConditionTypeCAExpiring,
+		expiring,
+		message,
+	)
+}
+
+func (status *EtcdStatus) WithPromotionPending(
+	pending bool,
+	message string,
+) *EtcdStatus {
+	return status.WithStatusCondition(
+		EtcdConditionTypePromotionPending,
+		pending,
+		message,
+	)
+}
+
+func (status *EtcdStatus) WithRecovering(
+	recovering bool,
+	message string,
+) *EtcdStatus {
+	return status.WithStatusCondition(
+		EtcdConditionTypeRecovering,
+		recovering,
+		message,
+	)
+}
+
 func (status *EtcdStatus) WithStatusCondition(
 	conditionType EtcdConditionType,
 	ready bool,
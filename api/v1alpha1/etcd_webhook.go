@@ -25,7 +25,16 @@ SOFTWARE.
 package v1alpha1
 
 import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/blang/semver/v4"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -51,6 +60,47 @@ var _ webhook.Defaulter = &Etcd{}
 
 var (
 	defaultEtcdVersion semver.Version = semver.MustParse("3.5.1")
+
+	sha256Pattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+	etcdFlagNamePattern = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`)
+
+	// reservedEtcdFlagNames are names of etcd server flags which can't be specified as extra arguments, since the
+	// controller manages them or EtcdConfig covers them.
+	reservedEtcdFlagNames = map[string]bool{
+		"name":                        true,
+		"data-dir":                    true,
+		"config-file":                 true,
+		"listen-client-urls":          true,
+		"advertise-client-urls":       true,
+		"listen-peer-urls":            true,
+		"initial-advertise-peer-urls": true,
+		"initial-cluster":             true,
+		"initial-cluster-state":       true,
+		"initial-cluster-token":       true,
+		"force-new-cluster":           true,
+		"cert-file":                   true,
+		"key-file":                    true,
+		"trusted-ca-file":             true,
+		"client-cert-auth":            true,
+		"peer-cert-file":              true,
+		"peer-key-file":               true,
+		"peer-trusted-ca-file":        true,
+		"peer-client-cert-auth":       true,
+		"peer-cert-allowed-cn":        true,
+		"quota-backend-bytes":         true,
+		"auto-compaction-mode":        true,
+		"auto-compaction-retention":   true,
+		"snapshot-count":              true,
+		"heartbeat-interval":          true,
+		"election-timeout":            true,
+	}
+)
+
+const (
+	defaultEtcdHeartbeatInterval = 100 * time.Millisecond
+	defaultEtcdElectionTimeout   = 1000 * time.Millisecond
+	maxEtcdElectionTimeout       = 50000 * time.Millisecond
 )
 
 // Default implements webhook.Defaulter so a webhook will be registered for the type
@@ -78,6 +128,17 @@ func (r *Etcd) ValidateCreate() (admission.Warnings, error) {
 
 	var errs field.ErrorList
 	errs = append(errs, r.validateSpecVersion()...)
+	errs = append(errs, r.validateSpecBootstrap()...)
+	errs = append(errs, r.validateSpecInstanceType()...)
+	errs = append(errs, r.validateSpecCA()...)
+	errs = append(errs, r.validateSpecPeerMTLS()...)
+	errs = append(errs, r.validateSpecCertificateKeyAlgorithm()...)
+	errs = append(errs, r.validateSpecArtifacts()...)
+	errs = append(errs, r.validateSpecBootstrapDriver()...)
+	errs = append(errs, r.validateSpecConfig()...)
+	errs = append(errs, r.validateSpecExtraArgs()...)
+	errs = append(errs, r.validateSpecExtraCloudConfig()...)
+	errs = append(errs, r.validateSpecJoin()...)
 	if len(errs) > 0 {
 		err := apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "Etcd"}, r.Name, errs)
 		etcdlog.Error(err, "validation error", "name", r.Name)
@@ -93,7 +154,21 @@ func (r *Etcd) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
 
 	var errs field.ErrorList
 	errs = append(errs, r.validateSpecVersion()...)
+	errs = append(errs, r.validateSpecVersionUpgradePath(old)...)
 	errs = append(errs, r.validateSpecImagePersistentVolumeClaimRef()...)
+	errs = append(errs, r.validateSpecBootstrap()...)
+	errs = append(errs, r.validateSpecInstanceType()...)
+	errs = append(errs, r.validateSpecCA()...)
+	errs = append(errs, r.validateSpecPeerMTLS()...)
+	errs = append(errs, r.validateSpecCertificateKeyAlgorithm()...)
+	errs = append(errs, r.validateSpecArtifacts()...)
+	errs = append(errs, r.validateSpecBootstrapDriver()...)
+	errs = append(errs, r.validateSpecConfig()...)
+	errs = append(errs, r.validateSpecExtraArgs()...)
+	errs = append(errs, r.validateSpecExtraCloudConfig()...)
+	errs = append(errs, r.validateSpecJoin()...)
+	errs = append(errs, r.validateSpecCAUpdate(old)...)
+	errs = append(errs, r.validateSpecBootstrapDriverUpdate(old)...)
 	if len(errs) > 0 {
 		err := apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "Etcd"}, r.Name, errs)
 		etcdlog.Error(err, "validation error", "name", r.Name)
@@ -131,6 +206,61 @@ func (r *Etcd) validateSpecVersion() field.ErrorList {
 	return errs
 }
 
+func (r *Etcd) validateSpecVersionUpgradePath(old runtime.Object) field.ErrorList {
+	var errs field.ErrorList
+	oldEtcd, ok := old.(*Etcd)
+	if !ok || r.Spec.Version == nil {
+		return errs
+	}
+	to, err := semver.Parse(*r.Spec.Version)
+	if err != nil {
+		return errs
+	}
+
+	// Validate the path from the version which members are running if it's observed, since the desired version may be
+	// changed while the etcd cluster is being upgraded.
+	var current string
+	if oldEtcd.Status.Version != "" {
+		current = oldEtcd.Status.Version
+	} else if oldEtcd.Spec.Version != nil {
+		current = *oldEtcd.Spec.Version
+	}
+	from, err := semver.Parse(current)
+	if err != nil {
+		return errs
+	}
+
+	if msg := validateEtcdUpgradePath(from, to); msg != "" {
+		errs = append(errs,
+			field.Forbidden(
+				field.NewPath("spec", "version"),
+				msg,
+			),
+		)
+	}
+	return errs
+}
+
+// validateEtcdUpgradePath returns a message if etcd can't be upgraded from one version to another in place.
+// etcd supports upgrading only one minor version at a time, and doesn't support downgrading to an older minor version.
+func validateEtcdUpgradePath(from, to semver.Version) string {
+	switch {
+	case from.Major != to.Major:
+		return fmt.Sprintf("upgrading etcd from %s to %s across major versions is not supported", from, to)
+	case to.Minor < from.Minor:
+		return fmt.Sprintf("downgrading etcd from %s to %s is not supported", from, to)
+	case to.Minor > from.Minor+1:
+		return fmt.Sprintf(
+			"upgrading etcd from %s to %s skips minor versions, upgrade it to %d.%d first",
+			from,
+			to,
+			from.Major,
+			from.Minor+1,
+		)
+	}
+	return ""
+}
+
 func (r *Etcd) validateSpecImagePersistentVolumeClaimRef() field.ErrorList {
 	var errs field.ErrorList
 	if ref := r.Spec.ImagePersistentVolumeClaimRef; ref.Name == "" {
@@ -143,3 +273,448 @@ func (r *Etcd) validateSpecImagePersistentVolumeClaimRef() field.ErrorList {
 	}
 	return errs
 }
+
+func (r *Etcd) validateSpecBootstrap() field.ErrorList {
+	var errs field.ErrorList
+	if r.Spec.Bootstrap == nil || r.Spec.Bootstrap.FromSnapshot == nil {
+		return errs
+	}
+	path := field.NewPath("spec", "bootstrap", "fromSnapshot")
+	source := r.Spec.Bootstrap.FromSnapshot
+	switch {
+	case source.EtcdBackupRef != nil && source.URL != "":
+		errs = append(errs,
+			field.Invalid(
+				path,
+				source,
+				"only one of etcdBackupRef and url can be specified",
+			),
+		)
+	case source.EtcdBackupRef == nil && source.URL == "":
+		errs = append(errs,
+			field.Required(
+				path,
+				"either etcdBackupRef or url must be specified",
+			),
+		)
+	case source.EtcdBackupRef != nil && source.EtcdBackupRef.Name == "":
+		errs = append(errs,
+			field.Required(
+				path.Child("etcdBackupRef", "name"),
+				"etcdBackupRef must have a name",
+			),
+		)
+	case source.URL != "":
+		if u, err := url.Parse(source.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs,
+				field.Invalid(
+					path.Child("url"),
+					source.URL,
+					"the url must be an HTTP or HTTPS URL",
+				),
+			)
+		}
+		if source.SHA256 == "" {
+			errs = append(errs,
+				field.Required(
+					path.Child("sha256"),
+					"a snapshot downloaded from the url must be verified with its SHA-256 hash",
+				),
+			)
+		} else if !sha256Pattern.MatchString(source.SHA256) {
+			errs = append(errs,
+				field.Invalid(
+					path.Child("sha256"),
+					source.SHA256,
+					"must be a hex-encoded SHA-256 hash",
+				),
+			)
+		}
+	}
+	return errs
+}
+
+func (r *Etcd) validateSpecInstanceType() field.ErrorList {
+	var errs field.ErrorList
+	if r.Spec.InstanceType == nil {
+		return errs
+	}
+	path := field.NewPath("spec", "instanceType")
+	if r.Spec.InstanceType.Name == "" {
+		errs = append(errs,
+			field.Required(
+				path.Child("name"),
+				"instanceType must have a name",
+			),
+		)
+	}
+	if r.Spec.RunStrategy == nil {
+		errs = append(errs,
+			field.Invalid(
+				path,
+				r.Spec.InstanceType,
+				"instanceType can be specified only with runStrategy",
+			),
+		)
+	}
+	if r.Spec.Resources != nil {
+		errs = append(errs,
+			field.Invalid(
+				path,
+				r.Spec.InstanceType,
+				"only one of instanceType and resources can be specified",
+			),
+		)
+	}
+	return errs
+}
+
+func (r *Etcd) validateSpecCA() field.ErrorList {
+	var errs field.ErrorList
+	if r.Spec.CA == nil {
+		return errs
+	}
+	path := field.NewPath("spec", "ca")
+	ca := r.Spec.CA
+	switch {
+	case ca.SecretRef != nil && ca.IssuerRef != nil:
+		errs = append(errs,
+			field.Invalid(
+				path,
+				ca,
+				"only one of secretRef and issuerRef can be specified",
+			),
+		)
+	case ca.SecretRef == nil && ca.IssuerRef == nil:
+		errs = append(errs,
+			field.Required(
+				path,
+				"either secretRef or issuerRef must be specified",
+			),
+		)
+	case ca.SecretRef != nil && ca.SecretRef.Name == "":
+		errs = append(errs,
+			field.Required(
+				path.Child("secretRef", "name"),
+				"secretRef must have a name",
+			),
+		)
+	case ca.IssuerRef != nil && ca.IssuerRef.Name == "":
+		errs = append(errs,
+			field.Required(
+				path.Child("issuerRef", "name"),
+				"issuerRef must have a name",
+			),
+		)
+	}
+	return errs
+}
+
+func (r *Etcd) validateSpecCAUpdate(old runtime.Object) field.ErrorList {
+	var errs field.ErrorList
+	oldEtcd, ok := old.(*Etcd)
+	if !ok {
+		return errs
+	}
+	if !equality.Semantic.DeepEqual(r.Spec.CA, oldEtcd.Spec.CA) {
+		errs = append(errs,
+			field.Forbidden(
+				field.NewPath("spec", "ca"),
+				"the CA of an etcd cluster can't be changed",
+			),
+		)
+	}
+	return errs
+}
+
+func (r *Etcd) validateSpecPeerMTLS() field.ErrorList {
+	var errs field.ErrorList
+	if r.Spec.PeerMTLS == EtcdPeerMTLSModeRequired &&
+		r.Spec.MemberCertificateSigner != EtcdCertificateSignerController {
+		errs = append(errs,
+			field.Invalid(
+				field.NewPath("spec", "peerMTLS"),
+				r.Spec.PeerMTLS,
+				"peerMTLS can be Required only if memberCertificateSigner is Controller",
+			),
+		)
+	}
+	return errs
+}
+
+func (r *Etcd) validateSpecBootstrapDriver() field.ErrorList {
+	var errs field.ErrorList
+	if r.Spec.BootstrapDriver == EtcdBootstrapDriverSystemd &&
+		r.Spec.MemberCertificateSigner != EtcdCertificateSignerController {
+		errs = append(errs,
+			field.Invalid(
+				field.NewPath("spec", "bootstrapDriver"),
+				r.Spec.BootstrapDriver,
+				"bootstrapDriver can be Systemd only if memberCertificateSigner is Controller",
+			),
+		)
+	}
+	return errs
+}
+
+func (r *Etcd) validateSpecBootstrapDriverUpdate(old runtime.Object) field.ErrorList {
+	var errs field.ErrorList
+	oldEtcd, ok := old.(*Etcd)
+	if !ok {
+		return errs
+	}
+	if r.Spec.BootstrapDriver != oldEtcd.Spec.BootstrapDriver {
+		errs = append(errs,
+			field.Forbidden(
+				field.NewPath("spec", "bootstrapDriver"),
+				"the bootstrap driver of an etcd cluster can't be changed",
+			),
+		)
+	}
+	return errs
+}
+
+func (r *Etcd) validateSpecCertificateKeyAlgorithm() field.ErrorList {
+	var errs field.ErrorList
+	if r.Spec.CertificateKeyAlgorithm == EtcdKeyAlgorithmEd25519 {
+		errs = append(errs,
+			field.NotSupported(
+				field.NewPath("spec", "certificateKeyAlgorithm"),
+				r.Spec.CertificateKeyAlgorithm,
+				[]string{
+					string(EtcdKeyAlgorithmRSA2048),
+					string(EtcdKeyAlgorithmRSA3072),
+					string(EtcdKeyAlgorithmRSA4096),
+					string(EtcdKeyAlgorithmECDSAP256),
+					string(EtcdKeyAlgorithmECDSAP384),
+				},
+			),
+		)
+	}
+	return errs
+}
+
+func (r *Etcd) validateSpecArtifacts() field.ErrorList {
+	if r.Spec.Artifacts == nil {
+		return nil
+	}
+
+	var errs field.ErrorList
+	if r.Spec.Artifacts.MirrorURL != "" {
+		if u, err := url.Parse(r.Spec.Artifacts.MirrorURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			errs = append(errs,
+				field.Invalid(
+					field.NewPath("spec", "artifacts", "mirrorURL"),
+					r.Spec.Artifacts.MirrorURL,
+					"mirrorURL must be an HTTP or HTTPS URL",
+				),
+			)
+		}
+	}
+	// Release artifacts downloaded from a mirror are verified with checksums since the mirror isn't trusted. An etcdadm
+	// binary isn't downloaded with the Systemd bootstrap driver.
+	fromMirror := r.Spec.Artifacts.MirrorURL != ""
+	for _, checksum := range []struct {
+		name, value string
+		required    bool
+	}{
+		{
+			name:     "etcdadmSHA256",
+			value:    r.Spec.Artifacts.EtcdadmSHA256,
+			required: fromMirror && r.Spec.BootstrapDriver != EtcdBootstrapDriverSystemd,
+		},
+		{
+			name:     "etcdSHA256",
+			value:    r.Spec.Artifacts.EtcdSHA256,
+			required: fromMirror,
+		},
+	} {
+		if checksum.value == "" && checksum.required {
+			errs = append(errs,
+				field.Required(
+					field.NewPath("spec", "artifacts", checksum.name),
+					"must be specified with mirrorURL",
+				),
+			)
+		} else if checksum.value != "" && !sha256Pattern.MatchString(checksum.value) {
+			errs = append(errs,
+				field.Invalid(
+					field.NewPath("spec", "artifacts", checksum.name),
+					checksum.value,
+					"must be a hex-encoded SHA-256 hash",
+				),
+			)
+		}
+	}
+	return errs
+}
+
+func (r *Etcd) validateSpecConfig() field.ErrorList {
+	config := r.Spec.Config
+	if config == nil {
+		return nil
+	}
+
+	var errs field.ErrorList
+	if config.QuotaBackendBytes != nil && config.QuotaBackendBytes.Sign() <= 0 {
+		errs = append(errs,
+			field.Invalid(
+				field.NewPath("spec", "config", "quotaBackendBytes"),
+				config.QuotaBackendBytes.String(),
+				"quotaBackendBytes must be positive",
+			),
+		)
+	}
+
+	if retention := config.AutoCompactionRetention; retention != "" {
+		var valid bool
+		if config.AutoCompactionMode == EtcdAutoCompactionModeRevision {
+			n, err := strconv.ParseInt(retention, 10, 64)
+			valid = err == nil && n >= 0
+		} else if n, err := strconv.ParseInt(retention, 10, 64); err == nil {
+			valid = n >= 0
+		} else {
+			d, err := time.ParseDuration(retention)
+			valid = err == nil && d >= 0
+		}
+		if !valid {
+			errs = append(errs,
+				field.Invalid(
+					field.NewPath("spec", "config", "autoCompactionRetention"),
+					retention,
+					"autoCompactionRetention must be a number of revisions in the Revision mode, "+
+						"or a duration or a number of hours in the Periodic mode",
+				),
+			)
+		}
+	}
+
+	heartbeatInterval := defaultEtcdHeartbeatInterval
+	if config.HeartbeatInterval != nil {
+		heartbeatInterval = config.HeartbeatInterval.Duration
+		if heartbeatInterval < time.Millisecond {
+			errs = append(errs,
+				field.Invalid(
+					field.NewPath("spec", "config", "heartbeatInterval"),
+					config.HeartbeatInterval.Duration.String(),
+					"heartbeatInterval must be at least 1ms",
+				),
+			)
+		}
+	}
+	electionTimeout := defaultEtcdElectionTimeout
+	if config.ElectionTimeout != nil {
+		electionTimeout = config.ElectionTimeout.Duration
+	}
+	if electionTimeout < 5*heartbeatInterval || electionTimeout > maxEtcdElectionTimeout {
+		errs = append(errs,
+			field.Invalid(
+				field.NewPath("spec", "config", "electionTimeout"),
+				electionTimeout.String(),
+				fmt.Sprintf(
+					"electionTimeout must be at least 5 times as long as heartbeatInterval and at most %s",
+					maxEtcdElectionTimeout,
+				),
+			),
+		)
+	}
+	return errs
+}
+
+func (r *Etcd) validateSpecExtraArgs() field.ErrorList {
+	names := make([]string, 0, len(r.Spec.ExtraArgs))
+	for name := range r.Spec.ExtraArgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs field.ErrorList
+	for _, name := range names {
+		path := field.NewPath("spec", "extraArgs").Key(name)
+		switch {
+		case !etcdFlagNamePattern.MatchString(name):
+			errs = append(errs,
+				field.Invalid(path, name, "a name of an etcd server flag must be in kebab-case without leading dashes"),
+			)
+		case reservedEtcdFlagNames[name]:
+			errs = append(errs,
+				field.Forbidden(path, "the flag is managed by the controller or covered by spec.config"),
+			)
+		case strings.ContainsAny(r.Spec.ExtraArgs[name], "'\n"):
+			errs = append(errs,
+				field.Invalid(
+					path,
+					r.Spec.ExtraArgs[name],
+					"a value of an etcd server flag can't contain single quotes or newlines",
+				),
+			)
+		}
+	}
+	return errs
+}
+
+func (r *Etcd) validateSpecExtraCloudConfig() field.ErrorList {
+	var errs field.ErrorList
+	if r.Spec.ExtraCloudConfig == nil {
+		return errs
+	}
+	path := field.NewPath("spec", "extraCloudConfig")
+	source := r.Spec.ExtraCloudConfig
+	switch {
+	case source.ConfigMapKeyRef != nil && source.SecretKeyRef != nil:
+		errs = append(errs,
+			field.Invalid(
+				path,
+				source,
+				"only one of configMapKeyRef and secretKeyRef can be specified",
+			),
+		)
+	case source.ConfigMapKeyRef == nil && source.SecretKeyRef == nil:
+		errs = append(errs,
+			field.Required(
+				path,
+				"either configMapKeyRef or secretKeyRef must be specified",
+			),
+		)
+	case source.ConfigMapKeyRef != nil && (source.ConfigMapKeyRef.Name == "" || source.ConfigMapKeyRef.Key == ""):
+		errs = append(errs,
+			field.Required(
+				path.Child("configMapKeyRef"),
+				"configMapKeyRef must have a name and a key",
+			),
+		)
+	case source.SecretKeyRef != nil && (source.SecretKeyRef.Name == "" || source.SecretKeyRef.Key == ""):
+		errs = append(errs,
+			field.Required(
+				path.Child("secretKeyRef"),
+				"secretKeyRef must have a name and a key",
+			),
+		)
+	}
+	if r.Spec.Bootstrap != nil && r.Spec.Bootstrap.Format == EtcdBootstrapFormatIgnition {
+		errs = append(errs,
+			field.Forbidden(
+				path,
+				"extraCloudConfig can't be specified if bootstrap.format is Ignition",
+			),
+		)
+	}
+	return errs
+}
+
+func (r *Etcd) validateSpecJoin() field.ErrorList {
+	var errs field.ErrorList
+	if r.Spec.Join == nil {
+		return errs
+	}
+	if r.Spec.Join.Mode == EtcdJoinModeLearner && r.Spec.BootstrapDriver != EtcdBootstrapDriverSystemd {
+		errs = append(errs,
+			field.Invalid(
+				field.NewPath("spec", "join", "mode"),
+				r.Spec.Join.Mode,
+				"join mode can be Learner only if bootstrapDriver is Systemd",
+			),
+		)
+	}
+	return errs
+}
@@ -0,0 +1,302 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateEtcdUpgradePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		allowed bool
+	}{
+		{
+			name:    "the same version",
+			from:    "3.5.1",
+			to:      "3.5.1",
+			allowed: true,
+		},
+		{
+			name:    "a patch upgrade",
+			from:    "3.5.1",
+			to:      "3.5.12",
+			allowed: true,
+		},
+		{
+			name:    "a patch downgrade",
+			from:    "3.5.12",
+			to:      "3.5.1",
+			allowed: true,
+		},
+		{
+			name:    "a minor upgrade",
+			from:    "3.4.27",
+			to:      "3.5.12",
+			allowed: true,
+		},
+		{
+			name:    "a minor upgrade skipping a minor version",
+			from:    "3.3.27",
+			to:      "3.5.12",
+			allowed: false,
+		},
+		{
+			name:    "a minor downgrade",
+			from:    "3.5.12",
+			to:      "3.4.27",
+			allowed: false,
+		},
+		{
+			name:    "a major upgrade",
+			from:    "3.5.12",
+			to:      "4.0.0",
+			allowed: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := validateEtcdUpgradePath(semver.MustParse(tt.from), semver.MustParse(tt.to))
+			assert.Equal(t, tt.allowed, msg == "", msg)
+		})
+	}
+}
+
+func TestValidateSpecConfig(t *testing.T) {
+	quantity := func(s string) *resource.Quantity {
+		q := resource.MustParse(s)
+		return &q
+	}
+	duration := func(d time.Duration) *metav1.Duration {
+		return &metav1.Duration{Duration: d}
+	}
+	tests := []struct {
+		name    string
+		config  *EtcdConfig
+		allowed bool
+	}{
+		{
+			name:    "no config",
+			config:  nil,
+			allowed: true,
+		},
+		{
+			name: "a valid config",
+			config: &EtcdConfig{
+				QuotaBackendBytes:       quantity("8Gi"),
+				AutoCompactionMode:      EtcdAutoCompactionModePeriodic,
+				AutoCompactionRetention: "1h",
+				HeartbeatInterval:       duration(200 * time.Millisecond),
+				ElectionTimeout:         duration(2 * time.Second),
+			},
+			allowed: true,
+		},
+		{
+			name: "a negative quota",
+			config: &EtcdConfig{
+				QuotaBackendBytes: quantity("-1"),
+			},
+			allowed: false,
+		},
+		{
+			name: "a duration retention in the Revision mode",
+			config: &EtcdConfig{
+				AutoCompactionMode:      EtcdAutoCompactionModeRevision,
+				AutoCompactionRetention: "1h",
+			},
+			allowed: false,
+		},
+		{
+			name: "an election timeout shorter than 5 heartbeats",
+			config: &EtcdConfig{
+				HeartbeatInterval: duration(500 * time.Millisecond),
+			},
+			allowed: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Etcd{Spec: EtcdSpec{Config: tt.config}}
+			errs := r.validateSpecConfig()
+			assert.Equal(t, tt.allowed, len(errs) == 0, errs)
+		})
+	}
+}
+
+func TestValidateSpecExtraArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		extraArgs map[string]string
+		allowed   bool
+	}{
+		{
+			name: "an experimental flag",
+			extraArgs: map[string]string{
+				"experimental-initial-corrupt-check": "true",
+			},
+			allowed: true,
+		},
+		{
+			name: "a flag with leading dashes",
+			extraArgs: map[string]string{
+				"--log-level": "debug",
+			},
+			allowed: false,
+		},
+		{
+			name: "a reserved flag",
+			extraArgs: map[string]string{
+				"initial-cluster-state": "new",
+			},
+			allowed: false,
+		},
+		{
+			name: "a value with a single quote",
+			extraArgs: map[string]string{
+				"log-level": "'debug'",
+			},
+			allowed: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Etcd{Spec: EtcdSpec{ExtraArgs: tt.extraArgs}}
+			errs := r.validateSpecExtraArgs()
+			assert.Equal(t, tt.allowed, len(errs) == 0, errs)
+		})
+	}
+}
+
+func TestValidateSpecBootstrap(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  EtcdSnapshotSource
+		allowed bool
+	}{
+		{
+			name: "an EtcdBackup",
+			source: EtcdSnapshotSource{
+				EtcdBackupRef: &corev1.LocalObjectReference{Name: "backup"},
+			},
+			allowed: true,
+		},
+		{
+			name: "a URL with a hash",
+			source: EtcdSnapshotSource{
+				URL:    "https://example.com/snapshot.db",
+				SHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			},
+			allowed: true,
+		},
+		{
+			name: "a URL without a hash",
+			source: EtcdSnapshotSource{
+				URL: "https://example.com/snapshot.db",
+			},
+			allowed: false,
+		},
+		{
+			name: "a URL with a malformed hash",
+			source: EtcdSnapshotSource{
+				URL:    "https://example.com/snapshot.db",
+				SHA256: "e3b0c442",
+			},
+			allowed: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Etcd{Spec: EtcdSpec{Bootstrap: &EtcdBootstrapSpec{FromSnapshot: &tt.source}}}
+			errs := r.validateSpecBootstrap()
+			assert.Equal(t, tt.allowed, len(errs) == 0, errs)
+		})
+	}
+}
+
+func TestValidateSpecArtifacts(t *testing.T) {
+	const checksum = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	tests := []struct {
+		name            string
+		artifacts       EtcdArtifactsSpec
+		bootstrapDriver EtcdBootstrapDriver
+		allowed         bool
+	}{
+		{
+			name: "a mirror with checksums",
+			artifacts: EtcdArtifactsSpec{
+				MirrorURL:     "http://mirror.example.com",
+				EtcdadmSHA256: checksum,
+				EtcdSHA256:    checksum,
+			},
+			allowed: true,
+		},
+		{
+			name: "a mirror without a checksum of etcd",
+			artifacts: EtcdArtifactsSpec{
+				MirrorURL:     "http://mirror.example.com",
+				EtcdadmSHA256: checksum,
+			},
+			allowed: false,
+		},
+		{
+			name: "a mirror without a checksum of etcdadm",
+			artifacts: EtcdArtifactsSpec{
+				MirrorURL:  "http://mirror.example.com",
+				EtcdSHA256: checksum,
+			},
+			allowed: false,
+		},
+		{
+			name: "a mirror without a checksum of etcdadm with the Systemd bootstrap driver",
+			artifacts: EtcdArtifactsSpec{
+				MirrorURL:  "http://mirror.example.com",
+				EtcdSHA256: checksum,
+			},
+			bootstrapDriver: EtcdBootstrapDriverSystemd,
+			allowed:         true,
+		},
+		{
+			name:      "GitHub without checksums",
+			artifacts: EtcdArtifactsSpec{},
+			allowed:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Etcd{Spec: EtcdSpec{Artifacts: &tt.artifacts, BootstrapDriver: tt.bootstrapDriver}}
+			errs := r.validateSpecArtifacts()
+			assert.Equal(t, tt.allowed, len(errs) == 0, errs)
+		})
+	}
+}
@@ -0,0 +1,241 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EtcdBackupSpec defines the desired state of EtcdBackup
+type EtcdBackupSpec struct {
+	// EtcdRef is a local reference to an Etcd whose snapshot is taken.
+	EtcdRef corev1.LocalObjectReference `json:"etcdRef"`
+
+	// Sink is a destination where a snapshot is stored.
+	Sink EtcdBackupSink `json:"sink"`
+}
+
+// EtcdBackupSink is a destination of an etcd snapshot. Exactly one of the sinks should be specified.
+type EtcdBackupSink struct {
+	// PersistentVolumeClaim is a sink that stores a snapshot into a PersistentVolumeClaim.
+	PersistentVolumeClaim *EtcdBackupPersistentVolumeClaimSink `json:"persistentVolumeClaim,omitempty"`
+
+	// S3 is a sink that stores a snapshot into an S3-compatible object storage.
+	S3 *EtcdBackupS3Sink `json:"s3,omitempty"`
+}
+
+// EtcdBackupPersistentVolumeClaimSink is a sink that stores a snapshot into a PersistentVolumeClaim.
+// The PersistentVolumeClaim should be mounted to the controller under its backup volume directory with the name of the
+// claim.
+type EtcdBackupPersistentVolumeClaimSink struct {
+	// ClaimName is the name of a PersistentVolumeClaim in the same namespace as the EtcdBackup.
+	ClaimName string `json:"claimName"`
+
+	// Path is a directory in the volume where a snapshot is stored.
+	Path string `json:"path,omitempty"`
+}
+
+// EtcdBackupS3Sink is a sink that stores a snapshot into an S3-compatible object storage.
+type EtcdBackupS3Sink struct {
+	// Endpoint is a URL of an S3-compatible endpoint. (e.g. https://s3.us-east-1.amazonaws.com)
+	Endpoint string `json:"endpoint"`
+
+	// Region is a region of a bucket.
+	//+kubebuilder:default=us-east-1
+	Region string `json:"region,omitempty"`
+
+	// Bucket is the name of a bucket.
+	Bucket string `json:"bucket"`
+
+	// Prefix is a prefix of object keys.
+	Prefix string `json:"prefix,omitempty"`
+
+	// CredentialsSecretRef is a local reference to a Secret that holds an access key ID and a secret access key with
+	// the keys `accessKeyID` and `secretAccessKey`.
+	CredentialsSecretRef corev1.LocalObjectReference `json:"credentialsSecretRef"`
+
+	// InsecureSkipTLSVerify is whether the endpoint's certificate should not be verified.
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
+}
+
+// EtcdBackupStatus defines the observed state of EtcdBackup
+type EtcdBackupStatus struct {
+	// Phase indicates phase of the backup.
+	//+kubebuilder:default=Pending
+	Phase EtcdBackupPhase `json:"phase"`
+
+	// StartTime is the time when the backup was started.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time when the backup was completed.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Location is a URL of the stored snapshot.
+	Location string `json:"location,omitempty"`
+	// Size is the size of the stored snapshot in bytes.
+	Size int64 `json:"size,omitempty"`
+	// Revision is a lower bound of the revision in the snapshot. It is the revision of the etcd cluster right before
+	// the snapshot started to be taken, so the snapshot may contain later revisions.
+	Revision int64 `json:"revision,omitempty"`
+	// Hash is a SHA-256 hash of the stored snapshot.
+	Hash string `json:"hash,omitempty"`
+
+	// Conditions is a list of statuses respected to certain conditions.
+	Conditions []EtcdBackupCondition `json:"conditions,omitempty"`
+}
+
+// EtcdBackupPhase is a label for the phase of the backup at the current time.
+// +kubebuilder:validation:Enum=Pending;Running;Completed;Failed
+type EtcdBackupPhase string
+
+const (
+	// EtcdBackupPhasePending means the backup is waiting for being started.
+	EtcdBackupPhasePending EtcdBackupPhase = "Pending"
+	// EtcdBackupPhaseRunning means a snapshot is being taken.
+	EtcdBackupPhaseRunning EtcdBackupPhase = "Running"
+	// EtcdBackupPhaseCompleted means a snapshot was stored into a sink.
+	EtcdBackupPhaseCompleted EtcdBackupPhase = "Completed"
+	// EtcdBackupPhaseFailed means the backup was failed.
+	EtcdBackupPhaseFailed EtcdBackupPhase = "Failed"
+)
+
+// EtcdBackupCondition defines a status respected to a certain condition.
+type EtcdBackupCondition struct {
+	// Type is the type of the condition.
+	Type EtcdBackupConditionType `json:"type"`
+	// Status is the status of the condition.
+	Status corev1.ConditionStatus `json:"status"`
+	// Last time we probed the condition.
+	LastProbeTime *metav1.Time `json:"lastProbeTime,omitempty"`
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Unique, one-word, CamelCase reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+	// Human-readable message indicating details about last transition.
+	Message string `json:"message,omitempty"`
+}
+
+// EtcdBackupConditionType represents a type of condition.
+// +kubebuilder:validation:Enum=Completed
+type EtcdBackupConditionType string
+
+const (
+	// EtcdBackupConditionTypeCompleted is a status respective to a completion of a backup.
+	EtcdBackupConditionTypeCompleted EtcdBackupConditionType = "Completed"
+)
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Etcd",type=string,JSONPath=`.spec.etcdRef.name`
+//+kubebuilder:printcolumn:name="Revision",type=integer,JSONPath=`.status.revision`
+//+kubebuilder:printcolumn:name="Size",type=integer,JSONPath=`.status.size`
+//+kubebuilder:printcolumn:name="Location",type=string,priority=1,JSONPath=`.status.location`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// EtcdBackup is the Schema for the etcdbackups API
+type EtcdBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EtcdBackupSpec   `json:"spec,omitempty"`
+	Status EtcdBackupStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// EtcdBackupList contains a list of EtcdBackup
+type EtcdBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EtcdBackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EtcdBackup{}, &EtcdBackupList{})
+}
+
+func (status *EtcdBackupStatus) IsCompleted() bool {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == EtcdBackupConditionTypeCompleted {
+			return status.Conditions[i].Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func (status *EtcdBackupStatus) WithCompleted(
+	completed bool,
+	message string,
+) *EtcdBackupStatus {
+	return status.WithStatusCondition(
+		EtcdBackupConditionTypeCompleted,
+		completed,
+		message,
+	)
+}
+
+func (status *EtcdBackupStatus) WithStatusCondition(
+	conditionType EtcdBackupConditionType,
+	ready bool,
+	message string,
+) *EtcdBackupStatus {
+	newStatus := status.DeepCopy()
+	now := metav1.NewTime(time.Now())
+	condStatus := corev1.ConditionFalse
+	if ready {
+		condStatus = corev1.ConditionTrue
+	}
+	for i := range newStatus.Conditions {
+		if newStatus.Conditions[i].Type == conditionType {
+			if newStatus.Conditions[i].Status != condStatus {
+				newStatus.Conditions[i].LastTransitionTime = &now
+			}
+			if ready {
+				newStatus.Conditions[i].LastProbeTime = &now
+			}
+			newStatus.Conditions[i].Status = condStatus
+			newStatus.Conditions[i].Message = message
+			return newStatus
+		}
+	}
+	var lastProbeTime *metav1.Time
+	if ready {
+		lastProbeTime = &now
+	}
+	newStatus.Conditions = append(
+		newStatus.Conditions,
+		EtcdBackupCondition{
+			Type:               conditionType,
+			Status:             condStatus,
+			LastProbeTime:      lastProbeTime,
+			LastTransitionTime: &now,
+			Message:            message,
+		},
+	)
+	return newStatus
+}
@@ -0,0 +1,133 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var etcdbackuplog = logf.Log.WithName("etcdbackup-resource")
+
+func (r *EtcdBackup) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-kubernetesimal-kkohtaka-org-v1alpha1-etcdbackup,mutating=false,failurePolicy=fail,sideEffects=None,groups=kubernetesimal.kkohtaka.org,resources=etcdbackups,verbs=create;update,versions=v1alpha1,name=vetcdbackup.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &EtcdBackup{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *EtcdBackup) ValidateCreate() (admission.Warnings, error) {
+	etcdbackuplog.Info("validate create", "name", r.Name)
+
+	return nil, r.validate(nil)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *EtcdBackup) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	etcdbackuplog.Info("validate update", "name", r.Name)
+
+	return nil, r.validate(old)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *EtcdBackup) ValidateDelete() (admission.Warnings, error) {
+	etcdbackuplog.Info("validate delete", "name", r.Name)
+
+	return nil, nil
+}
+
+func (r *EtcdBackup) validate(old runtime.Object) error {
+	var errs field.ErrorList
+	errs = append(errs, validateEtcdBackupSink(&r.Spec.Sink, field.NewPath("spec", "sink"))...)
+	if old != nil {
+		errs = append(errs, r.validateSpecUpdate(old)...)
+	}
+	if len(errs) > 0 {
+		err := apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "EtcdBackup"}, r.Name, errs)
+		etcdbackuplog.Error(err, "validation error", "name", r.Name)
+		return err
+	}
+	return nil
+}
+
+// validateSpecUpdate validates that the spec of an EtcdBackup is not changed since a snapshot is taken only once.
+func (r *EtcdBackup) validateSpecUpdate(old runtime.Object) field.ErrorList {
+	var errs field.ErrorList
+	oldBackup, ok := old.(*EtcdBackup)
+	if !ok {
+		return errs
+	}
+	if !equality.Semantic.DeepEqual(r.Spec, oldBackup.Spec) {
+		errs = append(errs,
+			field.Forbidden(
+				field.NewPath("spec"),
+				"the spec of an EtcdBackup can't be changed",
+			),
+		)
+	}
+	return errs
+}
+
+// validateEtcdBackupSink validates that exactly one of the sinks is specified.
+func validateEtcdBackupSink(sink *EtcdBackupSink, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	var sinks []string
+	if sink.PersistentVolumeClaim != nil {
+		sinks = append(sinks, "persistentVolumeClaim")
+	}
+	if sink.S3 != nil {
+		sinks = append(sinks, "s3")
+	}
+	switch len(sinks) {
+	case 0:
+		errs = append(errs,
+			field.Required(
+				path,
+				"one of persistentVolumeClaim or s3 must be specified",
+			),
+		)
+	case 1:
+	default:
+		errs = append(errs,
+			field.Forbidden(
+				path,
+				"only one of persistentVolumeClaim or s3 can be specified",
+			),
+		)
+	}
+	return errs
+}
@@ -0,0 +1,130 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestValidateEtcdBackupSchedule(t *testing.T) {
+	pvcSink := &EtcdBackupPersistentVolumeClaimSink{ClaimName: "backup"}
+	s3Sink := &EtcdBackupS3Sink{
+		Endpoint:             "https://s3.us-east-1.amazonaws.com",
+		Bucket:               "backup",
+		CredentialsSecretRef: corev1.LocalObjectReference{Name: "credentials"},
+	}
+
+	tests := []struct {
+		name     string
+		schedule string
+		sink     EtcdBackupSink
+		allowed  bool
+	}{
+		{
+			name:     "a PersistentVolumeClaim sink",
+			schedule: "0 */6 * * *",
+			sink:     EtcdBackupSink{PersistentVolumeClaim: pvcSink},
+			allowed:  true,
+		},
+		{
+			name:     "an S3 sink",
+			schedule: "@daily",
+			sink:     EtcdBackupSink{S3: s3Sink},
+			allowed:  true,
+		},
+		{
+			name:     "no sink",
+			schedule: "0 */6 * * *",
+		},
+		{
+			name:     "multiple sinks",
+			schedule: "0 */6 * * *",
+			sink:     EtcdBackupSink{PersistentVolumeClaim: pvcSink, S3: s3Sink},
+		},
+		{
+			name:     "an invalid schedule",
+			schedule: "0 */6 * *",
+			sink:     EtcdBackupSink{PersistentVolumeClaim: pvcSink},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &EtcdBackupSchedule{
+				Spec: EtcdBackupScheduleSpec{
+					Schedule: tt.schedule,
+					Template: EtcdBackupTemplateSpec{
+						Spec: EtcdBackupSpec{
+							Sink: tt.sink,
+						},
+					},
+				},
+			}
+			_, err := schedule.ValidateCreate()
+			assert.Equal(t, tt.allowed, err == nil, err)
+		})
+	}
+}
+
+func TestValidateEtcdBackupUpdate(t *testing.T) {
+	newBackup := func(etcdName, claimName string) *EtcdBackup {
+		return &EtcdBackup{
+			Spec: EtcdBackupSpec{
+				EtcdRef: corev1.LocalObjectReference{Name: etcdName},
+				Sink: EtcdBackupSink{
+					PersistentVolumeClaim: &EtcdBackupPersistentVolumeClaimSink{ClaimName: claimName},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		backup  *EtcdBackup
+		allowed bool
+	}{
+		{
+			name:    "the spec is not changed",
+			backup:  newBackup("etcd", "backup"),
+			allowed: true,
+		},
+		{
+			name:   "the Etcd is changed",
+			backup: newBackup("another-etcd", "backup"),
+		},
+		{
+			name:   "the sink is changed",
+			backup: newBackup("etcd", "another-backup"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.backup.ValidateUpdate(newBackup("etcd", "backup"))
+			assert.Equal(t, tt.allowed, err == nil, err)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type EtcdBackupTemplateSpec struct {
+	// +kubebuilder:pruning:PreserveUnknownFields
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of the desired behavior of the EtcdBackup.
+	Spec EtcdBackupSpec `json:"spec,omitempty"`
+}
+
+// EtcdBackupScheduleSpec defines the desired state of EtcdBackupSchedule
+type EtcdBackupScheduleSpec struct {
+	// Schedule is a schedule in the Cron format to take snapshots. (e.g. "0 */6 * * *")
+	//+kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// Suspend is whether subsequent backups should be suspended.
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Template is the object that describes the EtcdBackup that will be created on schedule.
+	Template EtcdBackupTemplateSpec `json:"template"`
+
+	// Retention is a policy to delete old EtcdBackups created by this schedule.
+	Retention EtcdBackupRetentionPolicy `json:"retention,omitempty"`
+}
+
+// EtcdBackupRetentionPolicy is a policy to delete old EtcdBackups. The latest completed EtcdBackup is always retained.
+type EtcdBackupRetentionPolicy struct {
+	// MaxBackups is the maximum number of completed EtcdBackups to retain.
+	//+kubebuilder:default=7
+	//+kubebuilder:validation:Minimum=1
+	MaxBackups *int32 `json:"maxBackups,omitempty"`
+
+	// MaxAge is the maximum age of completed EtcdBackups to retain.
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+
+	// FailedBackupsHistoryLimit is the maximum number of failed EtcdBackups to retain.
+	//+kubebuilder:default=1
+	//+kubebuilder:validation:Minimum=0
+	FailedBackupsHistoryLimit *int32 `json:"failedBackupsHistoryLimit,omitempty"`
+}
+
+// EtcdBackupScheduleStatus defines the observed state of EtcdBackupSchedule
+type EtcdBackupScheduleStatus struct {
+	// LastScheduleTime is the last time an EtcdBackup was scheduled.
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastSuccessfulTime is the last time an EtcdBackup was completed successfully.
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+
+	// LastBackupRef is a reference to an EtcdBackup that was created most recently.
+	LastBackupRef *corev1.LocalObjectReference `json:"lastBackupRef,omitempty"`
+
+	// ObservedGeneration reflects the generation of the most recently observed EtcdBackupSchedule.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+//+kubebuilder:printcolumn:name="Suspend",type=boolean,JSONPath=`.spec.suspend`
+//+kubebuilder:printcolumn:name="Last Schedule",type=date,JSONPath=`.status.lastScheduleTime`
+//+kubebuilder:printcolumn:name="Last Successful",type=date,priority=1,JSONPath=`.status.lastSuccessfulTime`
+
+// EtcdBackupSchedule is the Schema for the etcdbackupschedules API
+type EtcdBackupSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EtcdBackupScheduleSpec   `json:"spec,omitempty"`
+	Status EtcdBackupScheduleStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// EtcdBackupScheduleList contains a list of EtcdBackupSchedule
+type EtcdBackupScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EtcdBackupSchedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EtcdBackupSchedule{}, &EtcdBackupScheduleList{})
+}
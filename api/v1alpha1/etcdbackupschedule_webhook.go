@@ -0,0 +1,104 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/robfig/cron/v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var etcdbackupschedulelog = logf.Log.WithName("etcdbackupschedule-resource")
+
+func (r *EtcdBackupSchedule) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-kubernetesimal-kkohtaka-org-v1alpha1-etcdbackupschedule,mutating=false,failurePolicy=fail,sideEffects=None,groups=kubernetesimal.kkohtaka.org,resources=etcdbackupschedules,verbs=create;update,versions=v1alpha1,name=vetcdbackupschedule.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &EtcdBackupSchedule{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *EtcdBackupSchedule) ValidateCreate() (admission.Warnings, error) {
+	etcdbackupschedulelog.Info("validate create", "name", r.Name)
+
+	return nil, r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *EtcdBackupSchedule) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	etcdbackupschedulelog.Info("validate update", "name", r.Name)
+
+	return nil, r.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *EtcdBackupSchedule) ValidateDelete() (admission.Warnings, error) {
+	etcdbackupschedulelog.Info("validate delete", "name", r.Name)
+
+	return nil, nil
+}
+
+func (r *EtcdBackupSchedule) validate() error {
+	var errs field.ErrorList
+	errs = append(errs, r.validateSpecSchedule()...)
+	errs = append(errs, validateEtcdBackupSink(
+		&r.Spec.Template.Spec.Sink,
+		field.NewPath("spec", "template", "spec", "sink"),
+	)...)
+	if len(errs) > 0 {
+		err := apierrors.NewInvalid(
+			schema.GroupKind{Group: GroupVersion.Group, Kind: "EtcdBackupSchedule"},
+			r.Name,
+			errs,
+		)
+		etcdbackupschedulelog.Error(err, "validation error", "name", r.Name)
+		return err
+	}
+	return nil
+}
+
+func (r *EtcdBackupSchedule) validateSpecSchedule() field.ErrorList {
+	var errs field.ErrorList
+	if _, err := cron.ParseStandard(r.Spec.Schedule); err != nil {
+		errs = append(errs,
+			field.Invalid(
+				field.NewPath("spec", "schedule"),
+				r.Spec.Schedule,
+				"the schedule must be in the Cron format: "+err.Error(),
+			),
+		)
+	}
+	return errs
+}
@@ -28,7 +28,9 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 type EtcdNodeTemplateSpec struct {
@@ -62,6 +64,12 @@ type EtcdNodeSpec struct {
 	// ClientPrivateKeyRef is a reference to a Secret key that composes a Client private key.
 	ClientPrivateKeyRef corev1.SecretKeySelector `json:"clientPrivateKeyRef,omitempty"`
 
+	// PeerCertificateRef is a reference to a Secret key that composes a certificate for peer communication. Peer
+	// certificates of the node inherit its common name.
+	PeerCertificateRef corev1.SecretKeySelector `json:"peerCertificateRef,omitempty"`
+	// PeerPrivateKeyRef is a reference to a Secret key that composes a private key for peer communication.
+	PeerPrivateKeyRef corev1.SecretKeySelector `json:"peerPrivateKeyRef,omitempty"`
+
 	// SSHPrivateKeyRef is a reference to a Secret key that composes an SSH private key.
 	SSHPrivateKeyRef corev1.SecretKeySelector `json:"sshPrivateKeyRef"`
 	// SSHPublicKeyRef is a reference to a Secret key that composes an SSH public key.
@@ -72,8 +80,254 @@ type EtcdNodeSpec struct {
 
 	// AsFirstNode is whether the node is the first node of a cluster.
 	AsFirstNode bool `json:"asFirstNode"`
+
+	// SnapshotSource is a source of a snapshot that the node is restored from. It's only respected for the first
+	// node of a cluster.
+	SnapshotSource *EtcdSnapshotSource `json:"snapshotSource,omitempty"`
+
+	// DataVolumeClaimTemplate is a template of a PersistentVolumeClaim that is created for each node and mounted as a
+	// data directory of etcd. If it's not specified, etcd stores its data in an ephemeral volume of a virtual machine.
+	// The volume outlives a VirtualMachineInstance, so a member provisioned again on a recreated VirtualMachineInstance
+	// restarts etcd with its data in the volume.
+	DataVolumeClaimTemplate *corev1.PersistentVolumeClaimTemplate `json:"dataVolumeClaimTemplate,omitempty"`
+
+	// RunStrategy is a strategy of running a virtual machine of the node. If it's specified, the node is composed of a
+	// VirtualMachine which keeps a VirtualMachineInstance running according to the strategy. Otherwise, the node is
+	// composed of a bare VirtualMachineInstance.
+	RunStrategy *EtcdNodeRunStrategy `json:"runStrategy,omitempty"`
+
+	// Resources is a specification of compute resources of a virtual machine of the node.
+	Resources *EtcdNodeResources `json:"resources,omitempty"`
+
+	// InstanceType is a reference to a KubeVirt instance type and preference that a virtual machine of the node is
+	// created with. It can be specified only if RunStrategy is specified, and can't be specified with Resources.
+	InstanceType *EtcdNodeInstanceType `json:"instanceType,omitempty"`
+
+	// NodeSelector is a selector which must match labels of a node that a virtual machine of the node is scheduled
+	// on.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations are tolerations of a virtual machine of the node.
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity is scheduling constraints of a virtual machine of the node. If it's not specified, a virtual machine
+	// of the node prefers to be scheduled on a host and a zone different from ones of the other nodes of the same
+	// cluster.
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// TopologySpreadConstraints describe how virtual machines of nodes are spread across topology domains.
+	// A virtual machine of a node is labeled with "etcdnode.kubernetesimal.kkohtaka.org/cluster" whose value is a
+	// name of the Service of its etcd cluster so that constraints can select virtual machines of the same cluster.
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// MemberCertificateSigner is a signer of server and peer certificates of the node.
+	//+kubebuilder:default=Etcdadm
+	MemberCertificateSigner EtcdCertificateSigner `json:"memberCertificateSigner,omitempty"`
+
+	// PeerMTLS is a mode of mutual TLS authentication between etcd members.
+	//+kubebuilder:default=Permissive
+	PeerMTLS EtcdPeerMTLSMode `json:"peerMTLS,omitempty"`
+
+	// BootstrapDriver is a driver which bootstraps an etcd member on a virtual machine of the node.
+	//+kubebuilder:default=Etcdadm
+	BootstrapDriver EtcdBootstrapDriver `json:"bootstrapDriver,omitempty"`
+
+	// SSH is a specification of SSH connections to a virtual machine of the node, which are used to provision an etcd
+	// member.
+	SSH *EtcdNodeSSHSpec `json:"ssh,omitempty"`
+
+	// Artifacts is a specification of where release artifacts installed on a virtual machine of the node are
+	// downloaded from.
+	Artifacts *EtcdArtifactsSpec `json:"artifacts,omitempty"`
+
+	// Config is a configuration of an etcd server of the node.
+	Config *EtcdConfig `json:"config,omitempty"`
+
+	// ExtraArgs is a map of names of etcd server flags without leading dashes to their values.
+	ExtraArgs map[string]string `json:"extraArgs,omitempty"`
+
+	// ExtraCloudConfig is a source of a cloud-config which is merged into a cloud-config generated for a virtual
+	// machine of the node.
+	ExtraCloudConfig *EtcdCloudConfigSource `json:"extraCloudConfig,omitempty"`
+
+	// Bootstrap is a specification of how a virtual machine of the node is bootstrapped.
+	Bootstrap *EtcdNodeBootstrapSpec `json:"bootstrap,omitempty"`
+
+	// Join is a specification of how a member of the node joins an existing etcd cluster.
+	Join *EtcdJoinSpec `json:"join,omitempty"`
 }
 
+// EtcdNodeBootstrapSpec is a specification of how a virtual machine of an etcd node is bootstrapped.
+type EtcdNodeBootstrapSpec struct {
+	// Format is a format of bootstrap data which is passed to a virtual machine of the node.
+	//+kubebuilder:default=CloudInit
+	Format EtcdBootstrapFormat `json:"format,omitempty"`
+}
+
+// EtcdJoinSpec is a specification of how etcd members join an existing etcd cluster.
+type EtcdJoinSpec struct {
+	// Mode is a mode of etcd members joining an existing etcd cluster. Learner can be specified only if the bootstrap
+	// driver is Systemd.
+	//+kubebuilder:default=Voter
+	Mode EtcdJoinMode `json:"mode,omitempty"`
+
+	// PromotionThreshold is the maximum number of raft entries which a learner can lag behind the leader to be promoted
+	// to a voting member. Defaults to 1000.
+	//+kubebuilder:validation:Minimum=0
+	PromotionThreshold *int64 `json:"promotionThreshold,omitempty"`
+}
+
+// EtcdNodeSSHSpec is a specification of SSH connections to a virtual machine.
+type EtcdNodeSSHSpec struct {
+	// User is a user who logs in to a virtual machine. Defaults to fedora, or core if the virtual machine is
+	// bootstrapped with Ignition.
+	User string `json:"user,omitempty"`
+
+	// Port is a port where an SSH server of a virtual machine listens. Defaults to 22.
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+
+	// JumpHosts are SSH servers which a connection to a virtual machine goes through in order, like ProxyJump of
+	// OpenSSH. The last one must be able to reach the Service of the node.
+	JumpHosts []EtcdNodeSSHJumpHost `json:"jumpHosts,omitempty"`
+}
+
+// EtcdNodeSSHJumpHost is an SSH server which a connection to a virtual machine goes through.
+type EtcdNodeSSHJumpHost struct {
+	// Address is a host name or an IP address of the SSH server.
+	Address string `json:"address"`
+
+	// Port is a port where the SSH server listens. Defaults to 22.
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+
+	// User is a user who logs in to the SSH server.
+	User string `json:"user"`
+
+	// PrivateKeyRef is a reference to a Secret key that composes an SSH private key to log in to the SSH server.
+	// Defaults to the SSH private key of the node.
+	PrivateKeyRef *corev1.SecretKeySelector `json:"privateKeyRef,omitempty"`
+
+	// HostKeyRef is a reference to a Secret key that composes a public host key of the SSH server in the
+	// authorized_keys format.
+	HostKeyRef corev1.SecretKeySelector `json:"hostKeyRef"`
+}
+
+// EtcdNodeResources is a specification of compute resources of a virtual machine.
+type EtcdNodeResources struct {
+	// Memory is the amount of memory requested by a virtual machine.
+	Memory *resource.Quantity `json:"memory,omitempty"`
+
+	// Cores is the number of CPU cores of a virtual machine.
+	//+kubebuilder:validation:Minimum=1
+	Cores *uint32 `json:"cores,omitempty"`
+
+	// CPUModel is a CPU model of a virtual machine (e.g. host-passthrough).
+	CPUModel string `json:"cpuModel,omitempty"`
+
+	// DedicatedCPUPlacement is whether dedicated physical CPUs are allocated to a virtual machine.
+	// If it's true, limits of CPU and memory are set to the same amount as requests.
+	DedicatedCPUPlacement bool `json:"dedicatedCPUPlacement,omitempty"`
+
+	// HugepagesPageSize is a page size of hugepages backing memory of a virtual machine (e.g. 2Mi or 1Gi).
+	HugepagesPageSize string `json:"hugepagesPageSize,omitempty"`
+}
+
+// EtcdNodeInstanceType is a reference to a KubeVirt instance type and preference.
+type EtcdNodeInstanceType struct {
+	// Name is the name of a VirtualMachineInstancetype or a VirtualMachineClusterInstancetype.
+	Name string `json:"name"`
+
+	// Kind is the kind of the instance type.
+	//+kubebuilder:validation:Enum=VirtualMachineInstancetype;VirtualMachineClusterInstancetype
+	//+kubebuilder:default=VirtualMachineClusterInstancetype
+	Kind string `json:"kind,omitempty"`
+
+	// PreferenceName is the name of a VirtualMachinePreference or a VirtualMachineClusterPreference.
+	PreferenceName string `json:"preferenceName,omitempty"`
+
+	// PreferenceKind is the kind of the preference.
+	//+kubebuilder:validation:Enum=VirtualMachinePreference;VirtualMachineClusterPreference
+	//+kubebuilder:default=VirtualMachineClusterPreference
+	PreferenceKind string `json:"preferenceKind,omitempty"`
+}
+
+// EtcdCertificateSigner is a signer of server and peer certificates of etcd members.
+// +kubebuilder:validation:Enum=Etcdadm;Controller
+type EtcdCertificateSigner string
+
+const (
+	// EtcdCertificateSignerEtcdadm means etcdadm signs certificates on a virtual machine with the CA private key which
+	// is placed on the virtual machine.
+	EtcdCertificateSignerEtcdadm EtcdCertificateSigner = "Etcdadm"
+	// EtcdCertificateSignerController means the controller signs certificates and places only them on a virtual
+	// machine, so that the CA private key never leaves the controller.
+	EtcdCertificateSignerController EtcdCertificateSigner = "Controller"
+)
+
+// EtcdPeerMTLSMode is a mode of mutual TLS authentication between etcd members.
+// +kubebuilder:validation:Enum=Permissive;Required
+type EtcdPeerMTLSMode string
+
+const (
+	// EtcdPeerMTLSModePermissive means etcd members accept any peer which presents a certificate signed by the CA.
+	EtcdPeerMTLSModePermissive EtcdPeerMTLSMode = "Permissive"
+	// EtcdPeerMTLSModeRequired means etcd members accept only peers which present a peer certificate of the etcd
+	// cluster, so that other certificates signed by the CA, e.g. client certificates, can't be used to join the etcd
+	// cluster. It requires the controller to sign certificates of etcd members.
+	EtcdPeerMTLSModeRequired EtcdPeerMTLSMode = "Required"
+)
+
+// EtcdBootstrapDriver is a driver which bootstraps etcd members on virtual machines.
+// +kubebuilder:validation:Enum=Etcdadm;Systemd
+type EtcdBootstrapDriver string
+
+const (
+	// EtcdBootstrapDriverEtcdadm means etcdadm bootstraps etcd members and manages their membership on virtual machines.
+	EtcdBootstrapDriverEtcdadm EtcdBootstrapDriver = "Etcdadm"
+	// EtcdBootstrapDriverSystemd means the controller renders a systemd unit and a configuration file of etcd, and
+	// manages membership of etcd members with the etcd API. It requires the controller to sign certificates of etcd
+	// members.
+	EtcdBootstrapDriverSystemd EtcdBootstrapDriver = "Systemd"
+)
+
+// EtcdBootstrapFormat is a format of bootstrap data which is passed to virtual machines.
+// +kubebuilder:validation:Enum=CloudInit;Ignition
+type EtcdBootstrapFormat string
+
+const (
+	// EtcdBootstrapFormatCloudInit means a cloud-config is passed to virtual machines through a NoCloud data source.
+	EtcdBootstrapFormatCloudInit EtcdBootstrapFormat = "CloudInit"
+	// EtcdBootstrapFormatIgnition means an Ignition config is passed to virtual machines through a config drive, which
+	// is suitable for immutable operating systems like Fedora CoreOS and Flatcar Container Linux.
+	EtcdBootstrapFormatIgnition EtcdBootstrapFormat = "Ignition"
+)
+
+// EtcdJoinMode is a mode of etcd members joining an existing etcd cluster.
+// +kubebuilder:validation:Enum=Voter;Learner
+type EtcdJoinMode string
+
+const (
+	// EtcdJoinModeVoter means a new etcd member is added to an etcd cluster as a voting member straight away.
+	EtcdJoinModeVoter EtcdJoinMode = "Voter"
+	// EtcdJoinModeLearner means a new etcd member is added to an etcd cluster as a learner, and promoted to a voting
+	// member once it has caught up with the leader, so that the quorum isn't widened by a member lagging behind.
+	EtcdJoinModeLearner EtcdJoinMode = "Learner"
+)
+
+// EtcdNodeRunStrategy is a strategy of running a virtual machine of an etcd node.
+// +kubebuilder:validation:Enum=Always;RerunOnFailure
+type EtcdNodeRunStrategy string
+
+const (
+	// EtcdNodeRunStrategyAlways means a VirtualMachineInstance is always restarted whenever it stops.
+	EtcdNodeRunStrategyAlways EtcdNodeRunStrategy = "Always"
+	// EtcdNodeRunStrategyRerunOnFailure means a VirtualMachineInstance is restarted only if it fails.
+	EtcdNodeRunStrategyRerunOnFailure EtcdNodeRunStrategy = "RerunOnFailure"
+)
+
 // EtcdNodeStatus defines the observed state of EtcdNode
 type EtcdNodeStatus struct {
 	// Phase indicates phase of the etcd node.
@@ -82,17 +336,112 @@ type EtcdNodeStatus struct {
 
 	// UserDataRef is a reference to a Secret that contains a userdata used to start a virtual machine instance.
 	UserDataRef *corev1.LocalObjectReference `json:"userDataRef,omitempty"`
+	// VirtualMachineRef is a reference to a VirtualMachine that composes an etcd node. It's set only if the node is
+	// composed of a VirtualMachine.
+	VirtualMachineRef *corev1.LocalObjectReference `json:"virtualMachineRef,omitempty"`
 	// VirtualMachineInstanceRef is a reference to a VirtualMachineInstance that composes an etcd node.
 	VirtualMachineInstanceRef *corev1.LocalObjectReference `json:"virtualMachineInstanceRef,omitempty"`
+	// VirtualMachineInstanceUID is the UID of the VirtualMachineInstance which an etcd member is provisioned on. The
+	// member is provisioned again when the VirtualMachineInstance is recreated, e.g., by a VirtualMachine, since a root
+	// disk of a virtual machine is ephemeral.
+	VirtualMachineInstanceUID types.UID `json:"virtualMachineInstanceUID,omitempty"`
 	// PeerServiceRef is a reference to a Service of an etcd node.
 	PeerServiceRef *corev1.LocalObjectReference `json:"peerServiceRef,omitempty"`
+	// DataVolumeClaimRef is a reference to a PersistentVolumeClaim that stores data of an etcd member.
+	DataVolumeClaimRef *corev1.LocalObjectReference `json:"dataVolumeClaimRef,omitempty"`
+	// SSHHostKeyRef is a reference to a Secret key that composes a public SSH host key of a virtual machine in the
+	// authorized_keys format. The controller connects to the virtual machine only if it presents the host key.
+	SSHHostKeyRef *corev1.SecretKeySelector `json:"sshHostKeyRef,omitempty"`
+	// MemberID is the ID of the etcd member in hexadecimal. It's recorded once the member serves in an etcd cluster, so
+	// that the member can be removed from the cluster even if its virtual machine is gone.
+	MemberID string `json:"memberID,omitempty"`
+	// Learner is whether the etcd member is a learner which is waiting to be promoted to a voting member.
+	Learner bool `json:"learner,omitempty"`
+	// ProbeFailures is the number of consecutive failed probes of the etcd member.
+	ProbeFailures int32 `json:"probeFailures,omitempty"`
+	// ObservedForceNewCluster is the value of the force-new-cluster annotation that was observed by the latest new etcd
+	// cluster which the etcd member formed.
+	ObservedForceNewCluster string `json:"observedForceNewCluster,omitempty"`
+
+	// Restore is the observed state of restoring the node from a snapshot.
+	Restore *EtcdRestoreStatus `json:"restore,omitempty"`
+
+	// Certificates is the observed state of certificates of an etcd member.
+	Certificates *EtcdNodeCertificatesStatus `json:"certificates,omitempty"`
+
+	// ProvisioningSteps are the observed states of steps of provisioning an etcd member. Provisioning is resumed from
+	// a step which didn't succeed.
+	ProvisioningSteps []EtcdNodeProvisioningStep `json:"provisioningSteps,omitempty"`
 
 	// Conditions is a list of statuses respected to certain conditions.
 	Conditions []EtcdNodeCondition `json:"conditions,omitempty"`
 }
 
+// EtcdNodeProvisioningStep is the observed state of a step of provisioning an etcd member.
+type EtcdNodeProvisioningStep struct {
+	// Name is the name of the step.
+	Name string `json:"name"`
+
+	// Phase indicates phase of the step.
+	Phase EtcdNodeProvisioningStepPhase `json:"phase"`
+
+	// ExitCode is an exit code of a command of the step.
+	ExitCode *int32 `json:"exitCode,omitempty"`
+
+	// Output is the tail of an output of a command of the step.
+	Output string `json:"output,omitempty"`
+
+	// Message is a human-readable message indicating why the step failed.
+	Message string `json:"message,omitempty"`
+
+	// LastRunTime is the time when the step was run last.
+	LastRunTime *metav1.Time `json:"lastRunTime,omitempty"`
+}
+
+// EtcdNodeProvisioningStepPhase is a label for the phase of a step of provisioning at the current time.
+// +kubebuilder:validation:Enum=Succeeded;Failed
+type EtcdNodeProvisioningStepPhase string
+
+const (
+	// EtcdNodeProvisioningStepPhaseSucceeded means the step succeeded.
+	EtcdNodeProvisioningStepPhaseSucceeded EtcdNodeProvisioningStepPhase = "Succeeded"
+	// EtcdNodeProvisioningStepPhaseFailed means the step failed, and it's retried.
+	EtcdNodeProvisioningStepPhaseFailed EtcdNodeProvisioningStepPhase = "Failed"
+)
+
+const (
+	// EtcdNodeCertificateRotationAnnotation is an annotation to request rotating certificates of an etcd member. Its
+	// value identifies a rotation, and a rotation is done whenever it's changed.
+	EtcdNodeCertificateRotationAnnotation = "etcdnode.kubernetesimal.kkohtaka.org/certificate-rotation"
+
+	// EtcdNodeRemediationAnnotation is an annotation which marks an etcd node failed permanently. Its value is a reason
+	// of the remediation. An etcd member of a marked node is removed from the etcd cluster with the etcd API instead of
+	// through its virtual machine.
+	EtcdNodeRemediationAnnotation = "etcdnode.kubernetesimal.kkohtaka.org/remediation"
+
+	// EtcdNodeForceNewClusterAnnotation is an annotation to request restarting an etcd member as the only member of a
+	// new etcd cluster with --force-new-cluster. Its value identifies a request, and a request is done whenever it's
+	// changed.
+	EtcdNodeForceNewClusterAnnotation = "etcdnode.kubernetesimal.kkohtaka.org/force-new-cluster"
+
+	// EtcdNodeForceNewClusterBackupAnnotation is an annotation to specify a name of a completed EtcdBackup whose
+	// snapshot an etcd member is restored from when it forms a new etcd cluster.
+	EtcdNodeForceNewClusterBackupAnnotation = "etcdnode.kubernetesimal.kkohtaka.org/force-new-cluster-backup"
+)
+
+// EtcdNodeCertificatesStatus defines the observed state of certificates of an etcd member.
+type EtcdNodeCertificatesStatus struct {
+	// ServerNotAfter is the time when the server certificate of an etcd member expires.
+	ServerNotAfter *metav1.Time `json:"serverNotAfter,omitempty"`
+	// PeerNotAfter is the time when the peer certificate of an etcd member expires.
+	PeerNotAfter *metav1.Time `json:"peerNotAfter,omitempty"`
+
+	// ObservedRotation is the value of the certificate-rotation annotation that was observed by the latest rotation.
+	ObservedRotation string `json:"observedRotation,omitempty"`
+}
+
 // EtcdNodePhase is a label for the phase of the etcd cluster at the current time.
-// +kubebuilder:validation:Enum=Creating;Provisioned;Running;Deleting;Error
+// +kubebuilder:validation:Enum=Creating;Provisioned;Learner;Running;Deleting;Error
 type EtcdNodePhase string
 
 const (
@@ -100,6 +449,8 @@ const (
 	EtcdNodePhaseCreating EtcdNodePhase = "Creating"
 	// EtcdNodePhaseProvisioned means the etcd node was provisioned and waiting to become running.
 	EtcdNodePhaseProvisioned EtcdNodePhase = "Provisioned"
+	// EtcdNodePhaseLearner means the etcd node is running as a learner and waiting to be promoted to a voting member.
+	EtcdNodePhaseLearner EtcdNodePhase = "Learner"
 	// EtcdNodePhaseRunning means the etcd node is running.
 	EtcdNodePhaseRunning EtcdNodePhase = "Running"
 	// EtcdNodePhaseDeleting means the etcd node is being deleted.
@@ -173,6 +524,8 @@ func (status *EtcdNodeStatus) LastReadyProbeTime() *metav1.Time {
 	return nil
 }
 
+// IsProvisioned returns true if an etcd member has ever been provisioned successfully, i.e., the Provisioned condition
+// has a LastProbeTime. It stays true even if a later provisioning fails.
 func (status *EtcdNodeStatus) IsProvisioned() bool {
 	for i := range status.Conditions {
 		if status.Conditions[i].Type == EtcdNodeConditionTypeProvisioned {
@@ -182,6 +535,17 @@ func (status *EtcdNodeStatus) IsProvisioned() bool {
 	return false
 }
 
+// IsProvisioningSucceeded returns true if the last provisioning of an etcd member succeeded, i.e., the Provisioned
+// condition has the status True. Unlike IsProvisioned, it becomes false when a later provisioning fails.
+func (status *EtcdNodeStatus) IsProvisioningSucceeded() bool {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == EtcdNodeConditionTypeProvisioned {
+			return status.Conditions[i].Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
 func (status *EtcdNodeStatus) IsReady() bool {
 	for i := range status.Conditions {
 		if status.Conditions[i].Type == EtcdNodeConditionTypeReady {
@@ -291,3 +655,15 @@ func (status *EtcdNodeStatus) WithStatusCondition(
 	)
 	return newStatus
 }
+
+func (status *EtcdNodeStatus) WithProvisioningStep(step EtcdNodeProvisioningStep) *EtcdNodeStatus {
+	newStatus := status.DeepCopy()
+	for i := range newStatus.ProvisioningSteps {
+		if newStatus.ProvisioningSteps[i].Name == step.Name {
+			newStatus.ProvisioningSteps[i] = step
+			return newStatus
+		}
+	}
+	newStatus.ProvisioningSteps = append(newStatus.ProvisioningSteps, step)
+	return newStatus
+}
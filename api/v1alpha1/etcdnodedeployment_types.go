@@ -50,6 +50,10 @@ type EtcdNodeDeploymentSpec struct {
 	// This is set to the max value of int32 (i.e. 2147483647) by default, which means
 	// "retaining all old EtcdNodeSets".
 	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// Indicates that the EtcdNodeDeployment is paused. EtcdNodeSets of a paused EtcdNodeDeployment are not scaled
+	// until it's resumed.
+	Paused bool `json:"paused,omitempty"`
 }
 
 // RollingUpdateEtcdNodeDeployment is the spec to control the desired behavior of rolling update.
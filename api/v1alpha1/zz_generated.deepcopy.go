@@ -78,6 +78,482 @@ func (in *Etcd) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdArtifactsSpec) DeepCopyInto(out *EtcdArtifactsSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdArtifactsSpec.
+func (in *EtcdArtifactsSpec) DeepCopy() *EtcdArtifactsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdArtifactsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdBackup) DeepCopyInto(out *EtcdBackup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdBackup.
+func (in *EtcdBackup) DeepCopy() *EtcdBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EtcdBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdBackupCondition) DeepCopyInto(out *EtcdBackupCondition) {
+	*out = *in
+	if in.LastProbeTime != nil {
+		in, out := &in.LastProbeTime, &out.LastProbeTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdBackupCondition.
+func (in *EtcdBackupCondition) DeepCopy() *EtcdBackupCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdBackupCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdBackupList) DeepCopyInto(out *EtcdBackupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EtcdBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdBackupList.
+func (in *EtcdBackupList) DeepCopy() *EtcdBackupList {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdBackupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EtcdBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdBackupPersistentVolumeClaimSink) DeepCopyInto(out *EtcdBackupPersistentVolumeClaimSink) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdBackupPersistentVolumeClaimSink.
+func (in *EtcdBackupPersistentVolumeClaimSink) DeepCopy() *EtcdBackupPersistentVolumeClaimSink {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdBackupPersistentVolumeClaimSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdBackupRetentionPolicy) DeepCopyInto(out *EtcdBackupRetentionPolicy) {
+	*out = *in
+	if in.MaxBackups != nil {
+		in, out := &in.MaxBackups, &out.MaxBackups
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.FailedBackupsHistoryLimit != nil {
+		in, out := &in.FailedBackupsHistoryLimit, &out.FailedBackupsHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdBackupRetentionPolicy.
+func (in *EtcdBackupRetentionPolicy) DeepCopy() *EtcdBackupRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdBackupRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdBackupS3Sink) DeepCopyInto(out *EtcdBackupS3Sink) {
+	*out = *in
+	out.CredentialsSecretRef = in.CredentialsSecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdBackupS3Sink.
+func (in *EtcdBackupS3Sink) DeepCopy() *EtcdBackupS3Sink {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdBackupS3Sink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdBackupSchedule) DeepCopyInto(out *EtcdBackupSchedule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdBackupSchedule.
+func (in *EtcdBackupSchedule) DeepCopy() *EtcdBackupSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdBackupSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EtcdBackupSchedule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdBackupScheduleList) DeepCopyInto(out *EtcdBackupScheduleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EtcdBackupSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdBackupScheduleList.
+func (in *EtcdBackupScheduleList) DeepCopy() *EtcdBackupScheduleList {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdBackupScheduleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EtcdBackupScheduleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdBackupScheduleSpec) DeepCopyInto(out *EtcdBackupScheduleSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+	in.Retention.DeepCopyInto(&out.Retention)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdBackupScheduleSpec.
+func (in *EtcdBackupScheduleSpec) DeepCopy() *EtcdBackupScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdBackupScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdBackupScheduleStatus) DeepCopyInto(out *EtcdBackupScheduleStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulTime != nil {
+		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastBackupRef != nil {
+		in, out := &in.LastBackupRef, &out.LastBackupRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdBackupScheduleStatus.
+func (in *EtcdBackupScheduleStatus) DeepCopy() *EtcdBackupScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdBackupScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdBackupSink) DeepCopyInto(out *EtcdBackupSink) {
+	*out = *in
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		*out = new(EtcdBackupPersistentVolumeClaimSink)
+		**out = **in
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(EtcdBackupS3Sink)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdBackupSink.
+func (in *EtcdBackupSink) DeepCopy() *EtcdBackupSink {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdBackupSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdBackupSpec) DeepCopyInto(out *EtcdBackupSpec) {
+	*out = *in
+	out.EtcdRef = in.EtcdRef
+	in.Sink.DeepCopyInto(&out.Sink)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdBackupSpec.
+func (in *EtcdBackupSpec) DeepCopy() *EtcdBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdBackupStatus) DeepCopyInto(out *EtcdBackupStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]EtcdBackupCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdBackupStatus.
+func (in *EtcdBackupStatus) DeepCopy() *EtcdBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdBackupTemplateSpec) DeepCopyInto(out *EtcdBackupTemplateSpec) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdBackupTemplateSpec.
+func (in *EtcdBackupTemplateSpec) DeepCopy() *EtcdBackupTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdBackupTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdBootstrapSpec) DeepCopyInto(out *EtcdBootstrapSpec) {
+	*out = *in
+	if in.FromSnapshot != nil {
+		in, out := &in.FromSnapshot, &out.FromSnapshot
+		*out = new(EtcdSnapshotSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdBootstrapSpec.
+func (in *EtcdBootstrapSpec) DeepCopy() *EtcdBootstrapSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdBootstrapSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdCASpec) DeepCopyInto(out *EtcdCASpec) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(EtcdIssuerReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdCASpec.
+func (in *EtcdCASpec) DeepCopy() *EtcdCASpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdCASpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdCertificateRotationSpec) DeepCopyInto(out *EtcdCertificateRotationSpec) {
+	*out = *in
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdCertificateRotationSpec.
+func (in *EtcdCertificateRotationSpec) DeepCopy() *EtcdCertificateRotationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdCertificateRotationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdCertificatesStatus) DeepCopyInto(out *EtcdCertificatesStatus) {
+	*out = *in
+	if in.CANotAfter != nil {
+		in, out := &in.CANotAfter, &out.CANotAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.ClientNotAfter != nil {
+		in, out := &in.ClientNotAfter, &out.ClientNotAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.PeerNotAfter != nil {
+		in, out := &in.PeerNotAfter, &out.PeerNotAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.ServerNotAfter != nil {
+		in, out := &in.ServerNotAfter, &out.ServerNotAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRotationTime != nil {
+		in, out := &in.LastRotationTime, &out.LastRotationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdCertificatesStatus.
+func (in *EtcdCertificatesStatus) DeepCopy() *EtcdCertificatesStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdCertificatesStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdCloudConfigSource) DeepCopyInto(out *EtcdCloudConfigSource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdCloudConfigSource.
+func (in *EtcdCloudConfigSource) DeepCopy() *EtcdCloudConfigSource {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdCloudConfigSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EtcdCondition) DeepCopyInto(out *EtcdCondition) {
 	*out = *in
@@ -101,6 +577,76 @@ func (in *EtcdCondition) DeepCopy() *EtcdCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdConfig) DeepCopyInto(out *EtcdConfig) {
+	*out = *in
+	if in.QuotaBackendBytes != nil {
+		in, out := &in.QuotaBackendBytes, &out.QuotaBackendBytes
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.SnapshotCount != nil {
+		in, out := &in.SnapshotCount, &out.SnapshotCount
+		*out = new(int64)
+		**out = **in
+	}
+	if in.HeartbeatInterval != nil {
+		in, out := &in.HeartbeatInterval, &out.HeartbeatInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ElectionTimeout != nil {
+		in, out := &in.ElectionTimeout, &out.ElectionTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdConfig.
+func (in *EtcdConfig) DeepCopy() *EtcdConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdIssuerReference) DeepCopyInto(out *EtcdIssuerReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdIssuerReference.
+func (in *EtcdIssuerReference) DeepCopy() *EtcdIssuerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdIssuerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdJoinSpec) DeepCopyInto(out *EtcdJoinSpec) {
+	*out = *in
+	if in.PromotionThreshold != nil {
+		in, out := &in.PromotionThreshold, &out.PromotionThreshold
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdJoinSpec.
+func (in *EtcdJoinSpec) DeepCopy() *EtcdJoinSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdJoinSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EtcdList) DeepCopyInto(out *EtcdList) {
 	*out = *in
@@ -134,32 +680,70 @@ func (in *EtcdList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EtcdNode) DeepCopyInto(out *EtcdNode) {
+func (in *EtcdNode) DeepCopyInto(out *EtcdNode) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdNode.
+func (in *EtcdNode) DeepCopy() *EtcdNode {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EtcdNode) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdNodeBootstrapSpec) DeepCopyInto(out *EtcdNodeBootstrapSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdNodeBootstrapSpec.
+func (in *EtcdNodeBootstrapSpec) DeepCopy() *EtcdNodeBootstrapSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdNodeBootstrapSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdNodeCertificatesStatus) DeepCopyInto(out *EtcdNodeCertificatesStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.ServerNotAfter != nil {
+		in, out := &in.ServerNotAfter, &out.ServerNotAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.PeerNotAfter != nil {
+		in, out := &in.PeerNotAfter, &out.PeerNotAfter
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdNode.
-func (in *EtcdNode) DeepCopy() *EtcdNode {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdNodeCertificatesStatus.
+func (in *EtcdNodeCertificatesStatus) DeepCopy() *EtcdNodeCertificatesStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(EtcdNode)
+	out := new(EtcdNodeCertificatesStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *EtcdNode) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EtcdNodeCondition) DeepCopyInto(out *EtcdNodeCondition) {
 	*out = *in
@@ -299,6 +883,21 @@ func (in *EtcdNodeDeploymentStatus) DeepCopy() *EtcdNodeDeploymentStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdNodeInstanceType) DeepCopyInto(out *EtcdNodeInstanceType) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdNodeInstanceType.
+func (in *EtcdNodeInstanceType) DeepCopy() *EtcdNodeInstanceType {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdNodeInstanceType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EtcdNodeList) DeepCopyInto(out *EtcdNodeList) {
 	*out = *in
@@ -331,6 +930,108 @@ func (in *EtcdNodeList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdNodeProvisioningStep) DeepCopyInto(out *EtcdNodeProvisioningStep) {
+	*out = *in
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LastRunTime != nil {
+		in, out := &in.LastRunTime, &out.LastRunTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdNodeProvisioningStep.
+func (in *EtcdNodeProvisioningStep) DeepCopy() *EtcdNodeProvisioningStep {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdNodeProvisioningStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdNodeResources) DeepCopyInto(out *EtcdNodeResources) {
+	*out = *in
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Cores != nil {
+		in, out := &in.Cores, &out.Cores
+		*out = new(uint32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdNodeResources.
+func (in *EtcdNodeResources) DeepCopy() *EtcdNodeResources {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdNodeResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdNodeSSHJumpHost) DeepCopyInto(out *EtcdNodeSSHJumpHost) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PrivateKeyRef != nil {
+		in, out := &in.PrivateKeyRef, &out.PrivateKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.HostKeyRef.DeepCopyInto(&out.HostKeyRef)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdNodeSSHJumpHost.
+func (in *EtcdNodeSSHJumpHost) DeepCopy() *EtcdNodeSSHJumpHost {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdNodeSSHJumpHost)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdNodeSSHSpec) DeepCopyInto(out *EtcdNodeSSHSpec) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.JumpHosts != nil {
+		in, out := &in.JumpHosts, &out.JumpHosts
+		*out = make([]EtcdNodeSSHJumpHost, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdNodeSSHSpec.
+func (in *EtcdNodeSSHSpec) DeepCopy() *EtcdNodeSSHSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdNodeSSHSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EtcdNodeSet) DeepCopyInto(out *EtcdNodeSet) {
 	*out = *in
@@ -444,9 +1145,99 @@ func (in *EtcdNodeSpec) DeepCopyInto(out *EtcdNodeSpec) {
 	in.CAPrivateKeyRef.DeepCopyInto(&out.CAPrivateKeyRef)
 	in.ClientCertificateRef.DeepCopyInto(&out.ClientCertificateRef)
 	in.ClientPrivateKeyRef.DeepCopyInto(&out.ClientPrivateKeyRef)
+	in.PeerCertificateRef.DeepCopyInto(&out.PeerCertificateRef)
+	in.PeerPrivateKeyRef.DeepCopyInto(&out.PeerPrivateKeyRef)
 	in.SSHPrivateKeyRef.DeepCopyInto(&out.SSHPrivateKeyRef)
 	in.SSHPublicKeyRef.DeepCopyInto(&out.SSHPublicKeyRef)
 	out.ServiceRef = in.ServiceRef
+	if in.SnapshotSource != nil {
+		in, out := &in.SnapshotSource, &out.SnapshotSource
+		*out = new(EtcdSnapshotSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DataVolumeClaimTemplate != nil {
+		in, out := &in.DataVolumeClaimTemplate, &out.DataVolumeClaimTemplate
+		*out = new(v1.PersistentVolumeClaimTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RunStrategy != nil {
+		in, out := &in.RunStrategy, &out.RunStrategy
+		*out = new(EtcdNodeRunStrategy)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(EtcdNodeResources)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InstanceType != nil {
+		in, out := &in.InstanceType, &out.InstanceType
+		*out = new(EtcdNodeInstanceType)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SSH != nil {
+		in, out := &in.SSH, &out.SSH
+		*out = new(EtcdNodeSSHSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Artifacts != nil {
+		in, out := &in.Artifacts, &out.Artifacts
+		*out = new(EtcdArtifactsSpec)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(EtcdConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraArgs != nil {
+		in, out := &in.ExtraArgs, &out.ExtraArgs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExtraCloudConfig != nil {
+		in, out := &in.ExtraCloudConfig, &out.ExtraCloudConfig
+		*out = new(EtcdCloudConfigSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Bootstrap != nil {
+		in, out := &in.Bootstrap, &out.Bootstrap
+		*out = new(EtcdNodeBootstrapSpec)
+		**out = **in
+	}
+	if in.Join != nil {
+		in, out := &in.Join, &out.Join
+		*out = new(EtcdJoinSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdNodeSpec.
@@ -467,6 +1258,11 @@ func (in *EtcdNodeStatus) DeepCopyInto(out *EtcdNodeStatus) {
 		*out = new(v1.LocalObjectReference)
 		**out = **in
 	}
+	if in.VirtualMachineRef != nil {
+		in, out := &in.VirtualMachineRef, &out.VirtualMachineRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
 	if in.VirtualMachineInstanceRef != nil {
 		in, out := &in.VirtualMachineInstanceRef, &out.VirtualMachineInstanceRef
 		*out = new(v1.LocalObjectReference)
@@ -477,6 +1273,33 @@ func (in *EtcdNodeStatus) DeepCopyInto(out *EtcdNodeStatus) {
 		*out = new(v1.LocalObjectReference)
 		**out = **in
 	}
+	if in.DataVolumeClaimRef != nil {
+		in, out := &in.DataVolumeClaimRef, &out.DataVolumeClaimRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.SSHHostKeyRef != nil {
+		in, out := &in.SSHHostKeyRef, &out.SSHHostKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(EtcdRestoreStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Certificates != nil {
+		in, out := &in.Certificates, &out.Certificates
+		*out = new(EtcdNodeCertificatesStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProvisioningSteps != nil {
+		in, out := &in.ProvisioningSteps, &out.ProvisioningSteps
+		*out = make([]EtcdNodeProvisioningStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]EtcdNodeCondition, len(*in))
@@ -513,6 +1336,102 @@ func (in *EtcdNodeTemplateSpec) DeepCopy() *EtcdNodeTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdRecoveryStatus) DeepCopyInto(out *EtcdRecoveryStatus) {
+	*out = *in
+	if in.SnapshotSource != nil {
+		in, out := &in.SnapshotSource, &out.SnapshotSource
+		*out = new(EtcdSnapshotSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdRecoveryStatus.
+func (in *EtcdRecoveryStatus) DeepCopy() *EtcdRecoveryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdRecoveryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdRemediationSpec) DeepCopyInto(out *EtcdRemediationSpec) {
+	*out = *in
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdRemediationSpec.
+func (in *EtcdRemediationSpec) DeepCopy() *EtcdRemediationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdRemediationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdRestoreStatus) DeepCopyInto(out *EtcdRestoreStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdRestoreStatus.
+func (in *EtcdRestoreStatus) DeepCopy() *EtcdRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdSnapshotSource) DeepCopyInto(out *EtcdSnapshotSource) {
+	*out = *in
+	if in.EtcdBackupRef != nil {
+		in, out := &in.EtcdBackupRef, &out.EtcdBackupRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdSnapshotSource.
+func (in *EtcdSnapshotSource) DeepCopy() *EtcdSnapshotSource {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdSnapshotSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EtcdSpec) DeepCopyInto(out *EtcdSpec) {
 	*out = *in
@@ -532,6 +1451,104 @@ func (in *EtcdSpec) DeepCopyInto(out *EtcdSpec) {
 		*out = new(v1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Bootstrap != nil {
+		in, out := &in.Bootstrap, &out.Bootstrap
+		*out = new(EtcdBootstrapSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DataVolumeClaimTemplate != nil {
+		in, out := &in.DataVolumeClaimTemplate, &out.DataVolumeClaimTemplate
+		*out = new(v1.PersistentVolumeClaimTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RunStrategy != nil {
+		in, out := &in.RunStrategy, &out.RunStrategy
+		*out = new(EtcdNodeRunStrategy)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(EtcdNodeResources)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InstanceType != nil {
+		in, out := &in.InstanceType, &out.InstanceType
+		*out = new(EtcdNodeInstanceType)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(EtcdCASpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CertificateRotation != nil {
+		in, out := &in.CertificateRotation, &out.CertificateRotation
+		*out = new(EtcdCertificateRotationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SSH != nil {
+		in, out := &in.SSH, &out.SSH
+		*out = new(EtcdNodeSSHSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Artifacts != nil {
+		in, out := &in.Artifacts, &out.Artifacts
+		*out = new(EtcdArtifactsSpec)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(EtcdConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraArgs != nil {
+		in, out := &in.ExtraArgs, &out.ExtraArgs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExtraCloudConfig != nil {
+		in, out := &in.ExtraCloudConfig, &out.ExtraCloudConfig
+		*out = new(EtcdCloudConfigSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Join != nil {
+		in, out := &in.Join, &out.Join
+		*out = new(EtcdJoinSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Remediation != nil {
+		in, out := &in.Remediation, &out.Remediation
+		*out = new(EtcdRemediationSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdSpec.
@@ -597,6 +1614,21 @@ func (in *EtcdStatus) DeepCopyInto(out *EtcdStatus) {
 		*out = new(v1.LocalObjectReference)
 		**out = **in
 	}
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(EtcdRestoreStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Recovery != nil {
+		in, out := &in.Recovery, &out.Recovery
+		*out = new(EtcdRecoveryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Certificates != nil {
+		in, out := &in.Certificates, &out.Certificates
+		*out = new(EtcdCertificatesStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]EtcdCondition, len(*in))
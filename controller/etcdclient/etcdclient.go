@@ -0,0 +1,235 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package etcdclient provides clients of etcd clusters which controllers use to access etcd members with client
+// certificates stored in Secrets.
+package etcdclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	k8s_secret "github.com/kkohtaka/kubernetesimal/k8s/secret"
+	k8s_service "github.com/kkohtaka/kubernetesimal/k8s/service"
+)
+
+const (
+	// DefaultRequestTimeout is a timeout of a request to etcd members.
+	DefaultRequestTimeout = 5 * time.Second
+
+	defaultMemberStatusTimeout = time.Second
+)
+
+// Voter is a voting member of an etcd cluster.
+type Voter struct {
+	// Name is a name of the member. It's empty if the member hasn't started yet.
+	Name string
+	// Healthy is whether the member responds to a status request.
+	Healthy bool
+}
+
+// Credentials is a set of references to Secret keys which compose credentials to access etcd members.
+type Credentials struct {
+	CACertificateRef     *corev1.SecretKeySelector
+	ClientCertificateRef *corev1.SecretKeySelector
+	ClientPrivateKeyRef  *corev1.SecretKeySelector
+}
+
+// CredentialsFromEtcdStatus returns credentials which an Etcd prepares for its etcd cluster.
+func CredentialsFromEtcdStatus(status *kubernetesimalv1alpha1.EtcdStatus) *Credentials {
+	return &Credentials{
+		CACertificateRef:     status.CACertificateRef,
+		ClientCertificateRef: status.ClientCertificateRef,
+		ClientPrivateKeyRef:  status.ClientPrivateKeyRef,
+	}
+}
+
+// CredentialsFromEtcdNodeSpec returns credentials which an EtcdNode is given for its etcd cluster.
+func CredentialsFromEtcdNodeSpec(spec *kubernetesimalv1alpha1.EtcdNodeSpec) *Credentials {
+	return &Credentials{
+		CACertificateRef:     &spec.CACertificateRef,
+		ClientCertificateRef: &spec.ClientCertificateRef,
+		ClientPrivateKeyRef:  &spec.ClientPrivateKeyRef,
+	}
+}
+
+// NewTLSConfig returns a TLS config to access etcd members with credentials in Secrets of the namespace. It returns a
+// RequeueError if the credentials aren't prepared yet.
+func NewTLSConfig(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	credentials *Credentials,
+) (*tls.Config, error) {
+	caCertificate, err := getCredential(ctx, c, namespace, credentials.CACertificateRef, "a CA certificate")
+	if err != nil {
+		return nil, err
+	}
+	rootCAs := x509.NewCertPool()
+	if ok := rootCAs.AppendCertsFromPEM(caCertificate); !ok {
+		return nil, fmt.Errorf("unable to load a client CA certificate from Secret")
+	}
+
+	clientCertificate, err := getCredential(ctx, c, namespace, credentials.ClientCertificateRef, "a client certificate")
+	if err != nil {
+		return nil, err
+	}
+	clientPrivateKey, err := getCredential(ctx, c, namespace, credentials.ClientPrivateKeyRef, "a client private key")
+	if err != nil {
+		return nil, err
+	}
+
+	certificate, err := tls.X509KeyPair(clientCertificate, clientPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load a client certificate: %w", err)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{
+			certificate,
+		},
+		RootCAs:            rootCAs,
+		InsecureSkipVerify: true,
+	}, nil
+}
+
+func getCredential(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	ref *corev1.SecretKeySelector,
+	name string,
+) ([]byte, error) {
+	if ref == nil {
+		return nil, errors.NewRequeueError(fmt.Sprintf("waiting for %s prepared", name))
+	}
+	value, err := k8s_secret.GetValueFromSecretKeySelector(ctx, c, namespace, ref)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errors.NewRequeueError(fmt.Sprintf("waiting for %s prepared", name)).Wrap(err)
+		}
+		return nil, fmt.Errorf("unable to get %s: %w", name, err)
+	}
+	return value, nil
+}
+
+// New returns a client of an etcd cluster which accesses etcd members through the Service of the namespace.
+func New(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	serviceRef *corev1.LocalObjectReference,
+	credentials *Credentials,
+) (*clientv3.Client, error) {
+	address, err := k8s_service.GetAddressFromServiceRef(ctx, c, namespace, "etcd", serviceRef)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get an etcd address from a Service: %w", err)
+	}
+	return NewForEndpoint(ctx, c, namespace, fmt.Sprintf("https://%s", address), credentials)
+}
+
+// NewForEndpoint returns a client of an etcd cluster which accesses an etcd member at the endpoint.
+func NewForEndpoint(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	endpoint string,
+	credentials *Credentials,
+) (*clientv3.Client, error) {
+	tlsConfig, err := NewTLSConfig(ctx, c, namespace, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	etcdClient, err := clientv3.New(clientv3.Config{
+		Endpoints: []string{
+			endpoint,
+		},
+		TLS:         tlsConfig,
+		DialTimeout: DefaultRequestTimeout,
+	})
+	if err != nil {
+		return nil, errors.NewRequeueError("waiting for an etcd cluster become reachable").
+			Wrap(err).
+			WithDelay(5 * time.Second)
+	}
+	return etcdClient, nil
+}
+
+// ListVoters returns voting members of an etcd cluster with their health. A member which hasn't started yet has no
+// name, and it's unhealthy.
+func ListVoters(ctx context.Context, etcdClient *clientv3.Client) ([]Voter, error) {
+	listCtx, listCancel := context.WithTimeout(ctx, DefaultRequestTimeout)
+	resp, err := etcdClient.MemberList(listCtx)
+	listCancel()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list etcd members: %w", err)
+	}
+
+	var voters []Voter
+	for _, m := range resp.Members {
+		if m.IsLearner {
+			continue
+		}
+		voters = append(voters, Voter{
+			Name:    m.Name,
+			Healthy: m.Name != "" && isMemberHealthy(ctx, etcdClient, m.ClientURLs),
+		})
+	}
+	return voters, nil
+}
+
+// CountHealthyVoters returns the number of healthy members among voting members.
+func CountHealthyVoters(voters []Voter) int {
+	healthy := 0
+	for _, v := range voters {
+		if v.Healthy {
+			healthy++
+		}
+	}
+	return healthy
+}
+
+// isMemberHealthy returns whether any of client URLs of an etcd member responds to a status request.
+func isMemberHealthy(ctx context.Context, etcdClient *clientv3.Client, clientURLs []string) bool {
+	for _, u := range clientURLs {
+		statusCtx, statusCancel := context.WithTimeout(ctx, defaultMemberStatusTimeout)
+		_, err := etcdClient.Status(statusCtx, u)
+		statusCancel()
+		if err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package quorum provides arithmetic on quorums of etcd clusters, which controllers use to decide whether they can
+// change membership of an etcd cluster safely.
+package quorum
+
+// Size returns the number of voting members which must be healthy for an etcd cluster with the number of voting
+// members to keep its quorum, i.e. ⌊n/2⌋+1.
+func Size(voters int) int {
+	return voters/2 + 1
+}
+
+// CanRemoveMember returns whether a voting member can be removed from an etcd cluster with the number of voting
+// members and healthy ones among them. The cluster must have its quorum to commit the removal, and healthy members
+// left after the removal must form a quorum of the shrunk cluster. The last voting member can't be removed.
+func CanRemoveMember(voters, healthyVoters int, healthy bool) bool {
+	if voters <= 1 {
+		return false
+	}
+	if healthyVoters < Size(voters) {
+		return false
+	}
+	remaining := healthyVoters
+	if healthy {
+		remaining--
+	}
+	return remaining >= Size(voters-1)
+}
@@ -0,0 +1,110 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package quorum_test
+
+import (
+	"testing"
+
+	"github.com/kkohtaka/kubernetesimal/controller/quorum"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSize(t *testing.T) {
+	for voters, want := range map[int]int{1: 1, 2: 2, 3: 2, 4: 3, 5: 3} {
+		assert.Equal(t, want, quorum.Size(voters), "voters: %d", voters)
+	}
+}
+
+func TestCanRemoveMember(t *testing.T) {
+	tests := []struct {
+		name          string
+		voters        int
+		healthyVoters int
+		healthy       bool
+		want          bool
+	}{
+		{
+			name:          "the last member",
+			voters:        1,
+			healthyVoters: 1,
+			healthy:       true,
+			want:          false,
+		},
+		{
+			name:          "a healthy member of a healthy 2-member cluster",
+			voters:        2,
+			healthyVoters: 2,
+			healthy:       true,
+			want:          true,
+		},
+		{
+			name:          "an unhealthy member of a 2-member cluster",
+			voters:        2,
+			healthyVoters: 1,
+			healthy:       false,
+			want:          false,
+		},
+		{
+			name:          "a healthy member of a healthy 3-member cluster",
+			voters:        3,
+			healthyVoters: 3,
+			healthy:       true,
+			want:          true,
+		},
+		{
+			name:          "a healthy member of a degraded 3-member cluster",
+			voters:        3,
+			healthyVoters: 2,
+			healthy:       true,
+			want:          false,
+		},
+		{
+			name:          "an unhealthy member of a degraded 3-member cluster",
+			voters:        3,
+			healthyVoters: 2,
+			healthy:       false,
+			want:          true,
+		},
+		{
+			name:          "an unhealthy member of a 3-member cluster without a quorum",
+			voters:        3,
+			healthyVoters: 1,
+			healthy:       false,
+			want:          false,
+		},
+		{
+			name:          "a healthy member of a degraded 5-member cluster",
+			voters:        5,
+			healthyVoters: 4,
+			healthy:       true,
+			want:          true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, quorum.CanRemoveMember(tt.voters, tt.healthyVoters, tt.healthy))
+		})
+	}
+}
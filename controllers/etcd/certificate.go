@@ -0,0 +1,326 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcd
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	k8s_object "github.com/kkohtaka/kubernetesimal/k8s/object"
+	k8s_secret "github.com/kkohtaka/kubernetesimal/k8s/secret"
+	"github.com/kkohtaka/kubernetesimal/observability/tracing"
+	"github.com/kkohtaka/kubernetesimal/pki"
+)
+
+const (
+	defaultCertificateRenewBefore = 30 * 24 * time.Hour
+)
+
+// reconcileCertificateRotation tracks the expiry of certificates of an etcd cluster, and rotates them when they are
+// near expiry or a rotation is requested with an annotation.
+// The client certificate and the certificate for peer communication are re-issued at once, and then certificates of
+// etcd members are rotated one by one so that the etcd cluster keeps its quorum.
+func reconcileCertificateRotation(
+	ctx context.Context,
+	c client.Client,
+	scheme *runtime.Scheme,
+	e client.Object,
+	spec *kubernetesimalv1alpha1.EtcdSpec,
+	status *kubernetesimalv1alpha1.EtcdStatus,
+) (*kubernetesimalv1alpha1.EtcdStatus, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "reconcileCertificateRotation")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	certificates := &kubernetesimalv1alpha1.EtcdCertificatesStatus{}
+	if status.Certificates != nil {
+		certificates = status.Certificates.DeepCopy()
+	}
+
+	var err error
+	if certificates.CANotAfter, err = getCertificateNotAfter(ctx, c, e, status.CACertificateRef); err != nil {
+		return status, err
+	}
+	if certificates.ClientNotAfter, err = getCertificateNotAfter(ctx, c, e, status.ClientCertificateRef); err != nil {
+		return status, err
+	}
+	if certificates.PeerNotAfter, err = getCertificateNotAfter(ctx, c, e, status.PeerCertificateRef); err != nil {
+		return status, err
+	}
+
+	nodes, err := getComponentEtcdNodes(ctx, c, e)
+	if err != nil {
+		return status, err
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	certificates.ServerNotAfter = nil
+	for _, node := range nodes {
+		if !node.DeletionTimestamp.IsZero() || node.Status.Certificates == nil {
+			continue
+		}
+		for _, notAfter := range []*metav1.Time{
+			node.Status.Certificates.ServerNotAfter,
+			node.Status.Certificates.PeerNotAfter,
+		} {
+			if notAfter != nil && (certificates.ServerNotAfter == nil || notAfter.Before(certificates.ServerNotAfter)) {
+				certificates.ServerNotAfter = notAfter.DeepCopy()
+			}
+		}
+	}
+	status.Certificates = certificates
+
+	if message := getCAExpiryMessage(spec, certificates, time.Now()); message != "" {
+		if !status.IsCAExpiring() {
+			logger.Info("A CA certificate of an etcd cluster is near expiry.", "notAfter", certificates.CANotAfter)
+		}
+		status.WithCAExpiring(true, message).DeepCopyInto(status)
+	} else if status.IsCAExpiring() {
+		status.WithCAExpiring(false, "").DeepCopyInto(status)
+	}
+
+	if !status.AreCertificatesRotating() {
+		if !status.IsReady() || !status.AreMembersHealthy() || status.IsUpgrading() {
+			return status, nil
+		}
+
+		reason := getCertificateRotationReason(e, spec, certificates, time.Now())
+		if reason == "" {
+			return status, nil
+		}
+
+		// cert-manager renews certificates which it issued by itself.
+		if !isIssuedByCertManager(spec) {
+			if certificates.ClientNotAfter, err = reissueCertificate(
+				ctx,
+				c,
+				scheme,
+				e,
+				spec,
+				status,
+				newClientCertificateName(e),
+			); err != nil {
+				return status, fmt.Errorf("unable to re-issue a client certificate: %w", err)
+			}
+			if certificates.PeerNotAfter, err = reissueCertificate(
+				ctx,
+				c,
+				scheme,
+				e,
+				spec,
+				status,
+				newPeerCertificateName(e),
+			); err != nil {
+				return status, fmt.Errorf("unable to re-issue a certificate for peer communication: %w", err)
+			}
+		}
+
+		certificates.RotationID = time.Now().UTC().Format(time.RFC3339)
+		certificates.ObservedRotationRequest = e.GetAnnotations()[kubernetesimalv1alpha1.EtcdRotateCertificatesAnnotation]
+		status.WithCertificatesRotating(true, reason).DeepCopyInto(status)
+		logger.Info("Rotating certificates of an etcd cluster was started.", "reason", reason)
+	}
+
+	for _, node := range nodes {
+		if !node.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if node.GetAnnotations()[kubernetesimalv1alpha1.EtcdNodeCertificateRotationAnnotation] != certificates.RotationID {
+			patch := client.MergeFrom(node.DeepCopy())
+			annotations := node.GetAnnotations()
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			annotations[kubernetesimalv1alpha1.EtcdNodeCertificateRotationAnnotation] = certificates.RotationID
+			node.SetAnnotations(annotations)
+			if err := c.Patch(ctx, node, patch); err != nil {
+				return status, fmt.Errorf("unable to request rotating certificates of EtcdNode %s: %w", node.Name, err)
+			}
+			logger.Info("Rotating certificates of an etcd member was requested.", "etcdnode", node.Name)
+			return status, errors.NewRequeueError("waiting for certificates of an etcd member rotated").
+				WithDelay(5 * time.Second)
+		}
+		if node.Status.Certificates == nil ||
+			node.Status.Certificates.ObservedRotation != certificates.RotationID ||
+			!node.Status.IsReady() {
+			return status, errors.NewRequeueError("waiting for certificates of an etcd member rotated").
+				WithDelay(5 * time.Second)
+		}
+	}
+
+	now := metav1.Now()
+	certificates.LastRotationTime = &now
+	status.WithCertificatesRotating(false, "").DeepCopyInto(status)
+	logger.Info("Rotating certificates of an etcd cluster was completed.")
+	return status, nil
+}
+
+// getCertificateRotationReason returns a reason why certificates of an etcd cluster should be rotated. It returns an
+// empty string if they don't need to be rotated.
+// A certificate which expires with the CA isn't rotated for its expiry, since a certificate signed by the CA can't
+// outlive the CA.
+func getCertificateRotationReason(
+	e client.Object,
+	spec *kubernetesimalv1alpha1.EtcdSpec,
+	certificates *kubernetesimalv1alpha1.EtcdCertificatesStatus,
+	now time.Time,
+) string {
+	if requested := e.GetAnnotations()[kubernetesimalv1alpha1.EtcdRotateCertificatesAnnotation]; requested != "" &&
+		requested != certificates.ObservedRotationRequest {
+		return "a rotation was requested"
+	}
+
+	deadline := metav1.NewTime(now.Add(getCertificateRenewBefore(spec)))
+	notAfters := []*metav1.Time{certificates.ServerNotAfter}
+	if !isIssuedByCertManager(spec) {
+		notAfters = append(notAfters, certificates.ClientNotAfter, certificates.PeerNotAfter)
+	}
+	for _, notAfter := range notAfters {
+		if notAfter == nil || !notAfter.Before(&deadline) {
+			continue
+		}
+		if certificates.CANotAfter != nil && !notAfter.Before(certificates.CANotAfter) {
+			continue
+		}
+		return "certificates are near expiry"
+	}
+	return ""
+}
+
+// getCAExpiryMessage returns a message about the CA certificate of an etcd cluster if it's near expiry. It returns an
+// empty string otherwise. The CA issued by cert-manager is renewed by cert-manager.
+func getCAExpiryMessage(
+	spec *kubernetesimalv1alpha1.EtcdSpec,
+	certificates *kubernetesimalv1alpha1.EtcdCertificatesStatus,
+	now time.Time,
+) string {
+	if certificates.CANotAfter == nil || isIssuedByCertManager(spec) {
+		return ""
+	}
+	if !certificates.CANotAfter.Time.Before(now.Add(getCertificateRenewBefore(spec))) {
+		return ""
+	}
+	return fmt.Sprintf(
+		"the CA certificate expires at %s, and certificates signed by it can't be renewed beyond that",
+		certificates.CANotAfter.UTC().Format(time.RFC3339),
+	)
+}
+
+// getCertificateRenewBefore returns how long before expiry certificates of an etcd cluster are renewed.
+func getCertificateRenewBefore(spec *kubernetesimalv1alpha1.EtcdSpec) time.Duration {
+	if spec.CertificateRotation != nil && spec.CertificateRotation.RenewBefore != nil {
+		return spec.CertificateRotation.RenewBefore.Duration
+	}
+	return defaultCertificateRenewBefore
+}
+
+func getCertificateNotAfter(
+	ctx context.Context,
+	c client.Client,
+	e client.Object,
+	ref *corev1.SecretKeySelector,
+) (*metav1.Time, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	cert, err := k8s_secret.GetCertificateFromSecretKeySelector(ctx, c, e.GetNamespace(), ref)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to load a certificate from a Secret %s: %w", ref.Name, err)
+	}
+	return newNotAfter(cert), nil
+}
+
+// reissueCertificate replaces a pair of certificate and private key stored in a Secret with the specified name with
+// a new one signed by the CA.
+func reissueCertificate(
+	ctx context.Context,
+	c client.Client,
+	scheme *runtime.Scheme,
+	e client.Object,
+	spec *kubernetesimalv1alpha1.EtcdSpec,
+	status *kubernetesimalv1alpha1.EtcdStatus,
+	name string,
+) (*metav1.Time, error) {
+	caCert, err := k8s_secret.GetCertificateFromSecretKeySelector(ctx, c, e.GetNamespace(), status.CACertificateRef)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load a CA certificate from a Secret: %w", err)
+	}
+	caPrivateKey, err := k8s_secret.GetPrivateKeyFromSecretKeySelector(ctx, c, e.GetNamespace(), status.CAPrivateKeyRef)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load a CA private key from a Secret: %w", err)
+	}
+
+	certificate, privateKey, err := pki.CreateClientCertificateAndPrivateKey(
+		name,
+		pki.KeyAlgorithm(spec.CertificateKeyAlgorithm),
+		caCert,
+		caPrivateKey,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := k8s_secret.Reconcile(
+		ctx,
+		e,
+		c,
+		name,
+		e.GetNamespace(),
+		k8s_object.WithOwner(e, scheme),
+		k8s_secret.WithType(corev1.SecretTypeTLS),
+		k8s_secret.WithDataWithKey(corev1.TLSCertKey, certificate),
+		k8s_secret.WithDataWithKey(corev1.TLSPrivateKeyKey, privateKey),
+	); err != nil {
+		return nil, err
+	}
+
+	cert, err := pki.ParseCertificate(certificate)
+	if err != nil {
+		return nil, err
+	}
+	return newNotAfter(cert), nil
+}
+
+func newNotAfter(cert *x509.Certificate) *metav1.Time {
+	notAfter := metav1.NewTime(cert.NotAfter)
+	return &notAfter
+}
@@ -0,0 +1,116 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+)
+
+func TestGetCertificateRotationReason(t *testing.T) {
+	now := time.Now()
+	after := func(d time.Duration) *metav1.Time {
+		t := metav1.NewTime(now.Add(d))
+		return &t
+	}
+	year := 365 * 24 * time.Hour
+
+	for _, tc := range []struct {
+		name         string
+		annotations  map[string]string
+		certificates *kubernetesimalv1alpha1.EtcdCertificatesStatus
+		expectRotate bool
+	}{
+		{
+			name: "certificates far from expiry",
+			certificates: &kubernetesimalv1alpha1.EtcdCertificatesStatus{
+				CANotAfter:     after(10 * year),
+				ClientNotAfter: after(year),
+				PeerNotAfter:   after(year),
+				ServerNotAfter: after(year),
+			},
+		},
+		{
+			name: "a certificate near expiry",
+			certificates: &kubernetesimalv1alpha1.EtcdCertificatesStatus{
+				CANotAfter:     after(10 * year),
+				ClientNotAfter: after(year),
+				PeerNotAfter:   after(year),
+				ServerNotAfter: after(time.Hour),
+			},
+			expectRotate: true,
+		},
+		{
+			name: "certificates expiring with the CA",
+			certificates: &kubernetesimalv1alpha1.EtcdCertificatesStatus{
+				CANotAfter:     after(time.Hour),
+				ClientNotAfter: after(time.Hour),
+				PeerNotAfter:   after(time.Hour),
+				ServerNotAfter: after(time.Hour),
+			},
+		},
+		{
+			name:        "a requested rotation",
+			annotations: map[string]string{kubernetesimalv1alpha1.EtcdRotateCertificatesAnnotation: "1"},
+			certificates: &kubernetesimalv1alpha1.EtcdCertificatesStatus{
+				CANotAfter: after(time.Hour),
+			},
+			expectRotate: true,
+		},
+		{
+			name:        "an observed rotation request",
+			annotations: map[string]string{kubernetesimalv1alpha1.EtcdRotateCertificatesAnnotation: "1"},
+			certificates: &kubernetesimalv1alpha1.EtcdCertificatesStatus{
+				ObservedRotationRequest: "1",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e := &kubernetesimalv1alpha1.Etcd{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations},
+			}
+			reason := getCertificateRotationReason(e, &e.Spec, tc.certificates, now)
+			assert.Equal(t, tc.expectRotate, reason != "", reason)
+		})
+	}
+}
+
+func TestGetCAExpiryMessage(t *testing.T) {
+	now := time.Now()
+	spec := &kubernetesimalv1alpha1.EtcdSpec{}
+
+	notAfter := metav1.NewTime(now.Add(10 * 365 * 24 * time.Hour))
+	assert.Empty(t, getCAExpiryMessage(spec, &kubernetesimalv1alpha1.EtcdCertificatesStatus{CANotAfter: &notAfter}, now))
+
+	notAfter = metav1.NewTime(now.Add(24 * time.Hour))
+	assert.NotEmpty(t, getCAExpiryMessage(spec, &kubernetesimalv1alpha1.EtcdCertificatesStatus{CANotAfter: &notAfter}, now))
+
+	assert.Empty(t, getCAExpiryMessage(spec, &kubernetesimalv1alpha1.EtcdCertificatesStatus{}, now))
+}
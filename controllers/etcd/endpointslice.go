@@ -121,8 +121,9 @@ func reconcileEndpointSlice(
 			continue
 		}
 
+		// A learner doesn't serve client requests until it's promoted to a voting member.
 		var (
-			serving     = node.Status.IsReady()
+			serving     = node.Status.IsReady() && !node.Status.Learner
 			terminating = !node.DeletionTimestamp.IsZero() || !peerService.DeletionTimestamp.IsZero()
 			ready       = serving && !terminating
 		)
@@ -27,8 +27,9 @@ package etcd
 import (
 	"context"
 	"crypto/tls"
-	"crypto/x509"
+	"encoding/json"
 	"fmt"
+	nethttp "net/http"
 	"sort"
 	"strings"
 	"time"
@@ -40,7 +41,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
-	k8s_secret "github.com/kkohtaka/kubernetesimal/k8s/secret"
+	"github.com/kkohtaka/kubernetesimal/controller/etcdclient"
 	k8s_service "github.com/kkohtaka/kubernetesimal/k8s/service"
 	"github.com/kkohtaka/kubernetesimal/net/http"
 	"github.com/kkohtaka/kubernetesimal/observability/tracing"
@@ -52,6 +53,8 @@ const (
 	defaultMemberStatusTimeout = time.Second
 )
 
+// getEtcdTLSConfig returns a TLS config to access etcd members with the client certificate of an etcd cluster. It
+// returns nil if the certificate isn't prepared yet.
 func getEtcdTLSConfig(
 	ctx context.Context,
 	c client.Client,
@@ -63,78 +66,19 @@ func getEtcdTLSConfig(
 	defer span.End()
 	logger := log.FromContext(ctx)
 
-	if status.CACertificateRef == nil {
-		logger.V(4).Info("a CA certificate for an etcd Service is not prepared yet")
+	if status.CACertificateRef == nil || status.ClientCertificateRef == nil || status.ClientPrivateKeyRef == nil {
+		logger.V(4).Info("A client certificate for an etcd Service is not prepared yet.")
 		return nil, nil
 	}
-	caCertificate, err := k8s_secret.GetValueFromSecretKeySelector(
-		ctx,
-		c,
-		obj.GetNamespace(),
-		status.CACertificateRef,
-	)
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			logger.V(4).Info("Skip probing an etcd since CA certificate isn't prepared yet.")
-			return nil, nil
-		}
-		return nil, fmt.Errorf("unable to get a CA certificate: %w", err)
-	}
-
-	rootCAs, err := x509.SystemCertPool()
-	if err != nil {
-		return nil, fmt.Errorf("unable to load a client CA certificates from the system: %w", err)
-	}
-	if ok := rootCAs.AppendCertsFromPEM(caCertificate); !ok {
-		return nil, fmt.Errorf("unable to load a client CA certificate from Secret")
-	}
-
-	if status.ClientCertificateRef == nil {
-		return nil, fmt.Errorf("a client certificate for an etcd Service is not prepared yet")
-	}
-	clientCertificate, err := k8s_secret.GetValueFromSecretKeySelector(
-		ctx,
-		c,
-		obj.GetNamespace(),
-		status.ClientCertificateRef,
-	)
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			logger.Info("Skip probing an etcd since a client certificate isn't prepared yet.")
-			return nil, nil
-		}
-		return nil, fmt.Errorf("unable to get a client certificate: %w", err)
-	}
-
-	if status.ClientCertificateRef == nil {
-		return nil, fmt.Errorf("a client certificate for an etcd Service is not prepared yet")
-	}
-	clientPrivateKey, err := k8s_secret.GetValueFromSecretKeySelector(
-		ctx,
-		c,
-		obj.GetNamespace(),
-		status.ClientPrivateKeyRef,
-	)
+	tlsConfig, err := etcdclient.NewTLSConfig(ctx, c, obj.GetNamespace(), etcdclient.CredentialsFromEtcdStatus(status))
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			logger.V(4).Info("Skip probing an etcd since a client private key isn't prepared yet.")
+			logger.V(4).Info("Skip probing an etcd since a client certificate isn't prepared yet.")
 			return nil, nil
 		}
-		return nil, fmt.Errorf("unable to get a client private key: %w", err)
+		return nil, err
 	}
-
-	certificate, err := tls.X509KeyPair(clientCertificate, clientPrivateKey)
-	if err != nil {
-		return nil, fmt.Errorf("unable to load a client certificate: %w", err)
-	}
-
-	return &tls.Config{
-		Certificates: []tls.Certificate{
-			certificate,
-		},
-		RootCAs:            rootCAs,
-		InsecureSkipVerify: true,
-	}, nil
+	return tlsConfig, nil
 }
 
 func probeEtcd(
@@ -281,3 +225,68 @@ members:
 	}
 	return true, "", nil
 }
+
+func getEtcdClusterVersion(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	_ *kubernetesimalv1alpha1.EtcdSpec,
+	status *kubernetesimalv1alpha1.EtcdStatus,
+) (string, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "getEtcdClusterVersion")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	if status.ServiceRef == nil {
+		logger.V(4).Info("a Service for an etcd is not prepared yet")
+		return "", nil
+	}
+	address, err := k8s_service.GetAddressFromServiceRef(ctx, c, obj.GetNamespace(), "etcd", status.ServiceRef)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.V(4).Info("Skip getting a cluster version since an etcd Service isn't prepared yet.")
+			return "", nil
+		}
+		return "", fmt.Errorf("unable to get an etcd address from an etcd Service: %w", err)
+	}
+
+	tlsConfig, err := getEtcdTLSConfig(ctx, c, obj, status)
+	if err != nil {
+		return "", fmt.Errorf("unable to get a TLS config for an etcd cluster: %w", err)
+	}
+
+	requestCtx, requestCancel := context.WithTimeout(ctx, defaultRequestTimeout)
+	defer requestCancel()
+	req, err := nethttp.NewRequestWithContext(
+		requestCtx,
+		nethttp.MethodGet,
+		fmt.Sprintf("https://%s/version", address),
+		nil,
+	)
+	if err != nil {
+		return "", fmt.Errorf("unable to create a request: %w", err)
+	}
+	transport := &nethttp.Transport{
+		TLSClientConfig: tlsConfig,
+	}
+	defer transport.CloseIdleConnections()
+	resp, err := (&nethttp.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to get versions of an etcd: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != nethttp.StatusOK {
+		return "", fmt.Errorf("unable to get versions of an etcd: unexpected status code %d", resp.StatusCode)
+	}
+
+	var versions struct {
+		Server  string `json:"etcdserver"`
+		Cluster string `json:"etcdcluster"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
+		return "", fmt.Errorf("unable to decode versions of an etcd: %w", err)
+	}
+	logger.V(4).Info("Get versions of an etcd.", "server", versions.Server, "cluster", versions.Cluster)
+	return versions.Cluster, nil
+}
@@ -27,6 +27,7 @@ package etcd
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -54,3 +55,44 @@ func getComponentEtcdNodes(ctx context.Context, c client.Client, e client.Object
 	}
 	return nodes, nil
 }
+
+// getRestoreStatus returns the observed state of restoring the first EtcdNode from a snapshot.
+func getRestoreStatus(
+	ctx context.Context,
+	c client.Client,
+	e client.Object,
+) (*kubernetesimalv1alpha1.EtcdRestoreStatus, error) {
+	nodes, err := getComponentEtcdNodes(ctx, c, e)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		if !node.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if node.Spec.AsFirstNode && node.Status.Restore != nil {
+			return node.Status.Restore.DeepCopy(), nil
+		}
+	}
+	return nil, nil
+}
+
+// getPendingLearners returns names of EtcdNodes whose etcd members are learners waiting to be promoted to voting
+// members.
+func getPendingLearners(ctx context.Context, c client.Client, e client.Object) ([]string, error) {
+	nodes, err := getComponentEtcdNodes(ctx, c, e)
+	if err != nil {
+		return nil, err
+	}
+	var learners []string
+	for _, node := range nodes {
+		if !node.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if node.Status.Learner {
+			learners = append(learners, fmt.Sprintf("%q", node.Name))
+		}
+	}
+	sort.Strings(learners)
+	return learners, nil
+}
@@ -0,0 +1,85 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+)
+
+func newFakeClient(t *testing.T, objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	require.NoError(t, kubernetesimalv1alpha1.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithStatusSubresource(objs...).Build()
+}
+
+func newComponentEtcdNode(
+	e client.Object,
+	name string,
+	status kubernetesimalv1alpha1.EtcdNodeStatus,
+) *kubernetesimalv1alpha1.EtcdNode {
+	return &kubernetesimalv1alpha1.EtcdNode{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: e.GetNamespace(),
+			Name:      name,
+			Labels:    newEtcdNodeTemplateSpecLabels(e),
+		},
+		Status: status,
+	}
+}
+
+func TestGetPendingLearners(t *testing.T) {
+	e := &kubernetesimalv1alpha1.Etcd{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "etcd"},
+	}
+	other := &kubernetesimalv1alpha1.Etcd{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other"},
+	}
+	leaving := newComponentEtcdNode(e, "leaving", kubernetesimalv1alpha1.EtcdNodeStatus{Learner: true})
+	leaving.Finalizers = []string{"kubernetesimal.kkohtaka.org/finalizer"}
+	now := metav1.Now()
+	leaving.DeletionTimestamp = &now
+
+	c := newFakeClient(t,
+		newComponentEtcdNode(e, "learner-b", kubernetesimalv1alpha1.EtcdNodeStatus{Learner: true}),
+		newComponentEtcdNode(e, "learner-a", kubernetesimalv1alpha1.EtcdNodeStatus{Learner: true}),
+		newComponentEtcdNode(e, "voter", kubernetesimalv1alpha1.EtcdNodeStatus{}),
+		newComponentEtcdNode(other, "other-learner", kubernetesimalv1alpha1.EtcdNodeStatus{Learner: true}),
+		leaving,
+	)
+
+	learners, err := getPendingLearners(context.Background(), c, e)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`"learner-a"`, `"learner-b"`}, learners)
+}
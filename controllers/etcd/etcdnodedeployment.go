@@ -34,6 +34,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -45,6 +46,11 @@ import (
 	"github.com/kkohtaka/kubernetesimal/observability/tracing"
 )
 
+var (
+	maxSurge       = intstr.FromInt(1)
+	maxUnavailable = intstr.FromInt(0)
+)
+
 func newEtcdNodeDeploymentName(e client.Object) string {
 	return e.GetName()
 }
@@ -85,15 +91,47 @@ func reconcileEtcdNodeDeployment(
 			CAPrivateKeyRef:                *status.CAPrivateKeyRef,
 			ClientCertificateRef:           *status.ClientCertificateRef,
 			ClientPrivateKeyRef:            *status.ClientPrivateKeyRef,
+			PeerCertificateRef:             *status.PeerCertificateRef,
+			PeerPrivateKeyRef:              *status.PeerPrivateKeyRef,
 			SSHPrivateKeyRef:               *status.SSHPrivateKeyRef,
 			SSHPublicKeyRef:                *status.SSHPublicKeyRef,
 			ServiceRef:                     *status.ServiceRef,
+			DataVolumeClaimTemplate:        spec.DataVolumeClaimTemplate,
+			RunStrategy:                    spec.RunStrategy,
+			Resources:                      spec.Resources,
+			InstanceType:                   spec.InstanceType,
+			NodeSelector:                   spec.NodeSelector,
+			Tolerations:                    spec.Tolerations,
+			Affinity:                       spec.Affinity,
+			TopologySpreadConstraints:      spec.TopologySpreadConstraints,
+			MemberCertificateSigner:        spec.MemberCertificateSigner,
+			PeerMTLS:                       spec.PeerMTLS,
+			SSH:                            spec.SSH,
+			Artifacts:                      spec.Artifacts,
+			BootstrapDriver:                spec.BootstrapDriver,
+			Config:                         spec.Config,
+			ExtraArgs:                      spec.ExtraArgs,
+			ExtraCloudConfig:               spec.ExtraCloudConfig,
 		},
 	}
+	// Only a non-default format is set to a template so that etcd members bootstrapped with cloud-init aren't rolled.
+	if spec.Bootstrap != nil && spec.Bootstrap.Format == kubernetesimalv1alpha1.EtcdBootstrapFormatIgnition {
+		template.Spec.Bootstrap = &kubernetesimalv1alpha1.EtcdNodeBootstrapSpec{
+			Format: spec.Bootstrap.Format,
+		}
+	}
+	// Likewise, only the Learner mode is set to a template so that existing etcd members aren't rolled.
+	if spec.Join != nil && spec.Join.Mode == kubernetesimalv1alpha1.EtcdJoinModeLearner {
+		template.Spec.Join = spec.Join.DeepCopy()
+	}
 
 	if !status.IsReadyOnce() {
 		// Create a single-node cluster before it becomes ready once.
 		template.Spec.AsFirstNode = true
+		if spec.Bootstrap != nil && spec.Bootstrap.FromSnapshot != nil {
+			// Restore the single-node cluster from a snapshot.
+			template.Spec.SnapshotSource = spec.Bootstrap.FromSnapshot.DeepCopy()
+		}
 
 		// If a corresponding deployment exists and the spec should be changed, scale its replicas to zero before
 		// changing the spec.
@@ -139,6 +177,12 @@ func reconcileEtcdNodeDeployment(
 		}
 	}
 
+	version, paused, err := getRolloutVersion(ctx, c, e, spec, status)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get a version of etcd to be rolled out: %w", err)
+	}
+	template.Spec.Version = version
+
 	var replicas int32 = 1
 	if spec.Replicas != nil {
 		replicas = *spec.Replicas
@@ -151,6 +195,12 @@ func reconcileEtcdNodeDeployment(
 		k8s_etcdnodedeployment.WithReplicas(replicas),
 		k8s_etcdnodedeployment.WithSelector(newEtcdNodeDeploymentSelector(e)),
 		k8s_etcdnodedeployment.WithTemplate(&template),
+		// Replace etcd members one by one so that the etcd cluster doesn't lose its quorum.
+		k8s_etcdnodedeployment.WithRollingUpdate(&kubernetesimalv1alpha1.RollingUpdateEtcdNodeDeployment{
+			MaxSurge:       &maxSurge,
+			MaxUnavailable: &maxUnavailable,
+		}),
+		k8s_etcdnodedeployment.WithPaused(paused),
 	); err != nil {
 		return nil, fmt.Errorf("unable to reconcile EtcdNodeDeployment: %w", err)
 	} else {
@@ -27,6 +27,7 @@ package etcd
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
@@ -39,6 +40,7 @@ import (
 	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
 	"github.com/kkohtaka/kubernetesimal/controller/errors"
 	"github.com/kkohtaka/kubernetesimal/controller/finalizer"
+	"github.com/kkohtaka/kubernetesimal/k8s/certmanager"
 	k8s_object "github.com/kkohtaka/kubernetesimal/k8s/object"
 	k8s_secret "github.com/kkohtaka/kubernetesimal/k8s/secret"
 	"github.com/kkohtaka/kubernetesimal/observability/tracing"
@@ -58,7 +60,7 @@ func reconcileCACertificate(
 	c client.Client,
 	scheme *runtime.Scheme,
 	obj client.Object,
-	_ *kubernetesimalv1alpha1.EtcdSpec,
+	spec *kubernetesimalv1alpha1.EtcdSpec,
 	status *kubernetesimalv1alpha1.EtcdStatus,
 ) (*corev1.SecretKeySelector, *corev1.SecretKeySelector, error) {
 	var span trace.Span
@@ -66,16 +68,25 @@ func reconcileCACertificate(
 	defer span.End()
 
 	if status.CAPrivateKeyRef != nil {
-		if name := status.CAPrivateKeyRef.LocalObjectReference.Name; name != newCACertificateName(obj) {
+		if name := status.CAPrivateKeyRef.LocalObjectReference.Name; name != getCASecretName(obj, spec) {
 			return nil, nil, fmt.Errorf("invalid Secret name %s to store a CA private key", name)
 		}
 	}
 	if status.CACertificateRef != nil {
-		if name := status.CACertificateRef.LocalObjectReference.Name; name != newCACertificateName(obj) {
+		if name := status.CACertificateRef.LocalObjectReference.Name; name != getCASecretName(obj, spec) {
 			return nil, nil, fmt.Errorf("invalid Secret name %s to store a CA certificate", name)
 		}
 	}
 
+	if spec.CA != nil {
+		switch {
+		case spec.CA.SecretRef != nil:
+			return reconcileExternalCACertificate(ctx, c, obj, spec.CA.SecretRef)
+		case spec.CA.IssuerRef != nil:
+			return reconcileIntermediateCACertificate(ctx, c, scheme, obj, spec.CA.IssuerRef, spec.CertificateKeyAlgorithm)
+		}
+	}
+
 	var ca corev1.Secret
 	if status.CAPrivateKeyRef != nil && status.CACertificateRef != nil {
 		if err := c.Get(
@@ -95,7 +106,10 @@ func reconcileCACertificate(
 		}
 	}
 
-	certificate, privateKey, err := pki.CreateCACertificateAndPrivateKey(newCACertificateIssuerName(obj))
+	certificate, privateKey, err := pki.CreateCACertificateAndPrivateKey(
+		newCACertificateIssuerName(obj),
+		pki.KeyAlgorithm(spec.CertificateKeyAlgorithm),
+	)
 	if err != nil {
 		return nil, nil, fmt.Errorf("unable to create a CA certificate for etcd: %w", err)
 	}
@@ -128,10 +142,201 @@ func reconcileCACertificate(
 	}
 }
 
+// getCASecretName returns a name of a Secret which holds a CA certificate and a CA private key of an etcd cluster.
+func getCASecretName(obj client.Object, spec *kubernetesimalv1alpha1.EtcdSpec) string {
+	if spec.CA != nil && spec.CA.SecretRef != nil {
+		return spec.CA.SecretRef.Name
+	}
+	return newCACertificateName(obj)
+}
+
+func newTLSSecretKeySelectors(name string) (*corev1.SecretKeySelector, *corev1.SecretKeySelector) {
+	return &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: name,
+			},
+			Key: corev1.TLSCertKey,
+		},
+		&corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: name,
+			},
+			Key: corev1.TLSPrivateKeyKey,
+		}
+}
+
+// reconcileExternalCACertificate verifies that an existing Secret specified by a user holds a CA certificate and a CA
+// private key.
+func reconcileExternalCACertificate(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	ref *corev1.LocalObjectReference,
+) (*corev1.SecretKeySelector, *corev1.SecretKeySelector, error) {
+	var ca corev1.Secret
+	if err := c.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: ref.Name}, &ca); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, errors.NewRequeueError("waiting for a CA Secret prepared").
+				Wrap(err).
+				WithDelay(10 * time.Second)
+		}
+		return nil, nil, fmt.Errorf("unable to get a Secret for a CA certificate: %w", err)
+	}
+	if _, ok := ca.Data[corev1.TLSCertKey]; !ok {
+		return nil, nil, fmt.Errorf("a Secret %s doesn't have a key %s", ref.Name, corev1.TLSCertKey)
+	}
+	if _, ok := ca.Data[corev1.TLSPrivateKeyKey]; !ok {
+		return nil, nil, fmt.Errorf("a Secret %s doesn't have a key %s", ref.Name, corev1.TLSPrivateKeyKey)
+	}
+	certificateRef, privateKeyRef := newTLSSecretKeySelectors(ref.Name)
+	return certificateRef, privateKeyRef, nil
+}
+
+// reconcileIntermediateCACertificate requests a cert-manager issuer to issue an intermediate CA of an etcd cluster.
+func reconcileIntermediateCACertificate(
+	ctx context.Context,
+	c client.Client,
+	scheme *runtime.Scheme,
+	obj client.Object,
+	ref *kubernetesimalv1alpha1.EtcdIssuerReference,
+	algorithm kubernetesimalv1alpha1.EtcdKeyAlgorithm,
+) (*corev1.SecretKeySelector, *corev1.SecretKeySelector, error) {
+	privateKeyAlgorithm, privateKeySize := newCertManagerPrivateKey(algorithm)
+	kind := ref.Kind
+	if kind == "" {
+		kind = certmanager.IssuerGroupVersionKind.Kind
+	}
+	group := ref.Group
+	if group == "" {
+		group = certmanager.GroupName
+	}
+
+	certificate, err := certmanager.ReconcileCertificate(
+		ctx,
+		c,
+		newCACertificateName(obj),
+		obj.GetNamespace(),
+		k8s_object.WithOwner(obj, scheme),
+		certmanager.WithCommonName(newCACertificateIssuerName(obj)),
+		certmanager.WithSecretName(newCACertificateName(obj)),
+		certmanager.WithIsCA(true),
+		certmanager.WithPrivateKey(privateKeyAlgorithm, privateKeySize),
+		certmanager.WithUsages("cert sign", "digital signature", "client auth", "server auth"),
+		certmanager.WithIssuerRef(ref.Name, kind, group),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to request a CA certificate: %w", err)
+	}
+	if !certmanager.IsReady(certificate) {
+		return nil, nil, errors.NewRequeueError("waiting for a CA certificate issued").WithDelay(10 * time.Second)
+	}
+	certificateRef, privateKeyRef := newTLSSecretKeySelectors(newCACertificateName(obj))
+	return certificateRef, privateKeyRef, nil
+}
+
+// reconcileIssuedCertificate requests a cert-manager Certificate signed by an intermediate CA of an etcd cluster, and
+// returns references to its certificate and private key once it's issued.
+func reconcileIssuedCertificate(
+	ctx context.Context,
+	c client.Client,
+	scheme *runtime.Scheme,
+	obj client.Object,
+	name string,
+	algorithm kubernetesimalv1alpha1.EtcdKeyAlgorithm,
+	usages ...string,
+) (*corev1.SecretKeySelector, *corev1.SecretKeySelector, error) {
+	privateKeyAlgorithm, privateKeySize := newCertManagerPrivateKey(algorithm)
+
+	if _, err := certmanager.ReconcileIssuer(
+		ctx,
+		c,
+		newCACertificateIssuerName(obj),
+		obj.GetNamespace(),
+		k8s_object.WithOwner(obj, scheme),
+		certmanager.WithCASecretName(newCACertificateName(obj)),
+	); err != nil {
+		return nil, nil, fmt.Errorf("unable to prepare an Issuer of an intermediate CA: %w", err)
+	}
+
+	certificate, err := certmanager.ReconcileCertificate(
+		ctx,
+		c,
+		name,
+		obj.GetNamespace(),
+		k8s_object.WithOwner(obj, scheme),
+		certmanager.WithCommonName(name),
+		certmanager.WithSecretName(name),
+		certmanager.WithUsages(usages...),
+		certmanager.WithPrivateKey(privateKeyAlgorithm, privateKeySize),
+		certmanager.WithIssuerRef(
+			newCACertificateIssuerName(obj),
+			certmanager.IssuerGroupVersionKind.Kind,
+			certmanager.GroupName,
+		),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to request a certificate %s: %w", name, err)
+	}
+	if !certmanager.IsReady(certificate) {
+		return nil, nil, errors.NewRequeueError("waiting for a certificate issued").WithDelay(10 * time.Second)
+	}
+	certificateRef, privateKeyRef := newTLSSecretKeySelectors(name)
+	return certificateRef, privateKeyRef, nil
+}
+
+// newCertManagerPrivateKey returns an algorithm and a size of a private key of a cert-manager Certificate.
+func newCertManagerPrivateKey(algorithm kubernetesimalv1alpha1.EtcdKeyAlgorithm) (string, int64) {
+	switch algorithm {
+	case kubernetesimalv1alpha1.EtcdKeyAlgorithmRSA2048:
+		return "RSA", 2048
+	case kubernetesimalv1alpha1.EtcdKeyAlgorithmRSA3072:
+		return "RSA", 3072
+	case kubernetesimalv1alpha1.EtcdKeyAlgorithmECDSAP256:
+		return "ECDSA", 256
+	case kubernetesimalv1alpha1.EtcdKeyAlgorithmECDSAP384:
+		return "ECDSA", 384
+	default:
+		return "RSA", 4096
+	}
+}
+
+// isIssuedByCertManager returns whether certificates of an etcd cluster are issued by cert-manager.
+func isIssuedByCertManager(spec *kubernetesimalv1alpha1.EtcdSpec) bool {
+	return spec.CA != nil && spec.CA.IssuerRef != nil
+}
+
+// finalizeCertificates deletes cert-manager Certificates of an etcd cluster before their Secrets are deleted so that
+// cert-manager doesn't issue them again.
+func finalizeCertificates(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdSpec,
+) error {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "finalizeCertificates")
+	defer span.End()
+
+	if !isIssuedByCertManager(spec) {
+		return nil
+	}
+	for _, name := range []string{
+		newClientCertificateName(obj),
+		newPeerCertificateName(obj),
+		newCACertificateName(obj),
+	} {
+		if err := finalizer.FinalizeObject(ctx, c, obj.GetNamespace(), name, certmanager.NewCertificate()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func finalizeCACertificateSecret(
 	ctx context.Context,
 	c client.Client,
 	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdSpec,
 	status *kubernetesimalv1alpha1.EtcdStatus,
 ) (*kubernetesimalv1alpha1.EtcdStatus, error) {
 	var span trace.Span
@@ -141,6 +346,11 @@ func finalizeCACertificateSecret(
 	if status.CACertificateRef == nil {
 		return status, nil
 	}
+	if spec.CA != nil && spec.CA.SecretRef != nil {
+		// A Secret specified by a user is left as it is.
+		status.CACertificateRef = nil
+		return status, nil
+	}
 	if err := finalizer.FinalizeSecret(ctx, c, obj.GetNamespace(), status.CACertificateRef.Name); err != nil {
 		return status, err
 	}
@@ -162,7 +372,7 @@ func reconcileClientCertificate(
 	c client.Client,
 	scheme *runtime.Scheme,
 	obj client.Object,
-	_ *kubernetesimalv1alpha1.EtcdSpec,
+	spec *kubernetesimalv1alpha1.EtcdSpec,
 	status *kubernetesimalv1alpha1.EtcdStatus,
 ) (*corev1.SecretKeySelector, *corev1.SecretKeySelector, error) {
 	var span trace.Span
@@ -180,6 +390,18 @@ func reconcileClientCertificate(
 		}
 	}
 
+	if isIssuedByCertManager(spec) {
+		return reconcileIssuedCertificate(
+			ctx,
+			c,
+			scheme,
+			obj,
+			newClientCertificateName(obj),
+			spec.CertificateKeyAlgorithm,
+			"client auth",
+		)
+	}
+
 	var secret corev1.Secret
 	if status.ClientPrivateKeyRef != nil && status.ClientCertificateRef != nil {
 		if err := c.Get(
@@ -227,6 +449,7 @@ func reconcileClientCertificate(
 
 	certificate, privateKey, err := pki.CreateClientCertificateAndPrivateKey(
 		newClientCertificateName(obj),
+		pki.KeyAlgorithm(spec.CertificateKeyAlgorithm),
 		caCert,
 		caPrivateKey,
 	)
@@ -267,7 +490,7 @@ func reconcilePeerCertificate(
 	c client.Client,
 	scheme *runtime.Scheme,
 	obj client.Object,
-	_ *kubernetesimalv1alpha1.EtcdSpec,
+	spec *kubernetesimalv1alpha1.EtcdSpec,
 	status *kubernetesimalv1alpha1.EtcdStatus,
 ) (*corev1.SecretKeySelector, *corev1.SecretKeySelector, error) {
 	var span trace.Span
@@ -285,6 +508,19 @@ func reconcilePeerCertificate(
 		}
 	}
 
+	if isIssuedByCertManager(spec) {
+		return reconcileIssuedCertificate(
+			ctx,
+			c,
+			scheme,
+			obj,
+			newPeerCertificateName(obj),
+			spec.CertificateKeyAlgorithm,
+			"server auth",
+			"client auth",
+		)
+	}
+
 	var secret corev1.Secret
 	if status.PeerPrivateKeyRef != nil && status.PeerCertificateRef != nil {
 		if err := c.Get(
@@ -332,6 +568,7 @@ func reconcilePeerCertificate(
 
 	certificate, privateKey, err := pki.CreateClientCertificateAndPrivateKey(
 		newPeerCertificateName(obj),
+		pki.KeyAlgorithm(spec.CertificateKeyAlgorithm),
 		caCert,
 		caPrivateKey,
 	)
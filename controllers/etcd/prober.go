@@ -27,6 +27,7 @@ package etcd
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/otel/trace"
@@ -141,6 +142,25 @@ func (r *Prober) doReconcile(
 		status.WithMembersHealthy(probed, message).DeepCopyInto(status)
 	}
 
+	if learners, err := getPendingLearners(ctx, r.Client, obj); err != nil {
+		return status, fmt.Errorf("unable to get learners waiting to be promoted: %w", err)
+	} else if len(learners) > 0 {
+		status.WithPromotionPending(
+			true,
+			fmt.Sprintf("[%s] are waiting to be promoted", strings.Join(learners, ", ")),
+		).DeepCopyInto(status)
+	} else {
+		status.WithPromotionPending(false, "").DeepCopyInto(status)
+	}
+
+	if status.IsReady() {
+		if clusterVersion, err := getEtcdClusterVersion(ctx, r.Client, obj, spec, status); err != nil {
+			return status, fmt.Errorf("unable to get a cluster version of an etcd: %w", err)
+		} else if clusterVersion != "" {
+			status.ClusterVersion = clusterVersion
+		}
+	}
+
 	return status, nil
 }
 
@@ -185,5 +205,11 @@ func getProbeInterval(status *kubernetesimalv1alpha1.EtcdStatus) time.Duration {
 	if !status.AreMembersHealthy() {
 		return probeIntervalOnNotReady
 	}
+	if status.IsUpgrading() {
+		return probeIntervalOnNotReady
+	}
+	if status.IsPromotionPending() {
+		return probeIntervalOnNotReady
+	}
 	return probeInterval
 }
@@ -34,6 +34,7 @@ import (
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -49,7 +50,8 @@ import (
 // Reconciler reconciles a Etcd object
 type Reconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 
 	Tracer trace.Tracer
 }
@@ -59,11 +61,13 @@ type Reconciler struct {
 //+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcds/finalizers,verbs=update
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cert-manager.io,resources=certificates;issuers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdnodedeployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdnodedeployments/status,verbs=get
-//+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdnodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdnodes,verbs=get;list;watch;update;patch;delete
 //+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdnodes/status,verbs=get
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -128,7 +132,7 @@ func (r *Reconciler) doReconcile(
 		}
 	} else {
 		if finalizer.HasFinalizer(obj) {
-			if newStatus, err := r.finalizeExternalResources(ctx, obj, status); err != nil {
+			if newStatus, err := r.finalizeExternalResources(ctx, obj, spec, status); err != nil {
 				return newStatus, err
 			} else {
 				status = newStatus
@@ -156,6 +160,7 @@ func (r *Reconciler) doReconcile(
 func (r *Reconciler) finalizeExternalResources(
 	ctx context.Context,
 	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdSpec,
 	status *kubernetesimalv1alpha1.EtcdStatus,
 ) (*kubernetesimalv1alpha1.EtcdStatus, error) {
 	var span trace.Span
@@ -166,7 +171,11 @@ func (r *Reconciler) finalizeExternalResources(
 		return status, err
 	}
 
-	if newStatus, err := finalizeCACertificateSecret(ctx, r.Client, obj, status); err != nil {
+	if err := finalizeCertificates(ctx, r.Client, obj, spec); err != nil {
+		return status, err
+	}
+
+	if newStatus, err := finalizeCACertificateSecret(ctx, r.Client, obj, spec, status); err != nil {
 		return newStatus, err
 	} else {
 		status = newStatus
@@ -211,6 +220,7 @@ func (r *Reconciler) reconcileExternalResources(
 		spec,
 		status,
 	); err != nil {
+		status.WithCertificatesReady(false, err.Error()).DeepCopyInto(status)
 		return status, fmt.Errorf("unable to prepare a CA certificate: %w", err)
 	} else {
 		status.CAPrivateKeyRef = privateKeyRef
@@ -225,6 +235,7 @@ func (r *Reconciler) reconcileExternalResources(
 		spec,
 		status,
 	); err != nil {
+		status.WithCertificatesReady(false, err.Error()).DeepCopyInto(status)
 		return status, fmt.Errorf("unable to prepare a client certificate: %w", err)
 	} else {
 		status.ClientPrivateKeyRef = privateKeyRef
@@ -239,11 +250,15 @@ func (r *Reconciler) reconcileExternalResources(
 		spec,
 		status,
 	); err != nil {
+		status.WithCertificatesReady(false, err.Error()).DeepCopyInto(status)
 		return status, fmt.Errorf("unable to prepare a certificate for peer communication: %w", err)
 	} else {
 		status.PeerPrivateKeyRef = privateKeyRef
 		status.PeerCertificateRef = certificateRef
 	}
+	if !status.AreCertificatesReady() {
+		status.WithCertificatesReady(true, "").DeepCopyInto(status)
+	}
 
 	if sshPrivateKeyRef, sshPublicKeyRef, err := reconcileSSHKeyPair(
 		ctx,
@@ -275,6 +290,31 @@ func (r *Reconciler) reconcileExternalResources(
 		return nil, fmt.Errorf("unable to prepare EtcdNodeDeployment: %w", err)
 	} else {
 		status.ReadyReplicas = deployment.Status.ReadyReplicas
+		status = syncUpgradeStatus(ctx, spec, status, deployment)
+	}
+
+	if newStatus, err := reconcileCertificateRotation(ctx, r.Client, r.Scheme, obj, spec, status); err != nil {
+		return newStatus, fmt.Errorf("unable to rotate certificates: %w", err)
+	} else {
+		status = newStatus
+	}
+
+	if spec.Bootstrap != nil && spec.Bootstrap.FromSnapshot != nil && !status.IsReadyOnce() {
+		if restore, err := getRestoreStatus(ctx, r.Client, obj); err != nil {
+			return status, fmt.Errorf("unable to get a status of restoring from a snapshot: %w", err)
+		} else if restore != nil {
+			status.Restore = restore
+		}
+	}
+
+	if newStatus, err := reconcileRecovery(ctx, r.Client, r.Recorder, obj, spec, status); err != nil {
+		return newStatus, fmt.Errorf("unable to recover an etcd cluster: %w", err)
+	} else {
+		status = newStatus
+	}
+
+	if err := reconcileRemediation(ctx, r.Client, obj, spec, status); err != nil {
+		return status, fmt.Errorf("unable to remediate failed etcd members: %w", err)
 	}
 	return status, nil
 }
@@ -0,0 +1,501 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	"github.com/kkohtaka/kubernetesimal/controller/etcdclient"
+	k8s_service "github.com/kkohtaka/kubernetesimal/k8s/service"
+	"github.com/kkohtaka/kubernetesimal/observability/tracing"
+)
+
+const (
+	recoveryRetryDelay = 5 * time.Second
+
+	eventReasonRecoveryStarted   = "RecoveryStarted"
+	eventReasonForcedNewCluster  = "ForcedNewCluster"
+	eventReasonReplacedMembers   = "ReplacedMembers"
+	eventReasonRecoveryCompleted = "RecoveryCompleted"
+	eventReasonRecoveryFailed    = "RecoveryFailed"
+
+	recoveryWithdrawnMessage = "the recovery request was withdrawn"
+)
+
+// reconcileRecovery recovers an etcd cluster which lost its quorum when it's requested with the recover annotation.
+// The etcd member of the healthiest surviving EtcdNode, or an EtcdNode restored from a snapshot, forms a new etcd
+// cluster with --force-new-cluster. The other EtcdNodes are replaced, and the etcd cluster is rebuilt to the desired
+// number of replicas. Removing the annotation withdraws a recovery in progress.
+func reconcileRecovery(
+	ctx context.Context,
+	c client.Client,
+	recorder record.EventRecorder,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdSpec,
+	status *kubernetesimalv1alpha1.EtcdStatus,
+) (*kubernetesimalv1alpha1.EtcdStatus, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "reconcileRecovery")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	request := obj.GetAnnotations()[kubernetesimalv1alpha1.EtcdRecoverAnnotation]
+	if request == "" {
+		return withdrawRecovery(ctx, c, recorder, obj, status)
+	}
+
+	newStatus := status.DeepCopy()
+	if newStatus.Recovery == nil || newStatus.Recovery.ObservedRequest != request {
+		recovery, err := startRecovery(ctx, c, obj, newStatus, request)
+		if err != nil {
+			return status, err
+		}
+		newStatus.Recovery = recovery
+		if recovery.Phase == kubernetesimalv1alpha1.EtcdRecoveryPhaseFailed {
+			logger.Info("Recovering an etcd cluster failed.", "reason", recovery.Message)
+			recorder.Event(obj, corev1.EventTypeWarning, eventReasonRecoveryFailed, recovery.Message)
+			return newStatus.WithRecovering(false, recovery.Message), nil
+		}
+		logger.Info("Recovering an etcd cluster was started.", "node", recovery.SourceNode, "request", request)
+		recorder.Eventf(
+			obj,
+			corev1.EventTypeNormal,
+			eventReasonRecoveryStarted,
+			"Started recovering the etcd cluster from an EtcdNode %s",
+			recovery.SourceNode,
+		)
+	}
+	recovery := newStatus.Recovery
+
+	if recovery.Phase == kubernetesimalv1alpha1.EtcdRecoveryPhaseForcingNewCluster {
+		newStatus = newStatus.WithRecovering(true, "waiting for an etcd member to form a new etcd cluster")
+		recovery = newStatus.Recovery
+		if forced, err := forceNewCluster(ctx, c, obj, recovery); err != nil {
+			return newStatus, err
+		} else if !forced {
+			return newStatus, errors.NewRequeueError("waiting for an etcd member to form a new etcd cluster").
+				WithDelay(recoveryRetryDelay)
+		}
+		recovery.Phase = kubernetesimalv1alpha1.EtcdRecoveryPhaseReplacingMembers
+		logger.Info("An etcd member formed a new etcd cluster.", "node", recovery.SourceNode)
+		recorder.Eventf(
+			obj,
+			corev1.EventTypeNormal,
+			eventReasonForcedNewCluster,
+			"An etcd member of an EtcdNode %s formed a new etcd cluster",
+			recovery.SourceNode,
+		)
+	}
+
+	if recovery.Phase == kubernetesimalv1alpha1.EtcdRecoveryPhaseReplacingMembers {
+		newStatus = newStatus.WithRecovering(true, "replacing etcd members which don't belong to the new etcd cluster")
+		recovery = newStatus.Recovery
+		replaced, err := replaceEtcdNodes(ctx, c, obj, newStatus)
+		if err != nil {
+			return newStatus, err
+		}
+		recovery.Phase = kubernetesimalv1alpha1.EtcdRecoveryPhaseRebuilding
+		logger.Info("Etcd members were marked to be replaced.", "nodes", replaced)
+		recorder.Eventf(
+			obj,
+			corev1.EventTypeNormal,
+			eventReasonReplacedMembers,
+			"Replaced %d etcd members which didn't belong to the new etcd cluster",
+			len(replaced),
+		)
+	}
+
+	if recovery.Phase == kubernetesimalv1alpha1.EtcdRecoveryPhaseRebuilding {
+		var replicas int32 = 1
+		if spec.Replicas != nil {
+			replicas = *spec.Replicas
+		}
+		if !newStatus.IsReady() || newStatus.ReadyReplicas < replicas {
+			message := fmt.Sprintf("rebuilding the etcd cluster (%d/%d ready)", newStatus.ReadyReplicas, replicas)
+			return newStatus.WithRecovering(true, message), errors.NewRequeueError("waiting for the etcd cluster rebuilt").
+				WithDelay(recoveryRetryDelay)
+		}
+		now := metav1.Now()
+		recovery.Phase = kubernetesimalv1alpha1.EtcdRecoveryPhaseCompleted
+		recovery.CompletionTime = &now
+		recovery.Message = ""
+		logger.Info("Recovering an etcd cluster was completed.", "request", request)
+		recorder.Event(obj, corev1.EventTypeNormal, eventReasonRecoveryCompleted, "Recovered the etcd cluster")
+		newStatus = newStatus.WithRecovering(false, "")
+	}
+	return newStatus, nil
+}
+
+// withdrawRecovery fails a recovery in progress whose request was withdrawn so that it doesn't block remediating etcd
+// members. The source EtcdNode isn't requested to form a new etcd cluster anymore unless it has done so.
+func withdrawRecovery(
+	ctx context.Context,
+	c client.Client,
+	recorder record.EventRecorder,
+	obj client.Object,
+	status *kubernetesimalv1alpha1.EtcdStatus,
+) (*kubernetesimalv1alpha1.EtcdStatus, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "withdrawRecovery")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	recovery := status.Recovery
+	if recovery == nil ||
+		recovery.Phase == kubernetesimalv1alpha1.EtcdRecoveryPhaseCompleted ||
+		recovery.Phase == kubernetesimalv1alpha1.EtcdRecoveryPhaseFailed {
+		if status.IsRecovering() {
+			return status.WithRecovering(false, ""), nil
+		}
+		return status, nil
+	}
+
+	if recovery.Phase == kubernetesimalv1alpha1.EtcdRecoveryPhaseForcingNewCluster {
+		if err := cancelForceNewCluster(ctx, c, obj, recovery); err != nil {
+			return status, err
+		}
+	}
+
+	newStatus := status.DeepCopy()
+	now := metav1.Now()
+	newStatus.Recovery.Phase = kubernetesimalv1alpha1.EtcdRecoveryPhaseFailed
+	newStatus.Recovery.CompletionTime = &now
+	newStatus.Recovery.Message = recoveryWithdrawnMessage
+	logger.Info("Recovering an etcd cluster was withdrawn.", "request", recovery.ObservedRequest)
+	recorder.Event(obj, corev1.EventTypeWarning, eventReasonRecoveryFailed, recoveryWithdrawnMessage)
+	return newStatus.WithRecovering(false, recoveryWithdrawnMessage), nil
+}
+
+// startRecovery selects an EtcdNode which a new etcd cluster is formed from, and returns a new status of a recovery.
+func startRecovery(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	status *kubernetesimalv1alpha1.EtcdStatus,
+	request string,
+) (*kubernetesimalv1alpha1.EtcdRecoveryStatus, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "startRecovery")
+	defer span.End()
+
+	now := metav1.Now()
+	recovery := &kubernetesimalv1alpha1.EtcdRecoveryStatus{
+		ObservedRequest: request,
+		StartTime:       &now,
+	}
+
+	nodes, err := getComponentEtcdNodes(ctx, c, obj)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list component EtcdNodes: %w", err)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	var source *kubernetesimalv1alpha1.EtcdNode
+	if backupName := obj.GetAnnotations()[kubernetesimalv1alpha1.EtcdRecoverFromBackupAnnotation]; backupName != "" {
+		// Any provisioned EtcdNode can be restored from a snapshot regardless of the data it has.
+		for _, node := range nodes {
+			if node.DeletionTimestamp.IsZero() && node.Status.IsProvisioned() {
+				source = node
+				break
+			}
+		}
+		recovery.SnapshotSource = &kubernetesimalv1alpha1.EtcdSnapshotSource{
+			EtcdBackupRef: &corev1.LocalObjectReference{
+				Name: backupName,
+			},
+		}
+	} else {
+		source = selectRecoverySource(nodes, getMemberRaftIndices(ctx, c, obj, status, nodes))
+	}
+
+	if source == nil {
+		recovery.Phase = kubernetesimalv1alpha1.EtcdRecoveryPhaseFailed
+		recovery.CompletionTime = &now
+		recovery.Message = "no surviving etcd member was found to recover the etcd cluster from"
+		return recovery, nil
+	}
+	recovery.Phase = kubernetesimalv1alpha1.EtcdRecoveryPhaseForcingNewCluster
+	recovery.SourceNode = source.Name
+	return recovery, nil
+}
+
+// isRecoverySourceCandidate returns whether an etcd member of an EtcdNode can form a new etcd cluster with its data.
+// Learners aren't candidates since they might not have the latest data.
+func isRecoverySourceCandidate(node *kubernetesimalv1alpha1.EtcdNode) bool {
+	return node.DeletionTimestamp.IsZero() && node.Status.IsProvisioned() && !node.Status.Learner
+}
+
+// getMemberRaftIndices returns raft indices of etcd members of EtcdNodes which can be recovery sources, keyed by names
+// of the EtcdNodes. Unreachable etcd members are omitted.
+func getMemberRaftIndices(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	status *kubernetesimalv1alpha1.EtcdStatus,
+	nodes []*kubernetesimalv1alpha1.EtcdNode,
+) map[string]uint64 {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "getMemberRaftIndices")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	raftIndices := map[string]uint64{}
+	for _, node := range nodes {
+		if !isRecoverySourceCandidate(node) {
+			continue
+		}
+		resp, err := getEtcdNodeMemberStatus(ctx, c, obj, status, node)
+		if err != nil {
+			logger.Info("An etcd member is unreachable to recover an etcd cluster from.", "node", node.Name, "reason", err)
+			continue
+		}
+		raftIndices[node.Name] = resp.RaftIndex
+	}
+	return raftIndices
+}
+
+// selectRecoverySource returns an EtcdNode whose etcd member survives and has the latest data, or nil if no etcd
+// member survives. An etcd member survives if its raft index is known. The first EtcdNode wins a tie.
+func selectRecoverySource(
+	nodes []*kubernetesimalv1alpha1.EtcdNode,
+	raftIndices map[string]uint64,
+) *kubernetesimalv1alpha1.EtcdNode {
+	var (
+		source    *kubernetesimalv1alpha1.EtcdNode
+		raftIndex uint64
+	)
+	for _, node := range nodes {
+		if !isRecoverySourceCandidate(node) {
+			continue
+		}
+		index, ok := raftIndices[node.Name]
+		if !ok {
+			continue
+		}
+		if source == nil || index > raftIndex {
+			source = node
+			raftIndex = index
+		}
+	}
+	return source
+}
+
+// getEtcdNodeMemberStatus returns a status of an etcd member of an EtcdNode via a peer Service of the EtcdNode.
+func getEtcdNodeMemberStatus(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	status *kubernetesimalv1alpha1.EtcdStatus,
+	node *kubernetesimalv1alpha1.EtcdNode,
+) (*clientv3.StatusResponse, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "getEtcdNodeMemberStatus")
+	defer span.End()
+
+	if node.Status.PeerServiceRef == nil {
+		return nil, fmt.Errorf("a peer Service of an EtcdNode %s is not prepared yet", node.Name)
+	}
+	address, err := k8s_service.GetAddressFromServiceRef(
+		ctx,
+		c,
+		obj.GetNamespace(),
+		"etcd",
+		node.Status.PeerServiceRef,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get an etcd address from a peer Service: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("https://%s", address)
+	etcdClient, err := etcdclient.NewForEndpoint(
+		ctx,
+		c,
+		obj.GetNamespace(),
+		endpoint,
+		etcdclient.CredentialsFromEtcdStatus(status),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create an etcd client: %w", err)
+	}
+	defer etcdClient.Close()
+
+	statusCtx, statusCancel := context.WithTimeout(ctx, defaultRequestTimeout)
+	defer statusCancel()
+	resp, err := etcdClient.Status(statusCtx, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get a status of an etcd member: %w", err)
+	}
+	return resp, nil
+}
+
+// forceNewCluster requests the source EtcdNode of a recovery to form a new etcd cluster, and returns whether the
+// EtcdNode has formed it.
+func forceNewCluster(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	recovery *kubernetesimalv1alpha1.EtcdRecoveryStatus,
+) (bool, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "forceNewCluster")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	var node kubernetesimalv1alpha1.EtcdNode
+	if err := c.Get(
+		ctx,
+		types.NamespacedName{Namespace: obj.GetNamespace(), Name: recovery.SourceNode},
+		&node,
+	); err != nil {
+		return false, fmt.Errorf("unable to get an EtcdNode %s: %w", recovery.SourceNode, err)
+	}
+	if node.Status.ObservedForceNewCluster == recovery.ObservedRequest {
+		return true, nil
+	}
+
+	var backupName string
+	if recovery.SnapshotSource != nil && recovery.SnapshotSource.EtcdBackupRef != nil {
+		backupName = recovery.SnapshotSource.EtcdBackupRef.Name
+	}
+	if node.Annotations[kubernetesimalv1alpha1.EtcdNodeForceNewClusterAnnotation] == recovery.ObservedRequest &&
+		node.Annotations[kubernetesimalv1alpha1.EtcdNodeForceNewClusterBackupAnnotation] == backupName {
+		return false, nil
+	}
+
+	patch := client.MergeFrom(node.DeepCopy())
+	if node.Annotations == nil {
+		node.Annotations = map[string]string{}
+	}
+	node.Annotations[kubernetesimalv1alpha1.EtcdNodeForceNewClusterAnnotation] = recovery.ObservedRequest
+	if backupName != "" {
+		node.Annotations[kubernetesimalv1alpha1.EtcdNodeForceNewClusterBackupAnnotation] = backupName
+	} else {
+		delete(node.Annotations, kubernetesimalv1alpha1.EtcdNodeForceNewClusterBackupAnnotation)
+	}
+	if err := c.Patch(ctx, &node, patch); err != nil {
+		return false, fmt.Errorf("unable to request an EtcdNode %s to form a new etcd cluster: %w", node.Name, err)
+	}
+	logger.Info("An etcd member was requested to form a new etcd cluster.", "node", node.Name, "backup", backupName)
+	return false, nil
+}
+
+// cancelForceNewCluster withdraws a request to form a new etcd cluster from the source EtcdNode of a recovery unless
+// the EtcdNode has formed it.
+func cancelForceNewCluster(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	recovery *kubernetesimalv1alpha1.EtcdRecoveryStatus,
+) error {
+	var node kubernetesimalv1alpha1.EtcdNode
+	if err := c.Get(
+		ctx,
+		types.NamespacedName{Namespace: obj.GetNamespace(), Name: recovery.SourceNode},
+		&node,
+	); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to get an EtcdNode %s: %w", recovery.SourceNode, err)
+	}
+	if node.Status.ObservedForceNewCluster == recovery.ObservedRequest ||
+		node.Annotations[kubernetesimalv1alpha1.EtcdNodeForceNewClusterAnnotation] != recovery.ObservedRequest {
+		return nil
+	}
+
+	patch := client.MergeFrom(node.DeepCopy())
+	delete(node.Annotations, kubernetesimalv1alpha1.EtcdNodeForceNewClusterAnnotation)
+	delete(node.Annotations, kubernetesimalv1alpha1.EtcdNodeForceNewClusterBackupAnnotation)
+	if err := c.Patch(ctx, &node, patch); err != nil {
+		return fmt.Errorf("unable to withdraw a request to form a new etcd cluster from an EtcdNode %s: %w", node.Name, err)
+	}
+	return nil
+}
+
+// replaceEtcdNodes marks EtcdNodes other than the source EtcdNode of a recovery to be remediated, and deletes them so
+// that they are recreated as members of the new etcd cluster. It returns names of the replaced EtcdNodes.
+func replaceEtcdNodes(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	status *kubernetesimalv1alpha1.EtcdStatus,
+) ([]string, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "replaceEtcdNodes")
+	defer span.End()
+
+	nodes, err := getComponentEtcdNodes(ctx, c, obj)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list component EtcdNodes: %w", err)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	var replaced []string
+	for _, node := range nodes {
+		if node.Name == status.Recovery.SourceNode || !node.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if _, ok := node.Annotations[kubernetesimalv1alpha1.EtcdNodeRemediationAnnotation]; !ok {
+			patch := client.MergeFrom(node.DeepCopy())
+			if node.Annotations == nil {
+				node.Annotations = map[string]string{}
+			}
+			node.Annotations[kubernetesimalv1alpha1.EtcdNodeRemediationAnnotation] = fmt.Sprintf(
+				"replaced by the recovery %q of the etcd cluster",
+				status.Recovery.ObservedRequest,
+			)
+			if err := c.Patch(ctx, node, patch); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, fmt.Errorf("unable to mark an EtcdNode %s to be replaced: %w", node.Name, err)
+			}
+		}
+		if err := remediateEtcdNode(ctx, c, node); err != nil {
+			return nil, err
+		}
+		replaced = append(replaced, node.Name)
+	}
+	return replaced, nil
+}
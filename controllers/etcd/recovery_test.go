@@ -0,0 +1,241 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+)
+
+func TestSelectRecoverySource(t *testing.T) {
+	provisioned := (&kubernetesimalv1alpha1.EtcdNodeStatus{}).WithProvisioned(true, "")
+	learner := provisioned.DeepCopy()
+	learner.Learner = true
+	newNode := func(name string, status *kubernetesimalv1alpha1.EtcdNodeStatus) *kubernetesimalv1alpha1.EtcdNode {
+		return &kubernetesimalv1alpha1.EtcdNode{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     *status,
+		}
+	}
+
+	for _, tc := range []struct {
+		name        string
+		nodes       []*kubernetesimalv1alpha1.EtcdNode
+		raftIndices map[string]uint64
+		expected    string
+	}{
+		{
+			name: "a member with the latest data",
+			nodes: []*kubernetesimalv1alpha1.EtcdNode{
+				newNode("a", provisioned),
+				newNode("b", provisioned),
+				newNode("c", provisioned),
+			},
+			raftIndices: map[string]uint64{"a": 10, "b": 30, "c": 20},
+			expected:    "b",
+		},
+		{
+			name: "the first member in a tie",
+			nodes: []*kubernetesimalv1alpha1.EtcdNode{
+				newNode("a", provisioned),
+				newNode("b", provisioned),
+			},
+			raftIndices: map[string]uint64{"a": 10, "b": 10},
+			expected:    "a",
+		},
+		{
+			name: "an unreachable member",
+			nodes: []*kubernetesimalv1alpha1.EtcdNode{
+				newNode("a", provisioned),
+				newNode("b", provisioned),
+			},
+			raftIndices: map[string]uint64{"a": 10},
+			expected:    "a",
+		},
+		{
+			name: "a learner",
+			nodes: []*kubernetesimalv1alpha1.EtcdNode{
+				newNode("a", provisioned),
+				newNode("b", learner),
+			},
+			raftIndices: map[string]uint64{"a": 10, "b": 30},
+			expected:    "a",
+		},
+		{
+			name: "an unprovisioned member",
+			nodes: []*kubernetesimalv1alpha1.EtcdNode{
+				newNode("a", &kubernetesimalv1alpha1.EtcdNodeStatus{}),
+			},
+			raftIndices: map[string]uint64{"a": 10},
+		},
+		{
+			name: "no surviving member",
+			nodes: []*kubernetesimalv1alpha1.EtcdNode{
+				newNode("a", provisioned),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			source := selectRecoverySource(tc.nodes, tc.raftIndices)
+			if tc.expected == "" {
+				assert.Nil(t, source)
+				return
+			}
+			require.NotNil(t, source)
+			assert.Equal(t, tc.expected, source.Name)
+		})
+	}
+}
+
+func TestReconcileRecovery(t *testing.T) {
+	const request = "1"
+	newEtcd := func(request string) *kubernetesimalv1alpha1.Etcd {
+		e := &kubernetesimalv1alpha1.Etcd{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "etcd"},
+			Spec:       kubernetesimalv1alpha1.EtcdSpec{Replicas: pointer.Int32(3)},
+		}
+		if request != "" {
+			e.Annotations = map[string]string{kubernetesimalv1alpha1.EtcdRecoverAnnotation: request}
+		}
+		return e
+	}
+	newStatus := func(phase kubernetesimalv1alpha1.EtcdRecoveryPhase) *kubernetesimalv1alpha1.EtcdStatus {
+		status := (&kubernetesimalv1alpha1.EtcdStatus{}).WithRecovering(true, "")
+		status.Recovery = &kubernetesimalv1alpha1.EtcdRecoveryStatus{
+			ObservedRequest: request,
+			Phase:           phase,
+			SourceNode:      "source",
+		}
+		return status
+	}
+
+	t.Run("a withdrawn request fails a recovery in progress", func(t *testing.T) {
+		e := newEtcd("")
+		status, err := reconcileRecovery(
+			context.Background(),
+			newFakeClient(t),
+			record.NewFakeRecorder(10),
+			e,
+			&e.Spec,
+			newStatus(kubernetesimalv1alpha1.EtcdRecoveryPhaseRebuilding),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, kubernetesimalv1alpha1.EtcdRecoveryPhaseFailed, status.Recovery.Phase)
+		assert.Equal(t, recoveryWithdrawnMessage, status.Recovery.Message)
+		assert.NotNil(t, status.Recovery.CompletionTime)
+		assert.False(t, status.IsRecovering())
+	})
+
+	t.Run("a withdrawn request cancels forming a new etcd cluster", func(t *testing.T) {
+		e := newEtcd("")
+		source := newComponentEtcdNode(e, "source", kubernetesimalv1alpha1.EtcdNodeStatus{})
+		source.Annotations = map[string]string{
+			kubernetesimalv1alpha1.EtcdNodeForceNewClusterAnnotation: request,
+		}
+		c := newFakeClient(t, source)
+		status, err := reconcileRecovery(
+			context.Background(),
+			c,
+			record.NewFakeRecorder(10),
+			e,
+			&e.Spec,
+			newStatus(kubernetesimalv1alpha1.EtcdRecoveryPhaseForcingNewCluster),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, kubernetesimalv1alpha1.EtcdRecoveryPhaseFailed, status.Recovery.Phase)
+
+		var node kubernetesimalv1alpha1.EtcdNode
+		require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "source"}, &node))
+		assert.NotContains(t, node.Annotations, kubernetesimalv1alpha1.EtcdNodeForceNewClusterAnnotation)
+	})
+
+	t.Run("a request fails without a surviving etcd member", func(t *testing.T) {
+		e := newEtcd(request)
+		status, err := reconcileRecovery(
+			context.Background(),
+			newFakeClient(t),
+			record.NewFakeRecorder(10),
+			e,
+			&e.Spec,
+			&kubernetesimalv1alpha1.EtcdStatus{},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, kubernetesimalv1alpha1.EtcdRecoveryPhaseFailed, status.Recovery.Phase)
+		assert.False(t, status.IsRecovering())
+	})
+
+	t.Run("etcd members are replaced after a new etcd cluster is formed", func(t *testing.T) {
+		e := newEtcd(request)
+		source := newComponentEtcdNode(e, "source", kubernetesimalv1alpha1.EtcdNodeStatus{
+			ObservedForceNewCluster: request,
+		})
+		other := newComponentEtcdNode(e, "other", kubernetesimalv1alpha1.EtcdNodeStatus{})
+		c := newFakeClient(t, source, other)
+		status, err := reconcileRecovery(
+			context.Background(),
+			c,
+			record.NewFakeRecorder(10),
+			e,
+			&e.Spec,
+			newStatus(kubernetesimalv1alpha1.EtcdRecoveryPhaseForcingNewCluster),
+		)
+		assert.True(t, errors.ShouldRequeue(err), err)
+		assert.Equal(t, kubernetesimalv1alpha1.EtcdRecoveryPhaseRebuilding, status.Recovery.Phase)
+		assert.True(t, status.IsRecovering())
+
+		var node kubernetesimalv1alpha1.EtcdNode
+		err = c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "other"}, &node)
+		assert.True(t, apierrors.IsNotFound(err), err)
+		require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "source"}, &node))
+	})
+
+	t.Run("a recovery is completed once the etcd cluster is rebuilt", func(t *testing.T) {
+		e := newEtcd(request)
+		status := newStatus(kubernetesimalv1alpha1.EtcdRecoveryPhaseRebuilding).WithReady(true, "")
+		status.ReadyReplicas = 3
+		status, err := reconcileRecovery(
+			context.Background(),
+			newFakeClient(t),
+			record.NewFakeRecorder(10),
+			e,
+			&e.Spec,
+			status,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, kubernetesimalv1alpha1.EtcdRecoveryPhaseCompleted, status.Recovery.Phase)
+		assert.False(t, status.IsRecovering())
+	})
+}
@@ -0,0 +1,218 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	"github.com/kkohtaka/kubernetesimal/controller/quorum"
+	"github.com/kkohtaka/kubernetesimal/observability/tracing"
+)
+
+const (
+	defaultRemediationFailureThreshold = 3
+	defaultRemediationTimeout          = 10 * time.Minute
+
+	remediationRetryDelay = 10 * time.Second
+)
+
+// reconcileRemediation replaces an etcd member which failed permanently. A failed member is marked with the
+// remediation annotation and its EtcdNode is deleted so that the EtcdNodeSet recreates it. The finalizer of the EtcdNode
+// removes the member from the etcd cluster. Only one etcd member is remediated at once, and only if the etcd cluster
+// keeps its quorum.
+func reconcileRemediation(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdSpec,
+	status *kubernetesimalv1alpha1.EtcdStatus,
+) error {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "reconcileRemediation")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	if spec.Remediation == nil {
+		return nil
+	}
+	if !status.IsReadyOnce() {
+		logger.V(4).Info("Skip remediating etcd members since the etcd cluster has never been ready.")
+		return nil
+	}
+	if status.IsRecovering() {
+		logger.V(4).Info("Skip remediating etcd members since the etcd cluster is being recovered.")
+		return nil
+	}
+
+	nodes, err := getComponentEtcdNodes(ctx, c, obj)
+	if err != nil {
+		return fmt.Errorf("unable to list component EtcdNodes: %w", err)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+
+	// A remediation which has been started is continued before another one.
+	for _, node := range nodes {
+		if _, ok := node.Annotations[kubernetesimalv1alpha1.EtcdNodeRemediationAnnotation]; ok &&
+			node.DeletionTimestamp.IsZero() {
+			return remediateEtcdNode(ctx, c, node)
+		}
+	}
+
+	var (
+		voters, healthyVoters int
+		failed                *kubernetesimalv1alpha1.EtcdNode
+		reason                string
+		nextCheck             time.Duration
+	)
+	now := time.Now()
+	for _, node := range nodes {
+		if !node.DeletionTimestamp.IsZero() {
+			logger.V(4).Info("Skip remediating etcd members since an EtcdNode is being deleted.", "node", node.Name)
+			return nil
+		}
+		if !node.Status.Learner {
+			voters++
+		}
+		if node.Status.IsReady() {
+			if !node.Status.Learner {
+				healthyVoters++
+			}
+			continue
+		}
+
+		nodeReason, wait := getRemediationReason(spec.Remediation, node, now)
+		if nodeReason == "" {
+			// An etcd member which is joining or recovering blocks remediating another one.
+			if nextCheck == 0 || wait < nextCheck {
+				nextCheck = wait
+			}
+			continue
+		}
+		if failed == nil {
+			failed = node
+			reason = nodeReason
+		}
+	}
+	if nextCheck > 0 {
+		return errors.NewRequeueError("waiting for etcd members become ready or fail").WithDelay(nextCheck)
+	}
+	if failed == nil {
+		return nil
+	}
+
+	// A learner can be removed without affecting the quorum.
+	if !failed.Status.Learner && !quorum.CanRemoveMember(voters, healthyVoters, false) {
+		logger.Info(
+			"Skip remediating an etcd member since the etcd cluster would lose its quorum.",
+			"node", failed.Name,
+			"voters", voters,
+			"healthyVoters", healthyVoters,
+		)
+		return errors.NewRequeueError("waiting for etcd members become healthy to remediate a failed member").
+			WithDelay(remediationRetryDelay)
+	}
+
+	patch := client.MergeFrom(failed.DeepCopy())
+	if failed.Annotations == nil {
+		failed.Annotations = map[string]string{}
+	}
+	failed.Annotations[kubernetesimalv1alpha1.EtcdNodeRemediationAnnotation] = reason
+	if err := c.Patch(ctx, failed, patch); err != nil {
+		return fmt.Errorf("unable to mark an EtcdNode %s to be remediated: %w", failed.Name, err)
+	}
+	logger.Info("An etcd member was marked to be remediated.", "node", failed.Name, "reason", reason)
+	return remediateEtcdNode(ctx, c, failed)
+}
+
+// getRemediationReason returns why an EtcdNode which isn't ready is considered failed, or how long it takes until it
+// can be considered failed.
+func getRemediationReason(
+	remediation *kubernetesimalv1alpha1.EtcdRemediationSpec,
+	node *kubernetesimalv1alpha1.EtcdNode,
+	now time.Time,
+) (string, time.Duration) {
+	failureThreshold := int32(defaultRemediationFailureThreshold)
+	if remediation.FailureThreshold != nil {
+		failureThreshold = *remediation.FailureThreshold
+	}
+	timeout := defaultRemediationTimeout
+	if remediation.Timeout != nil {
+		timeout = remediation.Timeout.Duration
+	}
+
+	if !node.Status.IsReadyOnce() {
+		if elapsed := now.Sub(node.CreationTimestamp.Time); elapsed < timeout {
+			return "", timeout - elapsed
+		}
+		return fmt.Sprintf("not ready for %s since it was created", timeout), 0
+	}
+
+	since := node.CreationTimestamp.Time
+	if t := node.Status.ReadySinceTime(); t != nil {
+		since = t.Time
+	}
+	if elapsed := now.Sub(since); elapsed < timeout {
+		return "", timeout - elapsed
+	}
+	if node.Status.ProbeFailures < failureThreshold {
+		return "", remediationRetryDelay
+	}
+	return fmt.Sprintf("not ready for %s with %d consecutive probe failures", timeout, node.Status.ProbeFailures), 0
+}
+
+// remediateEtcdNode deletes an EtcdNode marked to be remediated so that the EtcdNodeSet recreates it. The finalizer of
+// the EtcdNode removes its etcd member from the etcd cluster with the etcd API since the virtual machine of the
+// EtcdNode can't be trusted to leave the etcd cluster by itself.
+func remediateEtcdNode(
+	ctx context.Context,
+	c client.Client,
+	node *kubernetesimalv1alpha1.EtcdNode,
+) error {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "remediateEtcdNode")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	if err := c.Delete(ctx, node); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("unable to delete an EtcdNode %s: %w", node.Name, err)
+	}
+	logger.Info("A failed EtcdNode was deleted to be recreated.", "node", node.Name)
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+)
+
+func TestGetRemediationReason(t *testing.T) {
+	now := time.Now()
+	newNode := func(
+		createdAgo time.Duration,
+		readyOnce bool,
+		notReadyFor time.Duration,
+		failures int32,
+	) *kubernetesimalv1alpha1.EtcdNode {
+		node := &kubernetesimalv1alpha1.EtcdNode{
+			ObjectMeta: metav1.ObjectMeta{
+				CreationTimestamp: metav1.NewTime(now.Add(-createdAgo)),
+			},
+			Status: kubernetesimalv1alpha1.EtcdNodeStatus{
+				ProbeFailures: failures,
+			},
+		}
+		if readyOnce {
+			transitionTime := metav1.NewTime(now.Add(-notReadyFor))
+			probeTime := metav1.NewTime(now.Add(-notReadyFor - time.Minute))
+			node.Status.Conditions = []kubernetesimalv1alpha1.EtcdNodeCondition{
+				{
+					Type:               kubernetesimalv1alpha1.EtcdNodeConditionTypeReady,
+					Status:             corev1.ConditionFalse,
+					LastProbeTime:      &probeTime,
+					LastTransitionTime: &transitionTime,
+				},
+			}
+		}
+		return node
+	}
+	remediation := &kubernetesimalv1alpha1.EtcdRemediationSpec{}
+
+	for _, tc := range []struct {
+		name       string
+		node       *kubernetesimalv1alpha1.EtcdNode
+		failed     bool
+		expectWait time.Duration
+	}{
+		{
+			name:       "a node joining within the timeout",
+			node:       newNode(time.Minute, false, 0, 0),
+			expectWait: defaultRemediationTimeout - time.Minute,
+		},
+		{
+			name:   "a node never ready after the timeout",
+			node:   newNode(defaultRemediationTimeout+time.Minute, false, 0, 0),
+			failed: true,
+		},
+		{
+			name:       "a node not ready within the timeout",
+			node:       newNode(time.Hour, true, time.Minute, 10),
+			expectWait: defaultRemediationTimeout - time.Minute,
+		},
+		{
+			name:       "a node not ready after the timeout with few probe failures",
+			node:       newNode(time.Hour, true, defaultRemediationTimeout+time.Minute, 1),
+			expectWait: remediationRetryDelay,
+		},
+		{
+			name:   "a node not ready after the timeout with enough probe failures",
+			node:   newNode(time.Hour, true, defaultRemediationTimeout+time.Minute, defaultRemediationFailureThreshold),
+			failed: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reason, wait := getRemediationReason(remediation, tc.node, now)
+			assert.Equal(t, tc.failed, reason != "")
+			assert.Equal(t, tc.expectWait, wait)
+		})
+	}
+}
@@ -52,12 +52,30 @@ const (
 	sshKeyPairKeyPublicKey = "ssh-publickey"
 )
 
+// withSSHKeyAlgorithm makes an SSH key-pair generated with the specified algorithm.
+func withSSHKeyAlgorithm(algorithm kubernetesimalv1alpha1.EtcdKeyAlgorithm) func(*ssh.KeyPairOption) {
+	return func(o *ssh.KeyPairOption) {
+		switch algorithm {
+		case kubernetesimalv1alpha1.EtcdKeyAlgorithmRSA2048:
+			o.Type, o.BitSize = ssh.KeyTypeRSA, 2048
+		case kubernetesimalv1alpha1.EtcdKeyAlgorithmRSA3072:
+			o.Type, o.BitSize = ssh.KeyTypeRSA, 3072
+		case kubernetesimalv1alpha1.EtcdKeyAlgorithmECDSAP256:
+			o.Type, o.BitSize = ssh.KeyTypeECDSA, 256
+		case kubernetesimalv1alpha1.EtcdKeyAlgorithmECDSAP384:
+			o.Type, o.BitSize = ssh.KeyTypeECDSA, 384
+		case kubernetesimalv1alpha1.EtcdKeyAlgorithmEd25519:
+			o.Type = ssh.KeyTypeEd25519
+		}
+	}
+}
+
 func reconcileSSHKeyPair(
 	ctx context.Context,
 	c client.Client,
 	scheme *runtime.Scheme,
 	obj client.Object,
-	_ *kubernetesimalv1alpha1.EtcdSpec,
+	spec *kubernetesimalv1alpha1.EtcdSpec,
 	status *kubernetesimalv1alpha1.EtcdStatus,
 ) (*corev1.SecretKeySelector, *corev1.SecretKeySelector, error) {
 	var span trace.Span
@@ -94,7 +112,7 @@ func reconcileSSHKeyPair(
 		}
 	}
 
-	privateKey, publicKey, err := ssh.GenerateKeyPair()
+	privateKey, publicKey, err := ssh.GenerateKeyPair(withSSHKeyAlgorithm(spec.SSHKeyAlgorithm))
 	if err != nil {
 		return nil, nil, fmt.Errorf("unable to create an SSH key-pair: %w", err)
 	}
@@ -0,0 +1,164 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blang/semver/v4"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/observability/tracing"
+)
+
+// getRolloutVersion returns a version of etcd that EtcdNodes should run at the current step of an upgrade, and whether
+// the rollout of EtcdNodes should be paused.
+// An upgrade isn't started until the etcd cluster becomes healthy. The EtcdNodeDeployment replaces the next member only
+// after the previously replaced one becomes available, and the rollout is additionally paused while the etcd cluster
+// is unhealthy as a whole.
+func getRolloutVersion(
+	ctx context.Context,
+	c client.Client,
+	e client.Object,
+	spec *kubernetesimalv1alpha1.EtcdSpec,
+	status *kubernetesimalv1alpha1.EtcdStatus,
+) (string, bool, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "getRolloutVersion")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	var deployment kubernetesimalv1alpha1.EtcdNodeDeployment
+	deployment.Name = newEtcdNodeDeploymentName(e)
+	deployment.Namespace = e.GetNamespace()
+	if err := c.Get(ctx, client.ObjectKeyFromObject(&deployment), &deployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return *spec.Version, false, nil
+		}
+		return "", false, fmt.Errorf("unable to get EtcdNodeDeployment: %w", err)
+	}
+
+	healthy := status.IsReady() && status.AreMembersHealthy()
+	if current := deployment.Spec.Template.Spec.Version; current != *spec.Version {
+		if !healthy {
+			logger.Info(
+				"Upgrading etcd is postponed until the etcd cluster becomes healthy.",
+				"current", current,
+				"target", *spec.Version,
+			)
+			return current, false, nil
+		}
+		return *spec.Version, false, nil
+	}
+	return *spec.Version, status.IsUpgrading() && !healthy, nil
+}
+
+// syncUpgradeStatus updates the Upgrading condition and the version of the etcd cluster according to a progress of a
+// rollout of EtcdNodes.
+func syncUpgradeStatus(
+	ctx context.Context,
+	spec *kubernetesimalv1alpha1.EtcdSpec,
+	status *kubernetesimalv1alpha1.EtcdStatus,
+	deployment *kubernetesimalv1alpha1.EtcdNodeDeployment,
+) *kubernetesimalv1alpha1.EtcdStatus {
+	logger := log.FromContext(ctx)
+
+	if !status.IsReadyOnce() {
+		return status
+	}
+
+	if status.Version == "" {
+		status.Version = deployment.Spec.Template.Spec.Version
+	}
+	if status.Version == *spec.Version {
+		if status.IsUpgrading() {
+			return status.WithUpgrading(false, fmt.Sprintf("etcd was upgraded to %s", status.Version))
+		}
+		return status
+	}
+
+	if isUpgradeCompleted(spec, status, deployment) {
+		logger.Info(
+			"Upgrading etcd was completed.",
+			"from", status.Version,
+			"to", *spec.Version,
+		)
+		message := fmt.Sprintf("etcd was upgraded from %s to %s", status.Version, *spec.Version)
+		status.Version = *spec.Version
+		return status.WithUpgrading(false, message)
+	}
+	return status.WithUpgrading(
+		true,
+		fmt.Sprintf(
+			"etcd is being upgraded from %s to %s (%d/%d members were updated)",
+			status.Version,
+			*spec.Version,
+			deployment.Status.UpdatedReplicas,
+			*deployment.Spec.Replicas,
+		),
+	)
+}
+
+// isUpgradeCompleted returns true if all EtcdNodes were replaced with ones running the desired version, the etcd
+// cluster is healthy, and the cluster version of the etcd cluster converges to the desired version.
+func isUpgradeCompleted(
+	spec *kubernetesimalv1alpha1.EtcdSpec,
+	status *kubernetesimalv1alpha1.EtcdStatus,
+	deployment *kubernetesimalv1alpha1.EtcdNodeDeployment,
+) bool {
+	if deployment.Spec.Template.Spec.Version != *spec.Version {
+		return false
+	}
+	replicas := *deployment.Spec.Replicas
+	if deployment.Status.ObservedGeneration < deployment.Generation ||
+		deployment.Status.UpdatedReplicas != replicas ||
+		deployment.Status.Replicas != replicas ||
+		deployment.Status.AvailableReplicas != replicas {
+		return false
+	}
+	if !status.IsReady() || !status.AreMembersHealthy() {
+		return false
+	}
+	return isClusterVersionConverged(status.ClusterVersion, *spec.Version)
+}
+
+// isClusterVersionConverged returns true if a cluster version reported by an etcd cluster matches a desired version.
+// etcd reports a cluster version only with a major and minor version (e.g. 3.5.0).
+func isClusterVersionConverged(clusterVersion, version string) bool {
+	current, err := semver.Parse(clusterVersion)
+	if err != nil {
+		return false
+	}
+	desired, err := semver.Parse(version)
+	if err != nil {
+		return false
+	}
+	return current.Major == desired.Major && current.Minor == desired.Minor
+}
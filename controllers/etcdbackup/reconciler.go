@@ -0,0 +1,253 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdbackup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	"github.com/kkohtaka/kubernetesimal/controller/finalizer"
+	"github.com/kkohtaka/kubernetesimal/observability/tracing"
+)
+
+// Reconciler reconciles a EtcdBackup object
+type Reconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	Tracer trace.Tracer
+
+	// BackupVolumeDir is a directory where PersistentVolumeClaims for backups are mounted with their names.
+	BackupVolumeDir string
+}
+
+//+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdbackups,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdbackups/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdbackups/finalizers,verbs=update
+//+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcds,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcds/status,verbs=get
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("etcdbackup", req.NamespacedName)
+	ctx = log.IntoContext(ctx, logger)
+
+	ctx = tracing.NewContext(ctx, r.Tracer)
+	tracer := tracing.FromContext(ctx)
+
+	var span trace.Span
+	ctx, span = tracer.Start(ctx, "Reconcile")
+	defer span.End()
+
+	var backup kubernetesimalv1alpha1.EtcdBackup
+	if err := r.Get(ctx, req.NamespacedName, &backup); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	status, err := r.doReconcile(ctx, &backup, backup.Spec.DeepCopy(), backup.Status.DeepCopy())
+	if statusUpdateErr := r.updateStatus(ctx, &backup, status); statusUpdateErr != nil {
+		logger.Error(statusUpdateErr, "unable to update a status of an object")
+	}
+	if err != nil {
+		if errors.ShouldRequeue(err) {
+			delay := errors.GetDelay(err)
+			logger.V(2).Info(
+				"Reconciliation will be requeued.",
+				"reason", err,
+				"delay", delay,
+			)
+			return ctrl.Result{
+				RequeueAfter: delay,
+			}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *Reconciler) doReconcile(
+	ctx context.Context,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdBackupSpec,
+	status *kubernetesimalv1alpha1.EtcdBackupStatus,
+) (*kubernetesimalv1alpha1.EtcdBackupStatus, error) {
+	ctx, span := tracing.FromContext(ctx).Start(ctx, "doReconcile")
+	defer span.End()
+
+	if obj.GetDeletionTimestamp().IsZero() {
+		if !finalizer.HasFinalizer(obj) {
+			if err := finalizer.SetFinalizer(ctx, r.Client, obj); err != nil {
+				if apierrors.IsNotFound(err) {
+					return status, nil
+				}
+				return status, fmt.Errorf("unable to set finalizer: %w", err)
+			}
+			return status, errors.NewRequeueError("finalizer was set").WithDelay(time.Second)
+		}
+	} else {
+		if finalizer.HasFinalizer(obj) {
+			if newStatus, err := r.finalizeExternalResources(ctx, obj, spec, status); err != nil {
+				return newStatus, err
+			} else {
+				status = newStatus
+			}
+
+			if err := finalizer.UnsetFinalizer(ctx, r.Client, obj); err != nil {
+				if apierrors.IsNotFound(err) {
+					return status, nil
+				}
+				return status, fmt.Errorf("unable to unset finalizer: %w", err)
+			}
+			return status, errors.NewRequeueError("finalizer was unset").WithDelay(time.Second)
+		}
+		return status, nil
+	}
+
+	if newStatus, err := r.reconcileExternalResources(ctx, obj, spec, status); err != nil {
+		return newStatus, err
+	} else {
+		status = newStatus
+	}
+	return status, nil
+}
+
+func (r *Reconciler) finalizeExternalResources(
+	ctx context.Context,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdBackupSpec,
+	status *kubernetesimalv1alpha1.EtcdBackupStatus,
+) (*kubernetesimalv1alpha1.EtcdBackupStatus, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "finalizeExternalResources")
+	defer span.End()
+
+	if err := finalizeSnapshot(ctx, r.Client, obj, spec, status, r.BackupVolumeDir); err != nil {
+		return status, err
+	}
+	return status, nil
+}
+
+func (r *Reconciler) reconcileExternalResources(
+	ctx context.Context,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdBackupSpec,
+	status *kubernetesimalv1alpha1.EtcdBackupStatus,
+) (*kubernetesimalv1alpha1.EtcdBackupStatus, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "reconcileExternalResources")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	switch status.Phase {
+	case kubernetesimalv1alpha1.EtcdBackupPhaseCompleted, kubernetesimalv1alpha1.EtcdBackupPhaseFailed:
+		return status, nil
+	case kubernetesimalv1alpha1.EtcdBackupPhaseRunning:
+	default:
+		now := metav1.Now()
+		status.Phase = kubernetesimalv1alpha1.EtcdBackupPhaseRunning
+		status.StartTime = &now
+		return status, errors.NewRequeueError("backup was started").WithDelay(time.Second)
+	}
+
+	result, err := reconcileSnapshot(ctx, r.Client, obj, spec, status, r.BackupVolumeDir)
+	if err != nil {
+		if errors.ShouldRequeue(err) {
+			return status, err
+		}
+		logger.Error(err, "Taking a snapshot was failed.")
+		now := metav1.Now()
+		status.Phase = kubernetesimalv1alpha1.EtcdBackupPhaseFailed
+		status.CompletionTime = &now
+		status.WithCompleted(false, err.Error()).DeepCopyInto(status)
+		return status, nil
+	}
+
+	now := metav1.Now()
+	status.Phase = kubernetesimalv1alpha1.EtcdBackupPhaseCompleted
+	status.CompletionTime = &now
+	status.Location = result.location
+	status.Size = result.size
+	status.Revision = result.revision
+	status.Hash = result.hash
+	status.WithCompleted(true, "").DeepCopyInto(status)
+	return status, nil
+}
+
+func (r *Reconciler) updateStatus(
+	ctx context.Context,
+	backup *kubernetesimalv1alpha1.EtcdBackup,
+	status *kubernetesimalv1alpha1.EtcdBackupStatus,
+) error {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "updateStatus")
+	defer span.End()
+
+	logger := log.FromContext(ctx)
+
+	if !apiequality.Semantic.DeepEqual(status, &backup.Status) {
+		patch := client.MergeFrom(backup.DeepCopy())
+		status.DeepCopyInto(&backup.Status)
+		if err := r.Client.Status().Patch(ctx, backup, patch); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("status couldn't be applied a patch: %w", err)
+		}
+		logger.V(2).Info("Status was updated.")
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("etcdbackup-reconciler").
+		For(
+			&kubernetesimalv1alpha1.EtcdBackup{},
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Complete(r)
+}
@@ -0,0 +1,192 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdbackup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	"github.com/kkohtaka/kubernetesimal/controller/etcdclient"
+	k8s_etcdbackup "github.com/kkohtaka/kubernetesimal/k8s/etcdbackup"
+	k8s_service "github.com/kkohtaka/kubernetesimal/k8s/service"
+	"github.com/kkohtaka/kubernetesimal/observability/tracing"
+)
+
+const (
+	defaultRequestTimeout = 5 * time.Second
+
+	defaultSnapshotTimeout = 10 * time.Minute
+)
+
+type snapshotResult struct {
+	location string
+	size     int64
+	revision int64
+	hash     string
+}
+
+func reconcileSnapshot(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdBackupSpec,
+	_ *kubernetesimalv1alpha1.EtcdBackupStatus,
+	backupVolumeDir string,
+) (*snapshotResult, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "reconcileSnapshot")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	var e kubernetesimalv1alpha1.Etcd
+	if err := c.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: spec.EtcdRef.Name}, &e); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errors.NewRequeueError("waiting for an Etcd being created").Wrap(err).WithDelay(5 * time.Second)
+		}
+		return nil, fmt.Errorf("unable to get Etcd %s: %w", spec.EtcdRef.Name, err)
+	}
+	if !e.Status.IsReady() || e.Status.ServiceRef == nil {
+		return nil, errors.NewRequeueError("waiting for an Etcd becoming ready").WithDelay(5 * time.Second)
+	}
+
+	sink, err := k8s_etcdbackup.GetSink(ctx, c, obj.GetNamespace(), &spec.Sink, backupVolumeDir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to prepare a sink: %w", err)
+	}
+
+	address, err := k8s_service.GetAddressFromServiceRef(ctx, c, e.Namespace, "etcd", e.Status.ServiceRef)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get an etcd address from an etcd Service: %w", err)
+	}
+	endpoint := fmt.Sprintf("https://%s", address)
+	etcdClient, err := etcdclient.NewForEndpoint(
+		ctx,
+		c,
+		e.Namespace,
+		endpoint,
+		etcdclient.CredentialsFromEtcdStatus(&e.Status),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer etcdClient.Close()
+
+	// The revision is taken before the snapshot, so it is only a lower bound of the revision in the snapshot.
+	statusCtx, statusCancel := context.WithTimeout(ctx, defaultRequestTimeout)
+	resp, err := etcdClient.Status(statusCtx, endpoint)
+	statusCancel()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get a status of an etcd: %w", err)
+	}
+
+	f, err := os.CreateTemp("", "etcd-snapshot-*.db")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create a temporary file: %w", err)
+	}
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+
+	snapshotCtx, snapshotCancel := context.WithTimeout(ctx, defaultSnapshotTimeout)
+	defer snapshotCancel()
+	rc, err := etcdClient.Snapshot(snapshotCtx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to start taking a snapshot: %w", err)
+	}
+	defer rc.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(f, hash), rc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to receive a snapshot: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("unable to rewind a snapshot: %w", err)
+	}
+
+	location, err := sink.Save(ctx, k8s_etcdbackup.NewSnapshotKey(obj, spec), f, size)
+	if err != nil {
+		return nil, fmt.Errorf("unable to save a snapshot: %w", err)
+	}
+	logger.Info(
+		"A snapshot was saved.",
+		"location", location,
+		"size", size,
+		"revision", resp.Header.Revision,
+	)
+
+	return &snapshotResult{
+		location: location,
+		size:     size,
+		revision: resp.Header.Revision,
+		hash:     hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+func finalizeSnapshot(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdBackupSpec,
+	status *kubernetesimalv1alpha1.EtcdBackupStatus,
+	backupVolumeDir string,
+) error {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "finalizeSnapshot")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	if status.Location == "" {
+		return nil
+	}
+
+	sink, err := k8s_etcdbackup.GetSink(ctx, c, obj.GetNamespace(), &spec.Sink, backupVolumeDir)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("Skip deleting a snapshot since its sink doesn't exist.", "location", status.Location)
+			return nil
+		}
+		return fmt.Errorf("unable to prepare a sink: %w", err)
+	}
+	if err := sink.Delete(ctx, k8s_etcdbackup.NewSnapshotKey(obj, spec)); err != nil {
+		return fmt.Errorf("unable to delete a snapshot: %w", err)
+	}
+	logger.Info("A snapshot was deleted.", "location", status.Location)
+	return nil
+}
@@ -0,0 +1,250 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdbackupschedule
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	k8s_etcdbackup "github.com/kkohtaka/kubernetesimal/k8s/etcdbackup"
+	k8s_object "github.com/kkohtaka/kubernetesimal/k8s/object"
+	"github.com/kkohtaka/kubernetesimal/observability/tracing"
+)
+
+const (
+	// maxMissedSchedules is the maximum number of missed schedules to walk through before skipping ahead to the
+	// schedules close to now.
+	maxMissedSchedules = 100
+)
+
+func newEtcdBackupName(obj client.Object, scheduledTime time.Time) string {
+	return fmt.Sprintf("%s-%d", obj.GetName(), scheduledTime.Unix())
+}
+
+func reconcileEtcdBackups(
+	ctx context.Context,
+	c client.Client,
+	scheme *runtime.Scheme,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdBackupScheduleSpec,
+	status *kubernetesimalv1alpha1.EtcdBackupScheduleStatus,
+) (*kubernetesimalv1alpha1.EtcdBackupScheduleStatus, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "reconcileEtcdBackups")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	sched, err := cron.ParseStandard(spec.Schedule)
+	if err != nil {
+		return status, fmt.Errorf("unable to parse a schedule %q: %w", spec.Schedule, err)
+	}
+
+	backups, err := getControlledEtcdBackups(ctx, c, obj)
+	if err != nil {
+		return status, err
+	}
+
+	for _, backup := range backups {
+		if backup.Status.Phase != kubernetesimalv1alpha1.EtcdBackupPhaseCompleted ||
+			backup.Status.CompletionTime == nil {
+			continue
+		}
+		if status.LastSuccessfulTime == nil || status.LastSuccessfulTime.Before(backup.Status.CompletionTime) {
+			status.LastSuccessfulTime = backup.Status.CompletionTime.DeepCopy()
+		}
+	}
+
+	if err := cleanupEtcdBackups(ctx, c, &spec.Retention, backups); err != nil {
+		return status, err
+	}
+
+	if spec.Suspend {
+		logger.V(4).Info("Scheduling EtcdBackups is suspended.")
+		return status, nil
+	}
+
+	now := time.Now()
+	earliestTime := obj.GetCreationTimestamp().Time
+	if status.LastScheduleTime != nil {
+		earliestTime = status.LastScheduleTime.Time
+	}
+
+	if scheduledTime := getMostRecentScheduleTime(sched, earliestTime, now); !scheduledTime.IsZero() {
+		name := newEtcdBackupName(obj, scheduledTime)
+		if _, err := k8s_etcdbackup.Create(
+			ctx,
+			c,
+			k8s_object.WithName(name),
+			k8s_object.WithNamespace(obj.GetNamespace()),
+			k8s_object.WithOwner(obj, scheme),
+			k8s_object.WithLabels(spec.Template.GetLabels()),
+			k8s_object.WithAnnotations(spec.Template.GetAnnotations()),
+			k8s_etcdbackup.WithEtcdRef(spec.Template.Spec.EtcdRef),
+			k8s_etcdbackup.WithSink(&spec.Template.Spec.Sink),
+		); err != nil && !apierrors.IsAlreadyExists(err) {
+			return status, err
+		}
+		status.LastScheduleTime = &metav1.Time{Time: scheduledTime}
+		status.LastBackupRef = &corev1.LocalObjectReference{Name: name}
+	}
+
+	delay := sched.Next(now).Sub(now)
+	return status, errors.NewRequeueError("waiting for the next schedule").WithDelay(delay)
+}
+
+// getMostRecentScheduleTime returns the most recent scheduled time after the earliest time until now, or the zero
+// time if no schedule has come. Only the last schedule is returned even if some schedules were missed.
+func getMostRecentScheduleTime(sched cron.Schedule, earliestTime, now time.Time) time.Time {
+	var scheduledTime time.Time
+	for t, i := sched.Next(earliestTime), 0; !t.After(now); t, i = sched.Next(t), i+1 {
+		scheduledTime = t
+		if i >= maxMissedSchedules {
+			t, i = skipMissedSchedules(sched, t, now), 0
+		}
+	}
+	return scheduledTime
+}
+
+// skipMissedSchedules returns a time after t from which the next schedule is still until now, so that the caller can
+// skip the schedules in between. t is returned if no such time is found.
+func skipMissedSchedules(sched cron.Schedule, t, now time.Time) time.Time {
+	for window := sched.Next(t).Sub(t); window > 0; window *= 2 {
+		from := now.Add(-window)
+		if !from.After(t) {
+			break
+		}
+		if !sched.Next(from).After(now) {
+			return from
+		}
+	}
+	return t
+}
+
+func getControlledEtcdBackups(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+) ([]*kubernetesimalv1alpha1.EtcdBackup, error) {
+	var backupList kubernetesimalv1alpha1.EtcdBackupList
+	if err := c.List(ctx, &backupList, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil, fmt.Errorf("unable to list EtcdBackups: %w", err)
+	}
+
+	var backups []*kubernetesimalv1alpha1.EtcdBackup
+	for i := range backupList.Items {
+		backup := &backupList.Items[i]
+		if ref := metav1.GetControllerOf(backup); ref == nil {
+			continue
+		} else if ref.UID != obj.GetUID() {
+			continue
+		}
+		if backup.DeletionTimestamp != nil {
+			continue
+		}
+		backups = append(backups, backup)
+	}
+	return backups, nil
+}
+
+// cleanupEtcdBackups deletes EtcdBackups that are not retained by a retention policy.
+// The latest completed EtcdBackup is always retained.
+func cleanupEtcdBackups(
+	ctx context.Context,
+	c client.Client,
+	retention *kubernetesimalv1alpha1.EtcdBackupRetentionPolicy,
+	backups []*kubernetesimalv1alpha1.EtcdBackup,
+) error {
+	logger := log.FromContext(ctx)
+
+	for _, backup := range getEtcdBackupsToCleanup(retention, backups, time.Now()) {
+		logger.V(4).Info("Trying to cleanup EtcdBackup", "etcdBackup", backup.Name)
+		if err := c.Delete(ctx, backup); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete an old EtcdBackup: %w", err)
+		}
+	}
+	return nil
+}
+
+// getEtcdBackupsToCleanup returns EtcdBackups that are not retained by a retention policy.
+func getEtcdBackupsToCleanup(
+	retention *kubernetesimalv1alpha1.EtcdBackupRetentionPolicy,
+	backups []*kubernetesimalv1alpha1.EtcdBackup,
+	now time.Time,
+) []*kubernetesimalv1alpha1.EtcdBackup {
+	var completed, failed []*kubernetesimalv1alpha1.EtcdBackup
+	for _, backup := range backups {
+		switch backup.Status.Phase {
+		case kubernetesimalv1alpha1.EtcdBackupPhaseCompleted:
+			completed = append(completed, backup)
+		case kubernetesimalv1alpha1.EtcdBackupPhaseFailed:
+			failed = append(failed, backup)
+		}
+	}
+	sort.Sort(etcdBackupsByCompletionTime(completed))
+	sort.Sort(etcdBackupsByCompletionTime(failed))
+
+	var toDelete []*kubernetesimalv1alpha1.EtcdBackup
+	for i, backup := range completed {
+		if i == 0 {
+			continue
+		}
+		if retention.MaxBackups != nil && i >= int(*retention.MaxBackups) {
+			toDelete = append(toDelete, backup)
+		} else if retention.MaxAge != nil && backup.Status.CompletionTime != nil &&
+			now.Sub(backup.Status.CompletionTime.Time) > retention.MaxAge.Duration {
+			toDelete = append(toDelete, backup)
+		}
+	}
+	if retention.FailedBackupsHistoryLimit != nil && len(failed) > int(*retention.FailedBackupsHistoryLimit) {
+		toDelete = append(toDelete, failed[*retention.FailedBackupsHistoryLimit:]...)
+	}
+	return toDelete
+}
+
+// etcdBackupsByCompletionTime sorts a list of EtcdBackups by their completion time in descending order.
+type etcdBackupsByCompletionTime []*kubernetesimalv1alpha1.EtcdBackup
+
+func (o etcdBackupsByCompletionTime) Len() int      { return len(o) }
+func (o etcdBackupsByCompletionTime) Swap(i, j int) { o[i], o[j] = o[j], o[i] }
+func (o etcdBackupsByCompletionTime) Less(i, j int) bool {
+	t1, t2 := o[i].Status.CompletionTime, o[j].Status.CompletionTime
+	if t1 == nil || t2 == nil || t1.Equal(t2) {
+		return o[i].CreationTimestamp.After(o[j].CreationTimestamp.Time)
+	}
+	return t1.After(t2.Time)
+}
@@ -0,0 +1,159 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdbackupschedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+)
+
+func TestGetMostRecentScheduleTime(t *testing.T) {
+	sched, err := cron.ParseStandard("0 * * * *")
+	assert.NoError(t, err)
+	base := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		name         string
+		earliestTime time.Time
+		now          time.Time
+		expected     time.Time
+	}{
+		{
+			name:         "no schedule has come",
+			earliestTime: base,
+			now:          base.Add(30 * time.Minute),
+		},
+		{
+			name:         "a schedule has just come",
+			earliestTime: base,
+			now:          base.Add(time.Hour),
+			expected:     base.Add(time.Hour),
+		},
+		{
+			name:         "some schedules were missed",
+			earliestTime: base,
+			now:          base.Add(3*time.Hour + 30*time.Minute),
+			expected:     base.Add(3 * time.Hour),
+		},
+		{
+			name:         "too many schedules were missed",
+			earliestTime: base,
+			now:          base.Add(1000*time.Hour + 30*time.Minute),
+			expected:     base.Add(1000 * time.Hour),
+		},
+		{
+			name:         "the last schedule was already taken",
+			earliestTime: base.Add(3 * time.Hour),
+			now:          base.Add(3*time.Hour + 30*time.Minute),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, getMostRecentScheduleTime(sched, tc.earliestTime, tc.now))
+		})
+	}
+}
+
+func TestGetEtcdBackupsToCleanup(t *testing.T) {
+	now := time.Now()
+	newBackup := func(
+		name string,
+		phase kubernetesimalv1alpha1.EtcdBackupPhase,
+		completedAgo time.Duration,
+	) *kubernetesimalv1alpha1.EtcdBackup {
+		completionTime := metav1.NewTime(now.Add(-completedAgo))
+		return &kubernetesimalv1alpha1.EtcdBackup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(now.Add(-completedAgo - time.Minute)),
+			},
+			Status: kubernetesimalv1alpha1.EtcdBackupStatus{
+				Phase:          phase,
+				CompletionTime: &completionTime,
+			},
+		}
+	}
+	backups := []*kubernetesimalv1alpha1.EtcdBackup{
+		newBackup("completed-3h", kubernetesimalv1alpha1.EtcdBackupPhaseCompleted, 3*time.Hour),
+		newBackup("completed-1h", kubernetesimalv1alpha1.EtcdBackupPhaseCompleted, time.Hour),
+		newBackup("failed-2h", kubernetesimalv1alpha1.EtcdBackupPhaseFailed, 2*time.Hour),
+		newBackup("completed-2h", kubernetesimalv1alpha1.EtcdBackupPhaseCompleted, 2*time.Hour),
+		newBackup("running", kubernetesimalv1alpha1.EtcdBackupPhaseRunning, 0),
+		newBackup("failed-1h", kubernetesimalv1alpha1.EtcdBackupPhaseFailed, time.Hour),
+	}
+
+	for _, tc := range []struct {
+		name      string
+		retention kubernetesimalv1alpha1.EtcdBackupRetentionPolicy
+		expected  []string
+	}{
+		{
+			name:      "no limits",
+			retention: kubernetesimalv1alpha1.EtcdBackupRetentionPolicy{},
+		},
+		{
+			name: "the oldest completed backups exceed the max backups",
+			retention: kubernetesimalv1alpha1.EtcdBackupRetentionPolicy{
+				MaxBackups: pointer.Int32(2),
+			},
+			expected: []string{"completed-3h"},
+		},
+		{
+			name: "completed backups are older than the max age",
+			retention: kubernetesimalv1alpha1.EtcdBackupRetentionPolicy{
+				MaxAge: &metav1.Duration{Duration: 90 * time.Minute},
+			},
+			expected: []string{"completed-2h", "completed-3h"},
+		},
+		{
+			name: "the latest completed backup is retained regardless of its age",
+			retention: kubernetesimalv1alpha1.EtcdBackupRetentionPolicy{
+				MaxAge: &metav1.Duration{Duration: time.Minute},
+			},
+			expected: []string{"completed-2h", "completed-3h"},
+		},
+		{
+			name: "the oldest failed backups exceed the history limit",
+			retention: kubernetesimalv1alpha1.EtcdBackupRetentionPolicy{
+				FailedBackupsHistoryLimit: pointer.Int32(1),
+			},
+			expected: []string{"failed-2h"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var names []string
+			for _, backup := range getEtcdBackupsToCleanup(&tc.retention, backups, now) {
+				names = append(names, backup.Name)
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
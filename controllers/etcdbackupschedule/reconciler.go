@@ -0,0 +1,184 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdbackupschedule
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/trace"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	"github.com/kkohtaka/kubernetesimal/observability/tracing"
+)
+
+// Reconciler reconciles a EtcdBackupSchedule object
+type Reconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	Tracer trace.Tracer
+}
+
+//+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdbackupschedules,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdbackupschedules/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdbackupschedules/finalizers,verbs=update
+//+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdbackups,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdbackups/status,verbs=get
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("etcdbackupschedule", req.NamespacedName)
+	ctx = log.IntoContext(ctx, logger)
+
+	ctx = tracing.NewContext(ctx, r.Tracer)
+	tracer := tracing.FromContext(ctx)
+
+	var span trace.Span
+	ctx, span = tracer.Start(ctx, "Reconcile")
+	defer span.End()
+
+	var schedule kubernetesimalv1alpha1.EtcdBackupSchedule
+	if err := r.Get(ctx, req.NamespacedName, &schedule); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	status, err := r.doReconcile(ctx, &schedule, schedule.Spec.DeepCopy(), schedule.Status.DeepCopy())
+	if statusUpdateErr := r.updateStatus(ctx, &schedule, status); statusUpdateErr != nil {
+		logger.Error(statusUpdateErr, "unable to update a status of an object")
+	}
+	if err != nil {
+		if errors.ShouldRequeue(err) {
+			delay := errors.GetDelay(err)
+			logger.V(2).Info(
+				"Reconciliation will be requeued.",
+				"reason", err,
+				"delay", delay,
+			)
+			return ctrl.Result{
+				RequeueAfter: delay,
+			}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *Reconciler) doReconcile(
+	ctx context.Context,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdBackupScheduleSpec,
+	status *kubernetesimalv1alpha1.EtcdBackupScheduleStatus,
+) (*kubernetesimalv1alpha1.EtcdBackupScheduleStatus, error) {
+	ctx, span := tracing.FromContext(ctx).Start(ctx, "doReconcile")
+	defer span.End()
+
+	if !obj.GetDeletionTimestamp().IsZero() {
+		return status, nil
+	}
+
+	status.ObservedGeneration = obj.GetGeneration()
+
+	if newStatus, err := r.reconcileExternalResources(ctx, obj, spec, status); err != nil {
+		return newStatus, err
+	} else {
+		status = newStatus
+	}
+	return status, nil
+}
+
+func (r *Reconciler) reconcileExternalResources(
+	ctx context.Context,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdBackupScheduleSpec,
+	status *kubernetesimalv1alpha1.EtcdBackupScheduleStatus,
+) (*kubernetesimalv1alpha1.EtcdBackupScheduleStatus, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "reconcileExternalResources")
+	defer span.End()
+
+	if newStatus, err := reconcileEtcdBackups(ctx, r.Client, r.Scheme, obj, spec, status); err != nil {
+		if errors.ShouldRequeue(err) {
+			return newStatus, err
+		}
+		return status, fmt.Errorf("unable to reconcile EtcdBackups: %w", err)
+	} else {
+		status = newStatus
+	}
+	return status, nil
+}
+
+func (r *Reconciler) updateStatus(
+	ctx context.Context,
+	schedule *kubernetesimalv1alpha1.EtcdBackupSchedule,
+	status *kubernetesimalv1alpha1.EtcdBackupScheduleStatus,
+) error {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "updateStatus")
+	defer span.End()
+
+	logger := log.FromContext(ctx)
+
+	if !apiequality.Semantic.DeepEqual(status, &schedule.Status) {
+		patch := client.MergeFrom(schedule.DeepCopy())
+		status.DeepCopyInto(&schedule.Status)
+		if err := r.Client.Status().Patch(ctx, schedule, patch); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("status couldn't be applied a patch: %w", err)
+		}
+		logger.V(2).Info("Status was updated.")
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("etcdbackupschedule-reconciler").
+		For(
+			&kubernetesimalv1alpha1.EtcdBackupSchedule{},
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+		).
+		Owns(
+			&kubernetesimalv1alpha1.EtcdBackup{},
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Complete(r)
+}
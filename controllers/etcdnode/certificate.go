@@ -0,0 +1,439 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdnode
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"path"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	cryptossh "golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	k8s_secret "github.com/kkohtaka/kubernetesimal/k8s/secret"
+	"github.com/kkohtaka/kubernetesimal/observability/tracing"
+	"github.com/kkohtaka/kubernetesimal/pki"
+	"github.com/kkohtaka/kubernetesimal/ssh"
+)
+
+const (
+	etcdPKIDir = "/etc/etcd/pki"
+
+	serverCertificateName          = "server"
+	peerCertificateName            = "peer"
+	etcdctlClientCertificateName   = "etcdctl-etcd-client"
+	apiserverClientCertificateName = "apiserver-etcd-client"
+)
+
+// memberCertificateNames are names of certificates which etcdadm issues on an etcd member with the CA.
+var memberCertificateNames = []string{
+	serverCertificateName,
+	peerCertificateName,
+	etcdctlClientCertificateName,
+	apiserverClientCertificateName,
+}
+
+// reconcileMemberCertificates rotates certificates of an etcd member when it's requested with an annotation, and
+// records the expiry of them.
+func reconcileMemberCertificates(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+) (*kubernetesimalv1alpha1.EtcdNodeStatus, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "reconcileMemberCertificates")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	requested := obj.GetAnnotations()[kubernetesimalv1alpha1.EtcdNodeCertificateRotationAnnotation]
+	if status.Certificates != nil &&
+		status.Certificates.ServerNotAfter != nil &&
+		status.Certificates.ObservedRotation == requested {
+		return status, nil
+	}
+
+	client, closer, err := startSSHConnectionToEtcdMember(ctx, c, obj, spec, status)
+	if err != nil {
+		return status, err
+	}
+	defer closer()
+
+	certificates := &kubernetesimalv1alpha1.EtcdNodeCertificatesStatus{}
+	if status.Certificates != nil {
+		certificates = status.Certificates.DeepCopy()
+	}
+
+	if requested != certificates.ObservedRotation {
+		if err := rotateMemberCertificates(ctx, c, client, obj, spec); err != nil {
+			return status, err
+		}
+		logger.Info("Certificates of an etcd member were rotated.", "rotation", requested)
+		certificates.ObservedRotation = requested
+	}
+
+	for _, name := range []string{serverCertificateName, peerCertificateName} {
+		cert, err := readMemberCertificate(ctx, client, name)
+		if err != nil {
+			return status, err
+		}
+		if cert == nil {
+			return status, fmt.Errorf("no %s certificate found on an etcd member", name)
+		}
+		notAfter := metav1.NewTime(cert.NotAfter)
+		switch name {
+		case serverCertificateName:
+			certificates.ServerNotAfter = &notAfter
+		case peerCertificateName:
+			certificates.PeerNotAfter = &notAfter
+		}
+	}
+	status.Certificates = certificates
+	return status, nil
+}
+
+// rotateMemberCertificates re-issues certificates of an etcd member with the CA, and places them over SSH.
+// etcd loads certificate files on every new TLS handshake, so new certificates take effect without restarting etcd.
+func rotateMemberCertificates(
+	ctx context.Context,
+	c client.Client,
+	sshClient *cryptossh.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+) error {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "rotateMemberCertificates")
+	defer span.End()
+
+	caCert, caPrivateKey, err := getCA(ctx, c, obj, spec)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range memberCertificateNames {
+		cert, err := readMemberCertificate(ctx, sshClient, name)
+		if err != nil {
+			return err
+		}
+		if cert == nil {
+			continue
+		}
+
+		certificate, privateKey, err := pki.RenewCertificateAndPrivateKey(cert, caCert, caPrivateKey)
+		if err != nil {
+			return fmt.Errorf("unable to renew a %s certificate: %w", name, err)
+		}
+		if err := placeMemberCertificate(ctx, sshClient, name, certificate, privateKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// issueMemberCertificates signs certificates of an etcd member with the CA and places them over SSH before etcdadm
+// runs, so that etcdadm uses them instead of signing ones by itself. It does nothing unless the controller is the
+// signer of certificates of the member.
+// The certificates are valid for addresses of the VirtualMachineInstance and the etcd Services, since etcd advertises
+// an address of the VirtualMachineInstance to peers.
+func issueMemberCertificates(
+	ctx context.Context,
+	c client.Client,
+	sshClient *cryptossh.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+) error {
+	if spec.MemberCertificateSigner != kubernetesimalv1alpha1.EtcdCertificateSignerController {
+		return nil
+	}
+
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "issueMemberCertificates")
+	defer span.End()
+
+	caCert, caPrivateKey, err := getCA(ctx, c, obj, spec)
+	if err != nil {
+		return err
+	}
+	caAlgorithm, err := pki.KeyAlgorithmOf(caCert.PublicKey)
+	if err != nil {
+		return fmt.Errorf("unable to determine a key algorithm of a CA: %w", err)
+	}
+
+	dnsNames, ipAddresses, err := getMemberAddresses(ctx, c, obj, spec, status)
+	if err != nil {
+		return err
+	}
+
+	// Peer certificates of etcd members inherit the common name of the peer certificate of the etcd cluster so that
+	// etcd members can authenticate each other by the common name.
+	peerCommonName, err := getPeerCommonName(ctx, c, obj, spec)
+	if err != nil {
+		return err
+	}
+	if peerCommonName == "" {
+		peerCommonName = newVirtualMachineInstanceName(obj)
+	}
+
+	for _, issue := range []struct {
+		name string
+		fn   func() ([]byte, []byte, error)
+	}{
+		{
+			name: serverCertificateName,
+			fn: func() ([]byte, []byte, error) {
+				return pki.CreateServerCertificateAndPrivateKey(
+					newVirtualMachineInstanceName(obj),
+					dnsNames,
+					ipAddresses,
+					caCert,
+					caPrivateKey,
+				)
+			},
+		},
+		{
+			name: peerCertificateName,
+			fn: func() ([]byte, []byte, error) {
+				return pki.CreatePeerCertificateAndPrivateKey(
+					peerCommonName,
+					dnsNames,
+					ipAddresses,
+					caCert,
+					caPrivateKey,
+				)
+			},
+		},
+		{
+			name: etcdctlClientCertificateName,
+			fn: func() ([]byte, []byte, error) {
+				return pki.CreateClientCertificateAndPrivateKey(
+					etcdctlClientCertificateName,
+					caAlgorithm,
+					caCert,
+					caPrivateKey,
+				)
+			},
+		},
+		{
+			name: apiserverClientCertificateName,
+			fn: func() ([]byte, []byte, error) {
+				return pki.CreateClientCertificateAndPrivateKey(
+					apiserverClientCertificateName,
+					caAlgorithm,
+					caCert,
+					caPrivateKey,
+				)
+			},
+		},
+	} {
+		certificate, privateKey, err := issue.fn()
+		if err != nil {
+			return fmt.Errorf("unable to create a %s certificate: %w", issue.name, err)
+		}
+		if err := placeMemberCertificate(ctx, sshClient, issue.name, certificate, privateKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getMemberAddresses returns DNS names and IP addresses which an etcd member is accessed with.
+func getMemberAddresses(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+) ([]string, []net.IP, error) {
+	dnsNames := []string{newVirtualMachineInstanceName(obj), "localhost"}
+	ipAddresses := []net.IP{net.IPv4(127, 0, 0, 1)}
+
+	var vmi kubevirtv1.VirtualMachineInstance
+	if err := c.Get(
+		ctx,
+		types.NamespacedName{
+			Namespace: obj.GetNamespace(),
+			Name:      status.VirtualMachineInstanceRef.Name,
+		},
+		&vmi,
+	); err != nil {
+		return nil, nil, fmt.Errorf("unable to get a VirtualMachineInstance: %w", err)
+	}
+	for _, iface := range vmi.Status.Interfaces {
+		for _, address := range append([]string{iface.IP}, iface.IPs...) {
+			if ip := net.ParseIP(address); ip != nil {
+				ipAddresses = append(ipAddresses, ip)
+			}
+		}
+	}
+	if len(ipAddresses) == 1 {
+		return nil, nil, errors.NewRequeueError("waiting for an IP address of a VirtualMachineInstance assigned").
+			WithDelay(5 * time.Second)
+	}
+
+	for _, name := range []string{spec.ServiceRef.Name, status.PeerServiceRef.Name} {
+		var service corev1.Service
+		if err := c.Get(
+			ctx,
+			types.NamespacedName{
+				Namespace: obj.GetNamespace(),
+				Name:      name,
+			},
+			&service,
+		); err != nil {
+			return nil, nil, fmt.Errorf("unable to get a service %s/%s: %w", obj.GetNamespace(), name, err)
+		}
+		dnsNames = append(
+			dnsNames,
+			service.Name,
+			fmt.Sprintf("%s.%s", service.Name, service.Namespace),
+			fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace),
+		)
+		if ip := net.ParseIP(service.Spec.ClusterIP); ip != nil {
+			ipAddresses = append(ipAddresses, ip)
+		}
+	}
+	return dnsNames, ipAddresses, nil
+}
+
+// placeMemberCertificate places a pair of certificate and private key with the specified name on an etcd member.
+func placeMemberCertificate(
+	ctx context.Context,
+	sshClient *cryptossh.Client,
+	name string,
+	certificate, privateKey []byte,
+) error {
+	certPath := path.Join(etcdPKIDir, name+".crt")
+	keyPath := path.Join(etcdPKIDir, name+".key")
+	if err := ssh.RunCommandWithInputOverSSHSession(
+		ctx,
+		sshClient,
+		fmt.Sprintf("sudo install -D -m 0600 /dev/stdin %s.new", keyPath),
+		bytes.NewReader(privateKey),
+	); err != nil {
+		return fmt.Errorf("unable to transfer a %s private key: %w", name, err)
+	}
+	if err := ssh.RunCommandWithInputOverSSHSession(
+		ctx,
+		sshClient,
+		fmt.Sprintf("sudo install -D -m 0644 /dev/stdin %s.new", certPath),
+		bytes.NewReader(certificate),
+	); err != nil {
+		return fmt.Errorf("unable to transfer a %s certificate: %w", name, err)
+	}
+	if err := ssh.RunCommandOverSSHSession(
+		ctx,
+		sshClient,
+		fmt.Sprintf("sudo mv %s.new %s && sudo mv %s.new %s", keyPath, keyPath, certPath, certPath),
+	); err != nil {
+		return fmt.Errorf("unable to place a %s certificate: %w", name, err)
+	}
+	return nil
+}
+
+// readMemberCertificate reads a certificate with the specified name from an etcd member. It returns nil if the
+// certificate doesn't exist.
+func readMemberCertificate(
+	ctx context.Context,
+	sshClient *cryptossh.Client,
+	name string,
+) (*x509.Certificate, error) {
+	certPath := path.Join(etcdPKIDir, name+".crt")
+	out, err := ssh.OutputCommandOverSSHSession(
+		ctx,
+		sshClient,
+		fmt.Sprintf("if sudo test -f %s; then sudo cat %s; fi", certPath, certPath),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read a %s certificate: %w", name, err)
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil, nil
+	}
+	cert, err := pki.ParseCertificate(out)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse a %s certificate: %w", name, err)
+	}
+	return cert, nil
+}
+
+// getPeerCommonName returns a common name of the peer certificate of the etcd cluster, or an empty string if the
+// peer certificate isn't specified.
+func getPeerCommonName(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+) (string, error) {
+	if spec.PeerCertificateRef.Name == "" {
+		return "", nil
+	}
+	peerCert, err := k8s_secret.GetCertificateFromSecretKeySelector(ctx, c, obj.GetNamespace(), &spec.PeerCertificateRef)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", errors.NewRequeueError("waiting for a peer certificate prepared").Wrap(err)
+		}
+		return "", fmt.Errorf("unable to load a peer certificate from a Secret: %w", err)
+	}
+	return peerCert.Subject.CommonName, nil
+}
+
+func getCA(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+) (*x509.Certificate, crypto.Signer, error) {
+	caCert, err := k8s_secret.GetCertificateFromSecretKeySelector(ctx, c, obj.GetNamespace(), &spec.CACertificateRef)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, errors.NewRequeueError("waiting for a CA certificate prepared").Wrap(err)
+		}
+		return nil, nil, fmt.Errorf("unable to load a CA certificate from a Secret: %w", err)
+	}
+	caPrivateKey, err := k8s_secret.GetPrivateKeyFromSecretKeySelector(ctx, c, obj.GetNamespace(), &spec.CAPrivateKeyRef)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, errors.NewRequeueError("waiting for a CA private key prepared").Wrap(err)
+		}
+		return nil, nil, fmt.Errorf("unable to load a CA private key from a Secret: %w", err)
+	}
+	return caCert, caPrivateKey, nil
+}
@@ -0,0 +1,220 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdnode
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	k8s_secret "github.com/kkohtaka/kubernetesimal/k8s/secret"
+	"github.com/kkohtaka/kubernetesimal/observability/tracing"
+)
+
+const (
+	cloudConfigHeader = "#cloud-config\n"
+
+	// cloudConfigDefaultUser is an entry of users which makes cloud-init create the default user of a distribution.
+	cloudConfigDefaultUser = "default"
+)
+
+// getExtraCloudConfig returns an extra cloud-config which the spec refers to. It returns nil if the spec doesn't
+// refer to any extra cloud-config.
+func getExtraCloudConfig(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+) ([]byte, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "getExtraCloudConfig")
+	defer span.End()
+
+	source := spec.ExtraCloudConfig
+	switch {
+	case source == nil:
+		return nil, nil
+	case source.ConfigMapKeyRef != nil:
+		selector := source.ConfigMapKeyRef
+		var configMap corev1.ConfigMap
+		key := types.NamespacedName{
+			Namespace: obj.GetNamespace(),
+			Name:      selector.Name,
+		}
+		if err := c.Get(ctx, key, &configMap); err != nil {
+			if apierrors.IsNotFound(err) {
+				if selector.Optional != nil && *selector.Optional {
+					return nil, nil
+				}
+				return nil, errors.NewRequeueError("waiting for a ConfigMap of an extra cloud-config prepared").Wrap(err)
+			}
+			return nil, fmt.Errorf("unable to get ConfigMap %s: %w", key, err)
+		}
+		value, ok := configMap.Data[selector.Key]
+		if !ok {
+			if selector.Optional != nil && *selector.Optional {
+				return nil, nil
+			}
+			return nil, errors.NewRequeueError(
+				fmt.Sprintf("waiting for a key %s of ConfigMap %s prepared", selector.Key, key),
+			)
+		}
+		return []byte(value), nil
+	case source.SecretKeyRef != nil:
+		selector := source.SecretKeyRef
+		value, err := k8s_secret.GetValueFromSecretKeySelector(ctx, c, obj.GetNamespace(), selector)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				if selector.Optional != nil && *selector.Optional {
+					return nil, nil
+				}
+				return nil, errors.NewRequeueError("waiting for a Secret of an extra cloud-config prepared").Wrap(err)
+			}
+			return nil, fmt.Errorf("unable to get an extra cloud-config: %w", err)
+		}
+		return value, nil
+	default:
+		return nil, nil
+	}
+}
+
+// mergeCloudConfig merges an extra cloud-config into a generated cloud-config. Mappings are merged recursively and
+// lists are appended to ones of the generated cloud-config. The extra cloud-config can't override scalar values or
+// paths of write_files of the generated cloud-config since etcd members are provisioned with them.
+func mergeCloudConfig(generated, extra []byte) ([]byte, error) {
+	var base map[string]interface{}
+	if err := yaml.Unmarshal(generated, &base); err != nil {
+		return nil, fmt.Errorf("unable to parse a generated cloud-config: %w", err)
+	}
+	if base == nil {
+		base = map[string]interface{}{}
+	}
+	var overlay map[string]interface{}
+	if err := yaml.Unmarshal(extra, &overlay); err != nil {
+		return nil, fmt.Errorf("unable to parse an extra cloud-config: %w", err)
+	}
+
+	generatedPaths := make(map[string]bool)
+	for _, path := range writeFilesPaths(base) {
+		generatedPaths[path] = true
+	}
+	for _, path := range writeFilesPaths(overlay) {
+		if generatedPaths[path] {
+			return nil, fmt.Errorf("an extra cloud-config can't override a generated file %s", path)
+		}
+	}
+
+	// cloud-init doesn't create the default user if users are specified without it, and the controller logs in to a
+	// virtual machine as the default user.
+	if _, ok := base["users"]; !ok {
+		if users, ok := overlay["users"].([]interface{}); ok {
+			hasDefaultUser := false
+			for _, user := range users {
+				if user == cloudConfigDefaultUser {
+					hasDefaultUser = true
+					break
+				}
+			}
+			if !hasDefaultUser {
+				overlay["users"] = append([]interface{}{cloudConfigDefaultUser}, users...)
+			}
+		}
+	}
+
+	if err := mergeCloudConfigMaps("", base, overlay); err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(base)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode a merged cloud-config: %w", err)
+	}
+	return append([]byte(cloudConfigHeader), data...), nil
+}
+
+func mergeCloudConfigMaps(path string, base, overlay map[string]interface{}) error {
+	for key, value := range overlay {
+		childPath := key
+		if path != "" {
+			childPath = path + "." + key
+		}
+		current, ok := base[key]
+		if !ok {
+			base[key] = value
+			continue
+		}
+		switch current := current.(type) {
+		case map[string]interface{}:
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s of an extra cloud-config must be a mapping", childPath)
+			}
+			if err := mergeCloudConfigMaps(childPath, current, m); err != nil {
+				return err
+			}
+		case []interface{}:
+			l, ok := value.([]interface{})
+			if !ok {
+				return fmt.Errorf("%s of an extra cloud-config must be a list", childPath)
+			}
+			base[key] = append(current, l...)
+		default:
+			if !reflect.DeepEqual(current, value) {
+				return fmt.Errorf("%s of an extra cloud-config can't override a generated value", childPath)
+			}
+		}
+	}
+	return nil
+}
+
+func writeFilesPaths(config map[string]interface{}) []string {
+	files, _ := config["write_files"].([]interface{})
+	var paths []string
+	for _, file := range files {
+		if m, ok := file.(map[string]interface{}); ok {
+			if path, ok := m["path"].(string); ok {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// validateCloudConfig validates that a cloud-config is a YAML mapping.
+func validateCloudConfig(data []byte) error {
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("unable to parse a cloud-config: %w", err)
+	}
+	return nil
+}
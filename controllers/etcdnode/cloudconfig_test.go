@@ -0,0 +1,133 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdnode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+const generatedCloudConfig = `
+#cloud-config
+ssh_pwauth: False
+ssh_authorized_keys:
+  - ssh-ed25519 AAAA
+write_files:
+- path: /opt/bin/start-cluster.sh
+  permissions: '0755'
+`
+
+func TestMergeCloudConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		extra    string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name: "lists are appended",
+			extra: `#cloud-config
+packages:
+- chrony
+runcmd:
+- [ systemctl, enable, --now, chronyd ]
+write_files:
+- path: /etc/chrony.conf
+  content: pool pool.ntp.org iburst
+`,
+			expected: `
+ssh_pwauth: false
+ssh_authorized_keys:
+- ssh-ed25519 AAAA
+packages:
+- chrony
+runcmd:
+- [systemctl, enable, --now, chronyd]
+write_files:
+- path: /opt/bin/start-cluster.sh
+  permissions: '0755'
+- path: /etc/chrony.conf
+  content: pool pool.ntp.org iburst
+`,
+		},
+		{
+			name: "the default user is kept",
+			extra: `
+users:
+- name: operator
+`,
+			expected: `
+ssh_pwauth: false
+ssh_authorized_keys:
+- ssh-ed25519 AAAA
+users:
+- default
+- name: operator
+write_files:
+- path: /opt/bin/start-cluster.sh
+  permissions: '0755'
+`,
+		},
+		{
+			name:    "generated scalar values can't be overridden",
+			extra:   `ssh_pwauth: True`,
+			wantErr: true,
+		},
+		{
+			name: "generated files can't be overridden",
+			extra: `
+write_files:
+- path: /opt/bin/start-cluster.sh
+  content: exit 0
+`,
+			wantErr: true,
+		},
+		{
+			name:    "an extra cloud-config must be a mapping",
+			extra:   `- chrony`,
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			merged, err := mergeCloudConfig([]byte(generatedCloudConfig), []byte(tc.extra))
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(string(merged), cloudConfigHeader))
+			require.NoError(t, validateCloudConfig(merged))
+
+			var actual, expected map[string]interface{}
+			require.NoError(t, yaml.Unmarshal(merged, &actual))
+			require.NoError(t, yaml.Unmarshal([]byte(tc.expected), &expected))
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
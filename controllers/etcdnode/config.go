@@ -0,0 +1,89 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdnode
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+)
+
+// etcdEnvVar is an environment variable which configures an etcd server.
+type etcdEnvVar struct {
+	Name  string
+	Value string
+}
+
+// newEtcdEnvVar returns an environment variable which corresponds to an etcd server flag.
+func newEtcdEnvVar(flag, value string) etcdEnvVar {
+	return etcdEnvVar{
+		Name:  "ETCD_" + strings.ToUpper(strings.ReplaceAll(flag, "-", "_")),
+		Value: value,
+	}
+}
+
+// newEtcdConfigEnv returns environment variables which configure an etcd server with the configuration and extra
+// arguments of a node.
+func newEtcdConfigEnv(spec *kubernetesimalv1alpha1.EtcdNodeSpec) []etcdEnvVar {
+	var env []etcdEnvVar
+	if config := spec.Config; config != nil {
+		if config.QuotaBackendBytes != nil {
+			env = append(env, newEtcdEnvVar("quota-backend-bytes", strconv.FormatInt(config.QuotaBackendBytes.Value(), 10)))
+		}
+		if config.AutoCompactionMode != "" {
+			env = append(env, newEtcdEnvVar("auto-compaction-mode", strings.ToLower(string(config.AutoCompactionMode))))
+		}
+		if config.AutoCompactionRetention != "" {
+			env = append(env, newEtcdEnvVar("auto-compaction-retention", config.AutoCompactionRetention))
+		}
+		if config.SnapshotCount != nil {
+			env = append(env, newEtcdEnvVar("snapshot-count", strconv.FormatInt(*config.SnapshotCount, 10)))
+		}
+		if config.HeartbeatInterval != nil {
+			env = append(env, newEtcdEnvVar(
+				"heartbeat-interval",
+				strconv.FormatInt(config.HeartbeatInterval.Milliseconds(), 10),
+			))
+		}
+		if config.ElectionTimeout != nil {
+			env = append(env, newEtcdEnvVar(
+				"election-timeout",
+				strconv.FormatInt(config.ElectionTimeout.Milliseconds(), 10),
+			))
+		}
+	}
+
+	flags := make([]string, 0, len(spec.ExtraArgs))
+	for flag := range spec.ExtraArgs {
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+	for _, flag := range flags {
+		env = append(env, newEtcdEnvVar(flag, spec.ExtraArgs[flag]))
+	}
+	return env
+}
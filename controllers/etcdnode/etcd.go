@@ -26,12 +26,11 @@ package etcdnode
 
 import (
 	"context"
-	"crypto/tls"
-	"crypto/x509"
 	"fmt"
 	"time"
 
 	"go.opentelemetry.io/otel/trace"
+	cryptossh "golang.org/x/crypto/ssh"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -41,6 +40,7 @@ import (
 
 	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
 	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	"github.com/kkohtaka/kubernetesimal/controller/etcdclient"
 	k8s_secret "github.com/kkohtaka/kubernetesimal/k8s/secret"
 	k8s_service "github.com/kkohtaka/kubernetesimal/k8s/service"
 	"github.com/kkohtaka/kubernetesimal/net/http"
@@ -48,17 +48,13 @@ import (
 	"github.com/kkohtaka/kubernetesimal/ssh"
 )
 
-func provisionEtcdMember(
+func startSSHConnectionToEtcdMember(
 	ctx context.Context,
 	c client.Client,
 	obj client.Object,
 	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
 	status *kubernetesimalv1alpha1.EtcdNodeStatus,
-) error {
-	var span trace.Span
-	ctx, span = tracing.FromContext(ctx).Start(ctx, "provisionEtcdMember")
-	defer span.End()
-
+) (*cryptossh.Client, func(), error) {
 	var vmi kubevirtv1.VirtualMachineInstance
 	if err := c.Get(
 		ctx,
@@ -69,13 +65,13 @@ func provisionEtcdMember(
 		&vmi,
 	); err != nil {
 		if apierrors.IsNotFound(err) {
-			return errors.NewRequeueError("waiting for a VirtualMachineInstance prepared").Wrap(err)
+			return nil, nil, errors.NewRequeueError("waiting for a VirtualMachineInstance prepared").Wrap(err)
 		}
-		return fmt.Errorf(
+		return nil, nil, fmt.Errorf(
 			"unable to get a VirtualMachineInstance %s/%s: %w", obj.GetNamespace(), status.VirtualMachineInstanceRef.Name, err)
 	}
 	if vmi.Status.Phase != kubevirtv1.Running {
-		return errors.NewRequeueError("waiting for a VirtualMachineInstance become running")
+		return nil, nil, errors.NewRequeueError("waiting for a VirtualMachineInstance become running")
 	}
 
 	privateKey, err := k8s_secret.GetValueFromSecretKeySelector(
@@ -86,9 +82,9 @@ func provisionEtcdMember(
 	)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			return errors.NewRequeueError("waiting for an SSH private key prepared").Wrap(err)
+			return nil, nil, errors.NewRequeueError("waiting for an SSH private key prepared").Wrap(err)
 		}
-		return err
+		return nil, nil, err
 	}
 
 	var peerService corev1.Service
@@ -101,45 +97,53 @@ func provisionEtcdMember(
 		&peerService,
 	); err != nil {
 		if apierrors.IsNotFound(err) {
-			return errors.NewRequeueError("waiting for the etcd Service prepared").Wrap(err)
+			return nil, nil, errors.NewRequeueError("waiting for the etcd Service prepared").Wrap(err)
 		}
-		return err
+		return nil, nil, err
 	}
 	if peerService.Spec.ClusterIP == "" {
-		return errors.NewRequeueError("waiting for a cluster IP of the etcd Service prepared").
+		return nil, nil, errors.NewRequeueError("waiting for a cluster IP of the etcd Service prepared").
 			Wrap(err).
 			WithDelay(5 * time.Second)
 	}
-	var port int32
-	for i := range peerService.Spec.Ports {
-		if peerService.Spec.Ports[i].Name == serviceNameSSH {
-			port = peerService.Spec.Ports[i].TargetPort.IntVal
-			break
-		}
-	}
-	if port == 0 {
-		return errors.NewRequeueError("waiting for an SSH port of the etcd peer Service prepared").Wrap(err)
+	host, jumpHosts, err := newSSHHosts(ctx, c, obj, spec, status, privateKey, &peerService)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	client, closer, err := ssh.StartSSHConnection(ctx, privateKey, peerService.Spec.ClusterIP, int(port))
+	client, closer, err := ssh.StartSSHConnection(ctx, host, jumpHosts...)
 	if err != nil {
-		return errors.NewRequeueError("waiting for an SSH port of an etcd member prepared").
+		return nil, nil, errors.NewRequeueError("waiting for an SSH port of an etcd member prepared").
 			Wrap(err).
 			WithDelay(5 * time.Second)
 	}
+	return client, closer, nil
+}
+
+func provisionEtcdMember(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+) (*kubernetesimalv1alpha1.EtcdNodeStatus, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "provisionEtcdMember")
+	defer span.End()
+
+	client, closer, err := startSSHConnectionToEtcdMember(ctx, c, obj, spec, status)
+	if err != nil {
+		return status, err
+	}
 	defer closer()
 
+	var steps []ssh.Step
 	if spec.AsFirstNode {
-		if err := ssh.RunCommandOverSSHSession(ctx, client, "sudo /opt/bin/start-cluster.sh"); err != nil {
-			return err
-		}
+		steps = newProvisioningSteps(c, obj, spec, status, provisioningStepInitCluster, "sudo /opt/bin/start-cluster.sh")
 	} else {
-		if err := ssh.RunCommandOverSSHSession(ctx, client, "sudo /opt/bin/join-cluster.sh"); err != nil {
-			return err
-		}
+		steps = newProvisioningSteps(c, obj, spec, status, provisioningStepJoinCluster, "sudo /opt/bin/join-cluster.sh")
 	}
-
-	return nil
+	return runProvisioningSteps(ctx, client, status, steps)
 }
 
 func probeEtcdMember(
@@ -152,77 +156,25 @@ func probeEtcdMember(
 	var span trace.Span
 	ctx, span = tracing.FromContext(ctx).Start(ctx, "probeEtcdMember")
 	defer span.End()
-	logger := log.FromContext(ctx)
 
 	address, err := k8s_service.GetAddressFromServiceRef(ctx, c, obj.GetNamespace(), "etcd", status.PeerServiceRef)
 	if err != nil {
 		return false, fmt.Errorf("unable to get an etcd address from a peer Service: %w", err)
 	}
 
-	caCertificate, err := k8s_secret.GetValueFromSecretKeySelector(
+	tlsConfig, err := etcdclient.NewTLSConfig(
 		ctx,
 		c,
 		obj.GetNamespace(),
-		&spec.CACertificateRef,
+		etcdclient.CredentialsFromEtcdNodeSpec(spec),
 	)
 	if err != nil {
-		if apierrors.IsNotFound(err) {
-			logger.Info("Skip probing an etcd since CA certificate isn't prepared yet.")
-			return false, errors.NewRequeueError("waiting for a CA certificate prepared").Wrap(err)
-		}
-		return false, fmt.Errorf("unable to get a CA certificate: %w", err)
-	}
-
-	rootCAs, err := x509.SystemCertPool()
-	if err != nil {
-		return false, fmt.Errorf("unable to load a client CA certificates from the system: %w", err)
-	}
-	if ok := rootCAs.AppendCertsFromPEM(caCertificate); !ok {
-		return false, fmt.Errorf("unable to load a client CA certificate from Secret")
-	}
-
-	clientCertificate, err := k8s_secret.GetValueFromSecretKeySelector(
-		ctx,
-		c,
-		obj.GetNamespace(),
-		&spec.ClientCertificateRef,
-	)
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			logger.Info("Skip probing an etcd since a client certificate isn't prepared yet.")
-			return false, errors.NewRequeueError("waiting for a client certificate prepared").Wrap(err)
-		}
-		return false, fmt.Errorf("unable to get a client certificate: %w", err)
-	}
-
-	clientPrivateKey, err := k8s_secret.GetValueFromSecretKeySelector(
-		ctx,
-		c,
-		obj.GetNamespace(),
-		&spec.ClientPrivateKeyRef,
-	)
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			logger.Info("Skip probing an etcd since a client private key isn't prepared yet.")
-			return false, errors.NewRequeueError("waiting for a client private key prepared").Wrap(err)
-		}
-		return false, fmt.Errorf("unable to get a client private key: %w", err)
-	}
-
-	certificate, err := tls.X509KeyPair(clientCertificate, clientPrivateKey)
-	if err != nil {
-		return false, fmt.Errorf("unable to load a client certificate: %w", err)
+		return false, err
 	}
 
 	return http.NewProber(
 		fmt.Sprintf("https://%s/health", address),
-		http.WithTLSConfig(&tls.Config{
-			Certificates: []tls.Certificate{
-				certificate,
-			},
-			RootCAs:            rootCAs,
-			InsecureSkipVerify: true,
-		}),
+		http.WithTLSConfig(tlsConfig),
 	).Once(ctx)
 }
 
@@ -243,14 +195,20 @@ func finalizeEtcdMember(
 		return status, nil
 	}
 
-	if !status.IsProvisioned() {
+	// A virtual machine of a remediated node can't be trusted to leave the etcd cluster by itself.
+	if _, ok := obj.GetAnnotations()[kubernetesimalv1alpha1.EtcdNodeRemediationAnnotation]; ok {
+		logger.Info("Removing an etcd member with the etcd API since the node is remediated.")
+		return removeUnreachableEtcdMember(ctx, c, obj, spec, status)
+	}
+
+	if !status.IsProvisioned() && status.MemberID == "" {
 		logger.V(4).Info("Skip finalizing an etcd member since an etcd member was not provisioned")
 		return status, nil
 	}
 
-	if status.VirtualMachineInstanceRef == nil {
-		logger.V(4).Info("Skip finalizing an etcd member since a VirtualMachineInstance doesn't exit")
-		return status, nil
+	if !status.IsProvisioned() || status.VirtualMachineInstanceRef == nil {
+		logger.Info("Removing an etcd member with the etcd API since a VirtualMachineInstance doesn't exist.")
+		return removeUnreachableEtcdMember(ctx, c, obj, spec, status)
 	}
 
 	var vmi kubevirtv1.VirtualMachineInstance
@@ -263,12 +221,19 @@ func finalizeEtcdMember(
 		&vmi,
 	); err != nil {
 		if apierrors.IsNotFound(err) {
-			logger.V(4).Info("Skip finalizing an etcd member since a VirtualMachineInstance doesn't exit")
-			return status, nil
+			logger.Info("Removing an etcd member with the etcd API since a VirtualMachineInstance doesn't exist.")
+			return removeUnreachableEtcdMember(ctx, c, obj, spec, status)
 		}
 		return status, fmt.Errorf(
 			"unable to get a VirtualMachineInstance %s/%s: %w", obj.GetNamespace(), status.VirtualMachineInstanceRef.Name, err)
 	}
+	if !vmi.GetDeletionTimestamp().IsZero() || vmi.Status.Phase != kubevirtv1.Running {
+		logger.Info(
+			"Removing an etcd member with the etcd API since a VirtualMachineInstance is not running.",
+			"phase", vmi.Status.Phase,
+		)
+		return removeUnreachableEtcdMember(ctx, c, obj, spec, status)
+	}
 
 	privateKey, err := k8s_secret.GetValueFromSecretKeySelector(
 		ctx,
@@ -293,26 +258,122 @@ func finalizeEtcdMember(
 		return status, fmt.Errorf(
 			"unable to get the etcd Service %s/%s: %w", obj.GetNamespace(), status.PeerServiceRef.Name, err)
 	}
-	var port int32
-	for i := range peerService.Spec.Ports {
-		if peerService.Spec.Ports[i].Name == serviceNameSSH {
-			port = peerService.Spec.Ports[i].TargetPort.IntVal
-			break
-		}
+	host, jumpHosts, err := newSSHHosts(ctx, c, obj, spec, status, privateKey, &peerService)
+	if err != nil {
+		return status.WithMemberFinalized(false, err.Error()), err
 	}
 
-	client, closer, err := ssh.StartSSHConnection(ctx, privateKey, peerService.Spec.ClusterIP, int(port))
+	client, closer, err := ssh.StartSSHConnection(ctx, host, jumpHosts...)
 	if err != nil {
-		err = errors.NewRequeueError("waiting for an SSH port of an etcd member prepared").
-			Wrap(err).
-			WithDelay(5 * time.Second)
-		return status.WithMemberFinalized(false, err.Error()), err
+		logger.Info(
+			"Removing an etcd member with the etcd API since a virtual machine is unreachable over SSH.",
+			"error", err.Error(),
+		)
+		return removeUnreachableEtcdMember(ctx, c, obj, spec, status)
 	}
 	defer closer()
 
+	// With the Systemd bootstrap driver, an etcd member is removed from the etcd cluster with the etcd API before it
+	// stops, since there's no etcdadm which removes it on a virtual machine.
+	if spec.BootstrapDriver == kubernetesimalv1alpha1.EtcdBootstrapDriverSystemd {
+		etcdClient, err := etcdclient.New(
+			ctx,
+			c,
+			obj.GetNamespace(),
+			&spec.ServiceRef,
+			etcdclient.CredentialsFromEtcdNodeSpec(spec),
+		)
+		if err != nil {
+			return status.WithMemberFinalized(false, err.Error()), err
+		}
+		defer etcdClient.Close()
+
+		memberID, err := parseMemberID(status.MemberID)
+		if err != nil {
+			return status, fmt.Errorf("unable to parse an etcd member ID %q: %w", status.MemberID, err)
+		}
+		var peerURL string
+		if address, err := getMemberAdvertiseAddress(ctx, c, obj, status); err == nil {
+			peerURL = newMemberURL(address, serviceContainerPortPeer)
+		}
+		if err := removeEtcdMember(ctx, etcdClient, memberID, status.PeerServiceRef.Name, peerURL); err != nil {
+			return status.WithMemberFinalized(false, err.Error()), err
+		}
+	}
+
 	if err := ssh.RunCommandOverSSHSession(ctx, client, "sudo /opt/bin/leave-cluster.sh"); err != nil {
 		return status.WithMemberFinalized(false, err.Error()), err
 	}
 	logger.Info("An etcd member was finalized successfully.")
 	return status.WithMemberFinalized(true, ""), nil
 }
+
+// removeUnreachableEtcdMember removes an etcd member whose virtual machine is unreachable from an etcd cluster with the
+// client certificate of the cluster, instead of running leave-cluster.sh on the virtual machine.
+func removeUnreachableEtcdMember(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+) (*kubernetesimalv1alpha1.EtcdNodeStatus, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "removeUnreachableEtcdMember")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	// The whole etcd cluster is being torn down if the Service of the cluster is gone.
+	var service corev1.Service
+	if err := c.Get(
+		ctx,
+		types.NamespacedName{
+			Namespace: obj.GetNamespace(),
+			Name:      spec.ServiceRef.Name,
+		},
+		&service,
+	); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.V(4).Info("Skip removing an etcd member since the etcd Service doesn't exist")
+			return status.WithMemberFinalized(true, ""), nil
+		}
+		return status, fmt.Errorf(
+			"unable to get the etcd Service %s/%s: %w", obj.GetNamespace(), spec.ServiceRef.Name, err)
+	}
+	if !service.GetDeletionTimestamp().IsZero() {
+		logger.V(4).Info("Skip removing an etcd member since the etcd Service is being deleted")
+		return status.WithMemberFinalized(true, ""), nil
+	}
+
+	memberID, err := parseMemberID(status.MemberID)
+	if err != nil {
+		return status, fmt.Errorf("unable to parse an etcd member ID %q: %w", status.MemberID, err)
+	}
+
+	etcdClient, err := etcdclient.New(
+		ctx,
+		c,
+		obj.GetNamespace(),
+		&spec.ServiceRef,
+		etcdclient.CredentialsFromEtcdNodeSpec(spec),
+	)
+	if err != nil {
+		return status.WithMemberFinalized(false, err.Error()), err
+	}
+	defer etcdClient.Close()
+
+	// A member which hasn't started yet can be found only with its peer URL.
+	var peerURL string
+	if status.VirtualMachineInstanceRef != nil {
+		if address, err := getMemberAdvertiseAddress(ctx, c, obj, status); err == nil {
+			peerURL = newMemberURL(address, serviceContainerPortPeer)
+		}
+	}
+	if err := removeEtcdMember(ctx, etcdClient, memberID, obj.GetName(), peerURL); err != nil {
+		err = errors.NewRequeueError("waiting for an etcd cluster become reachable").
+			Wrap(err).
+			WithDelay(5 * time.Second)
+		return status.WithMemberFinalized(false, err.Error()), err
+	}
+	logger.Info("An etcd member was removed with the etcd API.", "id", status.MemberID)
+	return status.WithMemberFinalized(true, ""), nil
+}
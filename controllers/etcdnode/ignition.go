@@ -0,0 +1,267 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdnode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/ssh"
+)
+
+const (
+	ignitionVersion = "3.3.0"
+
+	// ignitionDefaultUser is the default user of operating systems bootstrapped with Ignition.
+	ignitionDefaultUser = "core"
+
+	sshHostPrivateKeyPath = "/etc/ssh/ssh_host_ed25519_key"
+	sshHostPublicKeyPath  = "/etc/ssh/ssh_host_ed25519_key.pub"
+	sshdConfigPath        = "/etc/ssh/sshd_config.d/40-kubernetesimal.conf"
+	loginPasswordPath     = "/etc/kubernetesimal/login-password"
+)
+
+// bootstrapData is data which bootstraps a virtual machine of an etcd node. Contents of files are base64-encoded.
+type bootstrapData struct {
+	LoginPassword               string
+	AuthorizedKeys              []string
+	SSHHostPrivateKey           string
+	SSHHostPublicKey            string
+	InstallBinariesScript       string
+	StartClusterScript          string
+	JoinClusterScript           string
+	RestoreClusterScript        string
+	LeaveClusterScript          string
+	CACertificate, CAPrivateKey string
+	DataDevice                  string
+	DataLabel                   string
+	DataDir                     string
+}
+
+// ignitionConfig is a subset of an Ignition config of the spec version 3.3.0.
+type ignitionConfig struct {
+	Ignition ignitionMetadata `json:"ignition"`
+	Passwd   ignitionPasswd   `json:"passwd"`
+	Storage  ignitionStorage  `json:"storage"`
+	Systemd  ignitionSystemd  `json:"systemd"`
+}
+
+type ignitionMetadata struct {
+	Version string `json:"version"`
+}
+
+type ignitionPasswd struct {
+	Users []ignitionUser `json:"users,omitempty"`
+}
+
+type ignitionUser struct {
+	Name              string   `json:"name"`
+	Groups            []string `json:"groups,omitempty"`
+	SSHAuthorizedKeys []string `json:"sshAuthorizedKeys,omitempty"`
+}
+
+type ignitionStorage struct {
+	Files       []ignitionFile       `json:"files,omitempty"`
+	Filesystems []ignitionFilesystem `json:"filesystems,omitempty"`
+}
+
+type ignitionFile struct {
+	Path      string           `json:"path"`
+	Mode      int              `json:"mode"`
+	Overwrite bool             `json:"overwrite"`
+	Contents  ignitionResource `json:"contents"`
+}
+
+type ignitionResource struct {
+	Source string `json:"source"`
+}
+
+type ignitionFilesystem struct {
+	Device         string `json:"device"`
+	Format         string `json:"format"`
+	Label          string `json:"label"`
+	WipeFilesystem bool   `json:"wipeFilesystem"`
+}
+
+type ignitionSystemd struct {
+	Units []ignitionUnit `json:"units,omitempty"`
+}
+
+type ignitionUnit struct {
+	Name     string `json:"name"`
+	Enabled  bool   `json:"enabled"`
+	Contents string `json:"contents"`
+}
+
+// bootstrapFormatOf returns a format of bootstrap data which is passed to a virtual machine of an etcd node.
+func bootstrapFormatOf(spec *kubernetesimalv1alpha1.EtcdNodeSpec) kubernetesimalv1alpha1.EtcdBootstrapFormat {
+	if spec.Bootstrap == nil || spec.Bootstrap.Format == "" {
+		return kubernetesimalv1alpha1.EtcdBootstrapFormatCloudInit
+	}
+	return spec.Bootstrap.Format
+}
+
+// getLoginUser returns a user who logs in to a virtual machine of an etcd node.
+func getLoginUser(spec *kubernetesimalv1alpha1.EtcdNodeSpec) string {
+	if spec.SSH != nil && spec.SSH.User != "" {
+		return spec.SSH.User
+	}
+	if bootstrapFormatOf(spec) == kubernetesimalv1alpha1.EtcdBootstrapFormatIgnition {
+		return ignitionDefaultUser
+	}
+	return ssh.DefaultUser
+}
+
+// newIgnitionConfig returns an Ignition config which places the same files, units and keys as a cloud-config
+// generated from the same bootstrap data.
+func newIgnitionConfig(user string, data *bootstrapData) ([]byte, error) {
+	authorizedKeys := make([]string, 0, len(data.AuthorizedKeys))
+	for _, key := range data.AuthorizedKeys {
+		authorizedKeys = append(authorizedKeys, strings.TrimSpace(key))
+	}
+
+	config := ignitionConfig{
+		Ignition: ignitionMetadata{
+			Version: ignitionVersion,
+		},
+		Passwd: ignitionPasswd{
+			Users: []ignitionUser{
+				{
+					Name:              user,
+					Groups:            []string{"sudo"},
+					SSHAuthorizedKeys: authorizedKeys,
+				},
+			},
+		},
+	}
+
+	addFile := func(path string, mode int, content string) {
+		config.Storage.Files = append(config.Storage.Files, ignitionFile{
+			Path:      path,
+			Mode:      mode,
+			Overwrite: true,
+			Contents: ignitionResource{
+				Source: "data:;base64," + content,
+			},
+		})
+	}
+	addFile("/opt/bin/install-binaries.sh", 0o755, data.InstallBinariesScript)
+	addFile("/opt/bin/start-cluster.sh", 0o755, data.StartClusterScript)
+	addFile("/opt/bin/join-cluster.sh", 0o755, data.JoinClusterScript)
+	if data.RestoreClusterScript != "" {
+		addFile("/opt/bin/restore-cluster.sh", 0o755, data.RestoreClusterScript)
+	}
+	addFile("/opt/bin/leave-cluster.sh", 0o755, data.LeaveClusterScript)
+	addFile(etcdPKIDir+"/ca.crt", 0o444, data.CACertificate)
+	if data.CAPrivateKey != "" {
+		addFile(etcdPKIDir+"/ca.key", 0o400, data.CAPrivateKey)
+	}
+	if data.SSHHostPrivateKey != "" {
+		addFile(
+			sshHostPrivateKeyPath,
+			0o600,
+			base64.StdEncoding.EncodeToString([]byte(strings.TrimSpace(data.SSHHostPrivateKey)+"\n")),
+		)
+		addFile(sshHostPublicKeyPath, 0o644, base64.StdEncoding.EncodeToString([]byte(data.SSHHostPublicKey+"\n")))
+	}
+	addFile(sshdConfigPath, 0o644, base64.StdEncoding.EncodeToString([]byte("PasswordAuthentication no\n")))
+
+	if data.LoginPassword != "" {
+		addFile(
+			loginPasswordPath,
+			0o400,
+			base64.StdEncoding.EncodeToString([]byte(user+":"+data.LoginPassword+"\n")),
+		)
+		unit, err := renderIgnitionUnit(
+			"set-login-password.service.tmpl",
+			&struct {
+				PasswordPath string
+			}{
+				PasswordPath: loginPasswordPath,
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		config.Systemd.Units = append(config.Systemd.Units, ignitionUnit{
+			Name:     "set-login-password.service",
+			Enabled:  true,
+			Contents: unit,
+		})
+	}
+
+	if data.DataDevice != "" {
+		config.Storage.Filesystems = append(config.Storage.Filesystems, ignitionFilesystem{
+			Device: data.DataDevice,
+			Format: "ext4",
+			Label:  data.DataLabel,
+		})
+		unit, err := renderIgnitionUnit(
+			"data.mount.tmpl",
+			&struct {
+				Label   string
+				DataDir string
+			}{
+				Label:   data.DataLabel,
+				DataDir: data.DataDir,
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		config.Systemd.Units = append(config.Systemd.Units, ignitionUnit{
+			// A name of a mount unit must be its escaped mount point. The data directory doesn't contain characters
+			// which need to be escaped other than slashes.
+			Name:     strings.ReplaceAll(strings.TrimPrefix(data.DataDir, "/"), "/", "-") + ".mount",
+			Enabled:  true,
+			Contents: unit,
+		})
+	}
+
+	b, err := json.Marshal(&config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode an Ignition config: %w", err)
+	}
+	return b, nil
+}
+
+func renderIgnitionUnit(name string, data interface{}) (string, error) {
+	buf := bytes.Buffer{}
+	tmpl, err := template.New(name).Funcs(sprig.FuncMap()).ParseFS(cloudConfigTemplates, "templates/"+name)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse a template of %s: %w", strings.TrimSuffix(name, ".tmpl"), err)
+	}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("unable to render %s from a template: %w", strings.TrimSuffix(name, ".tmpl"), err)
+	}
+	return strings.TrimLeft(buf.String(), "\n"), nil
+}
@@ -0,0 +1,147 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdnode
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	"github.com/kkohtaka/kubernetesimal/controller/etcdclient"
+	"github.com/kkohtaka/kubernetesimal/observability/tracing"
+)
+
+const (
+	defaultLearnerPromotionThreshold = 1000
+)
+
+// joinsAsLearner returns whether an etcd member of the node joins an existing etcd cluster as a learner.
+func joinsAsLearner(spec *kubernetesimalv1alpha1.EtcdNodeSpec) bool {
+	return !spec.AsFirstNode && spec.Join != nil && spec.Join.Mode == kubernetesimalv1alpha1.EtcdJoinModeLearner
+}
+
+// getLearnerPromotionThreshold returns the maximum number of raft entries which a learner can lag behind the leader to
+// be promoted to a voting member.
+func getLearnerPromotionThreshold(spec *kubernetesimalv1alpha1.EtcdNodeSpec) uint64 {
+	if spec.Join == nil || spec.Join.PromotionThreshold == nil || *spec.Join.PromotionThreshold < 0 {
+		return defaultLearnerPromotionThreshold
+	}
+	return uint64(*spec.Join.PromotionThreshold)
+}
+
+// getLearnerLag returns the number of raft entries which a learner lags behind the leader. A learner whose raft index
+// is ahead of the one of the leader observed earlier doesn't lag.
+func getLearnerLag(leaderIndex, learnerIndex uint64) uint64 {
+	if leaderIndex <= learnerIndex {
+		return 0
+	}
+	return leaderIndex - learnerIndex
+}
+
+// promoteEtcdLearner promotes an etcd member which is a learner to a voting member once its raft index is within the
+// promotion threshold of the one of the leader.
+func promoteEtcdLearner(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+	learnerStatus *clientv3.StatusResponse,
+) (*kubernetesimalv1alpha1.EtcdNodeStatus, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "promoteEtcdLearner")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	etcdClient, err := etcdclient.New(
+		ctx,
+		c,
+		obj.GetNamespace(),
+		&spec.ServiceRef,
+		etcdclient.CredentialsFromEtcdNodeSpec(spec),
+	)
+	if err != nil {
+		return status, err
+	}
+	defer etcdClient.Close()
+
+	listCtx, listCancel := context.WithTimeout(ctx, defaultEtcdRequestTimeout)
+	listResp, err := etcdClient.MemberList(listCtx)
+	listCancel()
+	if err != nil {
+		return status, fmt.Errorf("unable to list etcd members: %w", err)
+	}
+
+	var leaderIndex uint64
+	leaderFound := false
+	for _, m := range listResp.Members {
+		if m.ID != learnerStatus.Leader {
+			continue
+		}
+		for _, u := range m.ClientURLs {
+			statusCtx, statusCancel := context.WithTimeout(ctx, defaultEtcdRequestTimeout)
+			leaderStatus, err := etcdClient.Status(statusCtx, u)
+			statusCancel()
+			if err != nil {
+				logger.V(4).Info("Unable to get a status of the etcd leader.", "url", u, "error", err.Error())
+				continue
+			}
+			leaderIndex = leaderStatus.RaftIndex
+			leaderFound = true
+			break
+		}
+	}
+	if !leaderFound {
+		return status, errors.NewRequeueError("waiting for the etcd leader become reachable").
+			WithDelay(5 * time.Second)
+	}
+
+	lag := getLearnerLag(leaderIndex, learnerStatus.RaftIndex)
+	if threshold := getLearnerPromotionThreshold(spec); lag > threshold {
+		logger.V(4).Info("Waiting for a learner caught up with the etcd leader.", "lag", lag, "threshold", threshold)
+		return status, nil
+	}
+
+	promoteCtx, promoteCancel := context.WithTimeout(ctx, defaultEtcdRequestTimeout)
+	_, err = etcdClient.MemberPromote(promoteCtx, learnerStatus.Header.MemberId)
+	promoteCancel()
+	if err != nil {
+		return status, errors.NewRequeueError("waiting for a learner ready to be promoted").
+			Wrap(err).
+			WithDelay(5 * time.Second)
+	}
+	logger.Info("A learner was promoted to a voting member.", "id", formatMemberID(learnerStatus.Header.MemberId))
+
+	newStatus := status.DeepCopy()
+	newStatus.Learner = false
+	return newStatus, nil
+}
@@ -0,0 +1,94 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdnode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/pointer"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+)
+
+func TestGetLearnerLag(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		leaderIndex  uint64
+		learnerIndex uint64
+		expected     uint64
+	}{
+		{
+			name:         "a learner behind the leader",
+			leaderIndex:  1500,
+			learnerIndex: 300,
+			expected:     1200,
+		},
+		{
+			name:         "a learner caught up with the leader",
+			leaderIndex:  1500,
+			learnerIndex: 1500,
+			expected:     0,
+		},
+		{
+			name:         "a learner ahead of the leader observed earlier",
+			leaderIndex:  1500,
+			learnerIndex: 1600,
+			expected:     0,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, getLearnerLag(tc.leaderIndex, tc.learnerIndex))
+		})
+	}
+}
+
+func TestGetLearnerPromotionThreshold(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		join     *kubernetesimalv1alpha1.EtcdJoinSpec
+		expected uint64
+	}{
+		{
+			name:     "no join spec",
+			expected: defaultLearnerPromotionThreshold,
+		},
+		{
+			name:     "a threshold",
+			join:     &kubernetesimalv1alpha1.EtcdJoinSpec{PromotionThreshold: pointer.Int64(10)},
+			expected: 10,
+		},
+		{
+			name:     "a negative threshold",
+			join:     &kubernetesimalv1alpha1.EtcdJoinSpec{PromotionThreshold: pointer.Int64(-1)},
+			expected: defaultLearnerPromotionThreshold,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &kubernetesimalv1alpha1.EtcdNodeSpec{Join: tc.join}
+			assert.Equal(t, tc.expected, getLearnerPromotionThreshold(spec))
+		})
+	}
+}
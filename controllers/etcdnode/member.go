@@ -0,0 +1,326 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdnode
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	"github.com/kkohtaka/kubernetesimal/controller/etcdclient"
+	k8s_service "github.com/kkohtaka/kubernetesimal/k8s/service"
+	"github.com/kkohtaka/kubernetesimal/observability/tracing"
+)
+
+const (
+	defaultEtcdRequestTimeout = 5 * time.Second
+)
+
+// getMemberAdvertiseAddress returns an address of the VirtualMachineInstance which an etcd member advertises to peers
+// and clients.
+func getMemberAdvertiseAddress(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+) (string, error) {
+	var vmi kubevirtv1.VirtualMachineInstance
+	if err := c.Get(
+		ctx,
+		types.NamespacedName{
+			Namespace: obj.GetNamespace(),
+			Name:      status.VirtualMachineInstanceRef.Name,
+		},
+		&vmi,
+	); err != nil {
+		return "", fmt.Errorf("unable to get a VirtualMachineInstance: %w", err)
+	}
+	for _, iface := range vmi.Status.Interfaces {
+		if ip := net.ParseIP(iface.IP); ip != nil {
+			return ip.String(), nil
+		}
+	}
+	return "", errors.NewRequeueError("waiting for an IP address of a VirtualMachineInstance assigned").
+		WithDelay(5 * time.Second)
+}
+
+// newMemberURL returns a URL of an etcd member with the specified address and port.
+func newMemberURL(address string, port int) string {
+	return "https://" + net.JoinHostPort(address, strconv.Itoa(port))
+}
+
+// addEtcdMember adds an etcd member with the peer URL to an etcd cluster unless it has been added already, and returns
+// the initial cluster which the member starts with. The member is added as a learner if asLearner is true.
+func addEtcdMember(
+	ctx context.Context,
+	etcdClient *clientv3.Client,
+	name, peerURL string,
+	asLearner bool,
+) (string, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "addEtcdMember")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	listCtx, listCancel := context.WithTimeout(ctx, defaultEtcdRequestTimeout)
+	listResp, err := etcdClient.MemberList(listCtx)
+	listCancel()
+	if err != nil {
+		return "", fmt.Errorf("unable to list etcd members: %w", err)
+	}
+
+	members := listResp.Members
+	added := false
+	for _, m := range members {
+		if slices.Contains(m.PeerURLs, peerURL) {
+			added = true
+			break
+		}
+	}
+	if !added {
+		addCtx, addCancel := context.WithTimeout(ctx, defaultEtcdRequestTimeout)
+		var addResp *clientv3.MemberAddResponse
+		if asLearner {
+			addResp, err = etcdClient.MemberAddAsLearner(addCtx, []string{peerURL})
+		} else {
+			addResp, err = etcdClient.MemberAdd(addCtx, []string{peerURL})
+		}
+		addCancel()
+		if err != nil {
+			return "", fmt.Errorf("unable to add an etcd member: %w", err)
+		}
+		logger.Info("An etcd member was added.", "id", addResp.Member.ID, "peerURL", peerURL, "learner", asLearner)
+		members = addResp.Members
+	}
+
+	var initialCluster []string
+	for _, m := range members {
+		memberName := m.Name
+		if slices.Contains(m.PeerURLs, peerURL) {
+			// A member which hasn't started yet has no name.
+			memberName = name
+		} else if memberName == "" {
+			continue
+		}
+		for _, u := range m.PeerURLs {
+			initialCluster = append(initialCluster, memberName+"="+u)
+		}
+	}
+	return strings.Join(initialCluster, ","), nil
+}
+
+// updateEtcdMemberPeerURL updates the peer URL of an etcd member which was provisioned on a previous virtual machine
+// to the address of the current one. The first member is updated through itself after it starts since it might be the
+// only member of an etcd cluster, and other members are updated through the etcd cluster before they start.
+func updateEtcdMemberPeerURL(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+) error {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "updateEtcdMemberPeerURL")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	memberID, err := parseMemberID(status.MemberID)
+	if err != nil {
+		return fmt.Errorf("unable to parse an etcd member ID %s: %w", status.MemberID, err)
+	}
+
+	address, err := getMemberAdvertiseAddress(ctx, c, obj, status)
+	if err != nil {
+		return err
+	}
+	peerURL := newMemberURL(address, serviceContainerPortPeer)
+
+	var etcdClient *clientv3.Client
+	if spec.AsFirstNode {
+		var memberAddress string
+		memberAddress, err = k8s_service.GetAddressFromServiceRef(
+			ctx,
+			c,
+			obj.GetNamespace(),
+			"etcd",
+			status.PeerServiceRef,
+		)
+		if err != nil {
+			return fmt.Errorf("unable to get an etcd address from a peer Service: %w", err)
+		}
+		etcdClient, err = etcdclient.NewForEndpoint(
+			ctx,
+			c,
+			obj.GetNamespace(),
+			fmt.Sprintf("https://%s", memberAddress),
+			etcdclient.CredentialsFromEtcdNodeSpec(spec),
+		)
+	} else {
+		etcdClient, err = etcdclient.New(
+			ctx,
+			c,
+			obj.GetNamespace(),
+			&spec.ServiceRef,
+			etcdclient.CredentialsFromEtcdNodeSpec(spec),
+		)
+	}
+	if err != nil {
+		return err
+	}
+	defer etcdClient.Close()
+
+	listCtx, listCancel := context.WithTimeout(ctx, defaultEtcdRequestTimeout)
+	listResp, err := etcdClient.MemberList(listCtx)
+	listCancel()
+	if err != nil {
+		return fmt.Errorf("unable to list etcd members: %w", err)
+	}
+	for _, m := range listResp.Members {
+		if m.ID != memberID {
+			continue
+		}
+		if slices.Equal(m.PeerURLs, []string{peerURL}) {
+			return nil
+		}
+		updateCtx, updateCancel := context.WithTimeout(ctx, defaultEtcdRequestTimeout)
+		_, err := etcdClient.MemberUpdate(updateCtx, memberID, []string{peerURL})
+		updateCancel()
+		if err != nil {
+			return fmt.Errorf("unable to update a peer URL of an etcd member: %w", err)
+		}
+		logger.Info("A peer URL of an etcd member was updated.", "id", status.MemberID, "peerURL", peerURL)
+		return nil
+	}
+	return fmt.Errorf("etcd member %s is not found", status.MemberID)
+}
+
+// getEtcdMemberStatus returns a status of an etcd member which serves through the peer Service of the member. It
+// contains the ID of the member, whether the member is a learner and the raft index of the member.
+func getEtcdMemberStatus(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+) (*clientv3.StatusResponse, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "getEtcdMemberStatus")
+	defer span.End()
+
+	address, err := k8s_service.GetAddressFromServiceRef(ctx, c, obj.GetNamespace(), "etcd", status.PeerServiceRef)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get an etcd address from a peer Service: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("https://%s", address)
+	etcdClient, err := etcdclient.NewForEndpoint(
+		ctx,
+		c,
+		obj.GetNamespace(),
+		endpoint,
+		etcdclient.CredentialsFromEtcdNodeSpec(spec),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer etcdClient.Close()
+
+	statusCtx, statusCancel := context.WithTimeout(ctx, defaultEtcdRequestTimeout)
+	defer statusCancel()
+	resp, err := etcdClient.Status(statusCtx, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get a status of an etcd member: %w", err)
+	}
+	return resp, nil
+}
+
+// formatMemberID returns a hexadecimal representation of an etcd member ID as etcdctl shows.
+func formatMemberID(id uint64) string {
+	return strconv.FormatUint(id, 16)
+}
+
+// parseMemberID parses a hexadecimal representation of an etcd member ID. It returns 0, which is never used as a member
+// ID, if the ID is not recorded.
+func parseMemberID(id string) (uint64, error) {
+	if id == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(id, 16, 64)
+}
+
+// removeEtcdMember removes an etcd member with the ID, the name or the peer URL from an etcd cluster if it exists. The
+// last member of an etcd cluster is never removed, since the cluster is being torn down in that case.
+func removeEtcdMember(
+	ctx context.Context,
+	etcdClient *clientv3.Client,
+	id uint64,
+	name, peerURL string,
+) error {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "removeEtcdMember")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	listCtx, listCancel := context.WithTimeout(ctx, defaultEtcdRequestTimeout)
+	listResp, err := etcdClient.MemberList(listCtx)
+	listCancel()
+	if err != nil {
+		return fmt.Errorf("unable to list etcd members: %w", err)
+	}
+
+	if len(listResp.Members) <= 1 {
+		logger.V(4).Info("Skip removing the last etcd member of an etcd cluster.")
+		return nil
+	}
+
+	for _, m := range listResp.Members {
+		if (id == 0 || m.ID != id) &&
+			(name == "" || m.Name != name) &&
+			(peerURL == "" || !slices.Contains(m.PeerURLs, peerURL)) {
+			continue
+		}
+		removeCtx, removeCancel := context.WithTimeout(ctx, defaultEtcdRequestTimeout)
+		_, err := etcdClient.MemberRemove(removeCtx, m.ID)
+		removeCancel()
+		if err != nil {
+			return fmt.Errorf("unable to remove an etcd member %x: %w", m.ID, err)
+		}
+		logger.Info("An etcd member was removed.", "id", m.ID, "name", m.Name)
+	}
+	return nil
+}
@@ -124,15 +124,39 @@ func (r *Prober) doReconcile(
 
 	if probed, err := probeEtcdMember(ctx, r.Client, obj, spec, status); err != nil {
 		status.WithReady(false, err.Error()).DeepCopyInto(status)
+		status.ProbeFailures++
 		return status, fmt.Errorf("unable to probe an etcd member: %w", err)
 	} else {
 		if probed {
 			logger.V(4).Info("Probing an etcd member was succeeded.")
+			status.ProbeFailures = 0
 		} else {
 			logger.V(4).Info("Probing an etcd member was failed.")
+			status.ProbeFailures++
 		}
 		status.WithReady(probed, "").DeepCopyInto(status)
 	}
+
+	// The member ID is recorded so that the member can be removed from the etcd cluster even after its virtual machine
+	// is gone. A learner, which may not be ready yet, is checked until it's promoted to a voting member. Only a member
+	// which was provisioned successfully is asked since others don't respond until the request times out.
+	if status.IsProvisioningSucceeded() && (status.MemberID == "" || status.Learner) {
+		memberStatus, err := getEtcdMemberStatus(ctx, r.Client, obj, spec, status)
+		if err != nil {
+			logger.V(4).Info("Unable to get a status of an etcd member.", "error", err.Error())
+			return status, nil
+		}
+		status.MemberID = formatMemberID(memberStatus.Header.MemberId)
+		status.Learner = memberStatus.IsLearner
+
+		if status.Learner {
+			if newStatus, err := promoteEtcdLearner(ctx, r.Client, obj, spec, status, memberStatus); err != nil {
+				return status, fmt.Errorf("unable to promote a learner: %w", err)
+			} else {
+				status = newStatus
+			}
+		}
+	}
 	return status, nil
 }
 
@@ -171,7 +195,7 @@ func getProbeInterval(status *kubernetesimalv1alpha1.EtcdNodeStatus) time.Durati
 		probeInterval           = 3 * time.Minute
 	)
 
-	if !status.IsReady() {
+	if !status.IsReady() || status.Learner {
 		return probeIntervalOnNotReady
 	}
 	return probeInterval
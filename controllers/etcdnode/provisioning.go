@@ -0,0 +1,221 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdnode
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	cryptossh "golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	"github.com/kkohtaka/kubernetesimal/observability/tracing"
+	"github.com/kkohtaka/kubernetesimal/ssh"
+)
+
+const (
+	provisioningStepInstallBinaries   = "InstallBinaries"
+	provisioningStepWriteCertificates = "WriteCertificates"
+	provisioningStepConfigureEtcd     = "ConfigureEtcd"
+	provisioningStepInitCluster       = "InitCluster"
+	provisioningStepJoinCluster       = "JoinCluster"
+	provisioningStepRestoreCluster    = "RestoreCluster"
+	provisioningStepUpdatePeerURL     = "UpdatePeerURL"
+)
+
+// newProvisioningSteps returns steps to provision an etcd member, which install binaries, write certificates and
+// then run the specified command to start etcd. With the Systemd bootstrap driver, a systemd unit and a configuration
+// file of etcd are also placed before etcd starts. A member which was provisioned on a previous virtual machine also
+// updates its peer URL, so that it's found as an added member of the etcd cluster and restarts with its data.
+func newProvisioningSteps(
+	c client.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+	name, command string,
+) []ssh.Step {
+	var updatePeerURLSteps []ssh.Step
+	if status.MemberID != "" {
+		updatePeerURLSteps = append(updatePeerURLSteps, ssh.Step{
+			Name: provisioningStepUpdatePeerURL,
+			Func: func(ctx context.Context, _ *cryptossh.Client) error {
+				return updateEtcdMemberPeerURL(ctx, c, obj, spec, status)
+			},
+		})
+	}
+
+	steps := []ssh.Step{
+		{
+			Name:    provisioningStepInstallBinaries,
+			Command: "sudo /opt/bin/install-binaries.sh",
+		},
+		{
+			Name: provisioningStepWriteCertificates,
+			Func: func(ctx context.Context, sshClient *cryptossh.Client) error {
+				return issueMemberCertificates(ctx, c, sshClient, obj, spec, status)
+			},
+		},
+	}
+	if !spec.AsFirstNode {
+		steps = append(steps, updatePeerURLSteps...)
+	}
+	if spec.BootstrapDriver == kubernetesimalv1alpha1.EtcdBootstrapDriverSystemd {
+		steps = append(steps, ssh.Step{
+			Name: provisioningStepConfigureEtcd,
+			Func: func(ctx context.Context, sshClient *cryptossh.Client) error {
+				return configureEtcdMember(ctx, c, sshClient, obj, spec, status)
+			},
+		})
+	}
+	steps = append(steps, ssh.Step{
+		Name:    name,
+		Command: command,
+	})
+	if spec.AsFirstNode {
+		steps = append(steps, updatePeerURLSteps...)
+	}
+	return steps
+}
+
+// reconcileProvisionedVirtualMachineInstance records the UID of a VirtualMachineInstance which an etcd member is
+// provisioned on, and resets results of provisioning if the VirtualMachineInstance was recreated.
+func reconcileProvisionedVirtualMachineInstance(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+) (*kubernetesimalv1alpha1.EtcdNodeStatus, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "reconcileProvisionedVirtualMachineInstance")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	var vmi kubevirtv1.VirtualMachineInstance
+	if err := c.Get(
+		ctx,
+		types.NamespacedName{
+			Namespace: obj.GetNamespace(),
+			Name:      status.VirtualMachineInstanceRef.Name,
+		},
+		&vmi,
+	); err != nil {
+		if apierrors.IsNotFound(err) {
+			return status, errors.NewRequeueError("waiting for a VirtualMachineInstance created").
+				Wrap(err).
+				WithDelay(5 * time.Second)
+		}
+		return status, fmt.Errorf(
+			"unable to get a VirtualMachineInstance %s/%s: %w",
+			obj.GetNamespace(),
+			status.VirtualMachineInstanceRef.Name,
+			err,
+		)
+	}
+
+	newStatus, reset := observeVirtualMachineInstanceUID(status, vmi.UID)
+	if reset {
+		logger.Info("Provisioning an etcd member is reset since a VirtualMachineInstance was recreated.")
+	}
+	return newStatus, nil
+}
+
+// observeVirtualMachineInstanceUID records the UID of a VirtualMachineInstance in a status. If another
+// VirtualMachineInstance was recorded, the Provisioned condition and provisioning steps are reset since a root disk
+// of a virtual machine is ephemeral, and the member is marked as not ready. It returns true if they were reset.
+func observeVirtualMachineInstanceUID(
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+	uid types.UID,
+) (*kubernetesimalv1alpha1.EtcdNodeStatus, bool) {
+	newStatus := status.DeepCopy()
+	if newStatus.VirtualMachineInstanceUID == uid {
+		return newStatus, false
+	}
+	recreated := newStatus.VirtualMachineInstanceUID != ""
+	newStatus.VirtualMachineInstanceUID = uid
+	if !recreated {
+		return newStatus, false
+	}
+
+	now := metav1.Now()
+	for i := range newStatus.Conditions {
+		if newStatus.Conditions[i].Type != kubernetesimalv1alpha1.EtcdNodeConditionTypeProvisioned {
+			continue
+		}
+		if newStatus.Conditions[i].Status != corev1.ConditionFalse {
+			newStatus.Conditions[i].LastTransitionTime = &now
+		}
+		newStatus.Conditions[i].Status = corev1.ConditionFalse
+		newStatus.Conditions[i].LastProbeTime = nil
+		newStatus.Conditions[i].Message = "the VirtualMachineInstance was recreated"
+	}
+	newStatus.ProvisioningSteps = nil
+	return newStatus.WithReady(false, "the VirtualMachineInstance was recreated"), true
+}
+
+// runProvisioningSteps runs steps which haven't succeeded yet, and records their results in a status.
+func runProvisioningSteps(
+	ctx context.Context,
+	sshClient *cryptossh.Client,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+	steps []ssh.Step,
+) (*kubernetesimalv1alpha1.EtcdNodeStatus, error) {
+	completed := make(map[string]bool)
+	for i := range status.ProvisioningSteps {
+		if status.ProvisioningSteps[i].Phase == kubernetesimalv1alpha1.EtcdNodeProvisioningStepPhaseSucceeded {
+			completed[status.ProvisioningSteps[i].Name] = true
+		}
+	}
+
+	results, err := ssh.RunSteps(ctx, sshClient, steps, completed)
+	now := metav1.Now()
+	for i := range results {
+		step := kubernetesimalv1alpha1.EtcdNodeProvisioningStep{
+			Name:        results[i].Name,
+			Phase:       kubernetesimalv1alpha1.EtcdNodeProvisioningStepPhaseSucceeded,
+			Output:      results[i].Output,
+			LastRunTime: &now,
+		}
+		if results[i].ExitCode != nil {
+			exitCode := int32(*results[i].ExitCode)
+			step.ExitCode = &exitCode
+		}
+		if results[i].Err != nil {
+			step.Phase = kubernetesimalv1alpha1.EtcdNodeProvisioningStepPhaseFailed
+			step.Message = results[i].Err.Error()
+		}
+		status.WithProvisioningStep(step).DeepCopyInto(status)
+	}
+	return status, err
+}
@@ -0,0 +1,86 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdnode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+)
+
+func TestObserveVirtualMachineInstanceUID(t *testing.T) {
+	newProvisionedStatus := func(uid types.UID) *kubernetesimalv1alpha1.EtcdNodeStatus {
+		status := &kubernetesimalv1alpha1.EtcdNodeStatus{
+			VirtualMachineInstanceUID: uid,
+			ProvisioningSteps: []kubernetesimalv1alpha1.EtcdNodeProvisioningStep{
+				{
+					Name:  provisioningStepInstallBinaries,
+					Phase: kubernetesimalv1alpha1.EtcdNodeProvisioningStepPhaseSucceeded,
+				},
+			},
+		}
+		return status.WithProvisioned(true, "").WithReady(true, "")
+	}
+
+	for _, tc := range []struct {
+		name                string
+		status              *kubernetesimalv1alpha1.EtcdNodeStatus
+		uid                 types.UID
+		expectedReset       bool
+		expectedProvisioned bool
+	}{
+		{
+			name:                "a VirtualMachineInstance is observed for the first time",
+			status:              newProvisionedStatus(""),
+			uid:                 "vmi-1",
+			expectedProvisioned: true,
+		},
+		{
+			name:                "the same VirtualMachineInstance is observed",
+			status:              newProvisionedStatus("vmi-1"),
+			uid:                 "vmi-1",
+			expectedProvisioned: true,
+		},
+		{
+			name:          "a VirtualMachineInstance was recreated",
+			status:        newProvisionedStatus("vmi-1"),
+			uid:           "vmi-2",
+			expectedReset: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			status, reset := observeVirtualMachineInstanceUID(tc.status, tc.uid)
+			assert.Equal(t, tc.expectedReset, reset)
+			assert.Equal(t, tc.uid, status.VirtualMachineInstanceUID)
+			assert.Equal(t, tc.expectedProvisioned, status.IsProvisioned())
+			assert.Equal(t, tc.expectedProvisioned, status.IsProvisioningSucceeded())
+			assert.Equal(t, tc.expectedProvisioned, status.IsReady())
+			assert.Equal(t, tc.expectedProvisioned, len(status.ProvisioningSteps) > 0)
+		})
+	}
+}
@@ -53,6 +53,14 @@ type Reconciler struct {
 	Scheme *runtime.Scheme
 
 	Tracer trace.Tracer
+
+	// BackupVolumeDir is a directory where PersistentVolumeClaims for backups are mounted with their names.
+	BackupVolumeDir string
+
+	// DefaultArtifactMirrorURL is a URL of a mirror of release artifacts which is used by EtcdNodes that don't
+	// specify their own mirror but specify checksums of release artifacts. Release artifacts are downloaded from GitHub
+	// if it's empty.
+	DefaultArtifactMirrorURL string
 }
 
 //+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdnodes,verbs=get;list;watch;create;update;patch;delete
@@ -60,8 +68,14 @@ type Reconciler struct {
 //+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdnodes/finalizers,verbs=update
 //+kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachineinstances,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachineinstances/status,verbs=get
+//+kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachines,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=kubevirt.io,resources=virtualmachines/status,verbs=get
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdbackups,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdbackups/status,verbs=get
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -169,6 +183,12 @@ func (r *Reconciler) finalizeExternalResources(
 		status = newStatus
 	}
 
+	if newStatus, err := finalizeDataVolumeClaim(ctx, r.Client, obj, status); err != nil {
+		return newStatus, err
+	} else {
+		status = newStatus
+	}
+
 	return status, nil
 }
 
@@ -189,27 +209,92 @@ func (r *Reconciler) reconcileExternalResources(
 		status.PeerServiceRef = serviceRef
 	}
 
-	if userDataRef, err := reconcileUserData(ctx, r.Client, r.Scheme, obj, spec, status); err != nil {
+	if dataVolumeClaimRef, err := reconcileDataVolumeClaim(ctx, r.Client, r.Scheme, obj, spec, status); err != nil {
+		return status, fmt.Errorf("unable to prepare a data volume: %w", err)
+	} else {
+		status.DataVolumeClaimRef = dataVolumeClaimRef
+	}
+
+	if sshHostKeyRef, err := reconcileSSHHostKey(ctx, r.Client, r.Scheme, obj, spec, status); err != nil {
+		return status, fmt.Errorf("unable to prepare an SSH host key: %w", err)
+	} else {
+		status.SSHHostKeyRef = sshHostKeyRef
+	}
+
+	if userDataRef, err := reconcileUserData(
+		ctx,
+		r.Client,
+		r.Scheme,
+		obj,
+		spec,
+		status,
+		r.DefaultArtifactMirrorURL,
+	); err != nil {
 		return status, fmt.Errorf("unable to prepare a userdata: %w", err)
 	} else {
 		status.UserDataRef = userDataRef
 	}
 
-	if vmiRef, err := reconcileVirtualMachineInstance(ctx, r.Client, r.Scheme, obj, spec, status); err != nil {
-		return status, fmt.Errorf("unable to prepare a virtual machine instance: %w", err)
+	if spec.RunStrategy != nil {
+		if vmRef, err := reconcileVirtualMachine(ctx, r.Client, r.Scheme, obj, spec, status); err != nil {
+			return status, fmt.Errorf("unable to prepare a virtual machine: %w", err)
+		} else {
+			status.VirtualMachineRef = vmRef
+			status.VirtualMachineInstanceRef = &corev1.LocalObjectReference{
+				Name: vmRef.Name,
+			}
+		}
 	} else {
-		status.VirtualMachineInstanceRef = vmiRef
+		if vmiRef, err := reconcileVirtualMachineInstance(ctx, r.Client, r.Scheme, obj, spec, status); err != nil {
+			return status, fmt.Errorf("unable to prepare a virtual machine instance: %w", err)
+		} else {
+			status.VirtualMachineInstanceRef = vmiRef
+		}
+	}
+
+	if newStatus, err := reconcileProvisionedVirtualMachineInstance(ctx, r.Client, obj, status); err != nil {
+		return newStatus, err
+	} else {
+		status = newStatus
+	}
+
+	// A member which was restored from a snapshot once is provisioned as usual on a recreated virtual machine since it
+	// has its data already.
+	if !status.IsProvisioned() && spec.AsFirstNode && spec.SnapshotSource != nil &&
+		(status.Restore == nil || status.Restore.Phase != kubernetesimalv1alpha1.EtcdRestorePhaseCompleted) {
+		if newStatus, err := restoreEtcdMember(ctx, r.Client, obj, spec, status, r.BackupVolumeDir); err != nil {
+			newStatus.WithProvisioned(false, err.Error()).DeepCopyInto(newStatus)
+			return newStatus, fmt.Errorf("unable to restore an etcd member from a snapshot: %w", err)
+		} else {
+			status = newStatus
+		}
+		status.WithProvisioned(true, "").DeepCopyInto(status)
+		logger.Info("Provisioning an etcd member from a snapshot was completed.")
 	}
 
 	if !status.IsProvisioned() {
-		if err := provisionEtcdMember(ctx, r.Client, obj, spec, status); err != nil {
-			status.WithProvisioned(false, err.Error()).DeepCopyInto(status)
-			return status, fmt.Errorf("unable to provision an etcd member: %w", err)
+		if newStatus, err := provisionEtcdMember(ctx, r.Client, obj, spec, status); err != nil {
+			newStatus.WithProvisioned(false, err.Error()).DeepCopyInto(newStatus)
+			return newStatus, fmt.Errorf("unable to provision an etcd member: %w", err)
+		} else {
+			status = newStatus
 		}
 		status.WithProvisioned(true, "").DeepCopyInto(status)
 		logger.Info("Provisioning an etcd member was completed.")
 	}
 
+	if newStatus, err := reconcileForceNewCluster(ctx, r.Client, obj, spec, status, r.BackupVolumeDir); err != nil {
+		return newStatus, fmt.Errorf("unable to form a new etcd cluster: %w", err)
+	} else {
+		status = newStatus
+	}
+
+	if newStatus, err := reconcileMemberCertificates(ctx, r.Client, obj, spec, status); err != nil {
+		return newStatus, fmt.Errorf("unable to reconcile certificates of an etcd member: %w", err)
+	} else {
+		status = newStatus
+	}
+
 	return status, nil
 }
 
@@ -223,6 +308,8 @@ func (r *Reconciler) updateStatus(
 	switch {
 	case !en.ObjectMeta.DeletionTimestamp.IsZero():
 		status.Phase = kubernetesimalv1alpha1.EtcdNodePhaseDeleting
+	case status.Learner:
+		status.Phase = kubernetesimalv1alpha1.EtcdNodePhaseLearner
 	case status.IsReady():
 		status.Phase = kubernetesimalv1alpha1.EtcdNodePhaseRunning
 	case status.IsReadyOnce():
@@ -263,6 +350,14 @@ func (r *Reconciler) SetupWithManager(mgr ctrl.Manager) error {
 			&corev1.Service{},
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
 		).
+		Owns(
+			&corev1.PersistentVolumeClaim{},
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Owns(
+			&kubevirtv1.VirtualMachine{},
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
 		Owns(
 			&kubevirtv1.VirtualMachineInstance{},
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
@@ -0,0 +1,138 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdnode
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/observability/tracing"
+	"github.com/kkohtaka/kubernetesimal/ssh"
+)
+
+// reconcileForceNewCluster restarts an etcd member as the only member of a new etcd cluster when it's requested with
+// the force-new-cluster annotation. The member keeps its data with --force-new-cluster, or is restored from a snapshot
+// of an EtcdBackup specified with the force-new-cluster-backup annotation.
+func reconcileForceNewCluster(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+	backupVolumeDir string,
+) (*kubernetesimalv1alpha1.EtcdNodeStatus, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "reconcileForceNewCluster")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	request := obj.GetAnnotations()[kubernetesimalv1alpha1.EtcdNodeForceNewClusterAnnotation]
+	if request == "" || request == status.ObservedForceNewCluster {
+		return status, nil
+	}
+	if !status.IsProvisioned() {
+		return status, fmt.Errorf("an etcd member which isn't provisioned can't form a new etcd cluster")
+	}
+
+	sshClient, closer, err := startSSHConnectionToEtcdMember(ctx, c, obj, spec, status)
+	if err != nil {
+		return status, err
+	}
+	defer closer()
+
+	backupName := obj.GetAnnotations()[kubernetesimalv1alpha1.EtcdNodeForceNewClusterBackupAnnotation]
+	var advertiseAddress string
+	if backupName != "" {
+		if advertiseAddress, err = getMemberAdvertiseAddress(ctx, c, obj, status); err != nil {
+			return status, err
+		}
+		if err := transferSnapshot(
+			ctx,
+			c,
+			sshClient,
+			obj,
+			&kubernetesimalv1alpha1.EtcdSnapshotSource{
+				EtcdBackupRef: &corev1.LocalObjectReference{
+					Name: backupName,
+				},
+			},
+			backupVolumeDir,
+		); err != nil {
+			return status, err
+		}
+		logger.Info("A snapshot was transferred to an etcd member.", "backup", backupName)
+	}
+
+	scriptBuf := bytes.Buffer{}
+	scriptTmpl, err := template.New("force-new-cluster.sh.tmpl").Funcs(sprig.FuncMap()).ParseFS(
+		cloudConfigTemplates,
+		"templates/force-new-cluster.sh.tmpl",
+	)
+	if err != nil {
+		return status, fmt.Errorf("unable to parse a template of force-new-cluster.sh: %w", err)
+	}
+	if err := scriptTmpl.Execute(
+		&scriptBuf,
+		&struct {
+			ServiceName      string
+			DataDir          string
+			SnapshotPath     string
+			FromSnapshot     bool
+			AdvertiseAddress string
+		}{
+			ServiceName:      status.PeerServiceRef.Name,
+			DataDir:          etcdDataDir,
+			SnapshotPath:     snapshotPath,
+			FromSnapshot:     backupName != "",
+			AdvertiseAddress: advertiseAddress,
+		},
+	); err != nil {
+		return status, fmt.Errorf("unable to render force-new-cluster.sh from a template: %w", err)
+	}
+
+	// The script is passed over SSH since virtual machines created before it was introduced don't have it.
+	if err := ssh.RunCommandWithInputOverSSHSession(ctx, sshClient, "sudo bash -s", &scriptBuf); err != nil {
+		return status, fmt.Errorf("unable to form a new etcd cluster: %w", err)
+	}
+	logger.Info("An etcd member formed a new etcd cluster.", "request", request, "backup", backupName)
+
+	newStatus := status.DeepCopy()
+	newStatus.ObservedForceNewCluster = request
+	newStatus.Learner = false
+	if backupName != "" {
+		// A member restored from a snapshot has a new member ID.
+		newStatus.MemberID = ""
+	}
+	return newStatus, nil
+}
@@ -0,0 +1,264 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdnode
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	cryptossh "golang.org/x/crypto/ssh"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	k8s_etcdbackup "github.com/kkohtaka/kubernetesimal/k8s/etcdbackup"
+	"github.com/kkohtaka/kubernetesimal/observability/tracing"
+	"github.com/kkohtaka/kubernetesimal/ssh"
+)
+
+const (
+	snapshotPath = "/var/lib/kubernetesimal/snapshot.db"
+
+	snapshotDownloadTimeout = 10 * time.Minute
+)
+
+// snapshotHTTPClient is an HTTP client to download snapshots. A download is bounded by a timeout so that an
+// unresponsive server doesn't block reconciliation.
+var snapshotHTTPClient = &http.Client{
+	Timeout: snapshotDownloadTimeout,
+}
+
+// restoreEtcdMember restores the first member of an etcd cluster from a snapshot. A snapshot is transferred to a
+// virtual machine over SSH, and then the member is restored with etcdutl before being initialized with etcdadm.
+func restoreEtcdMember(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+	backupVolumeDir string,
+) (*kubernetesimalv1alpha1.EtcdNodeStatus, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "restoreEtcdMember")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	if status.Restore == nil {
+		now := metav1.Now()
+		status.Restore = &kubernetesimalv1alpha1.EtcdRestoreStatus{
+			Phase:     kubernetesimalv1alpha1.EtcdRestorePhaseTransferring,
+			StartTime: &now,
+		}
+	}
+
+	if status.Restore.Phase == kubernetesimalv1alpha1.EtcdRestorePhaseCompleted {
+		return status, nil
+	}
+
+	client, closer, err := startSSHConnectionToEtcdMember(ctx, c, obj, spec, status)
+	if err != nil {
+		status.Restore.Message = err.Error()
+		return status, err
+	}
+	defer closer()
+
+	switch status.Restore.Phase {
+	case kubernetesimalv1alpha1.EtcdRestorePhaseTransferring:
+		if err := transferSnapshot(ctx, c, client, obj, spec.SnapshotSource, backupVolumeDir); err != nil {
+			status.Restore.Message = err.Error()
+			return status, err
+		}
+		logger.Info("A snapshot was transferred to an etcd member.")
+		status.Restore.Phase = kubernetesimalv1alpha1.EtcdRestorePhaseRestoring
+		status.Restore.Message = ""
+		return status, errors.NewRequeueError("a snapshot was transferred").WithDelay(time.Second)
+
+	case kubernetesimalv1alpha1.EtcdRestorePhaseRestoring:
+		address, err := getMemberAdvertiseAddress(ctx, c, obj, status)
+		if err != nil {
+			status.Restore.Message = err.Error()
+			return status, err
+		}
+		if newStatus, err := runProvisioningSteps(
+			ctx,
+			client,
+			status,
+			newProvisioningSteps(
+				c,
+				obj,
+				spec,
+				status,
+				provisioningStepRestoreCluster,
+				fmt.Sprintf("sudo /opt/bin/restore-cluster.sh %s", address),
+			),
+		); err != nil {
+			newStatus.Restore.Message = err.Error()
+			return newStatus, err
+		} else {
+			status = newStatus
+		}
+		revision, err := getSnapshotRevision(ctx, client)
+		if err != nil {
+			status.Restore.Message = err.Error()
+			return status, err
+		}
+		logger.Info("An etcd member was restored from a snapshot.", "revision", revision)
+		now := metav1.Now()
+		status.Restore.Phase = kubernetesimalv1alpha1.EtcdRestorePhaseCompleted
+		status.Restore.CompletionTime = &now
+		status.Restore.RestoredRevision = revision
+		status.Restore.Message = ""
+	}
+	return status, nil
+}
+
+func transferSnapshot(
+	ctx context.Context,
+	c client.Client,
+	sshClient *cryptossh.Client,
+	obj client.Object,
+	source *kubernetesimalv1alpha1.EtcdSnapshotSource,
+	backupVolumeDir string,
+) error {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "transferSnapshot")
+	defer span.End()
+
+	rc, expectedHash, err := openSnapshot(ctx, c, obj, source, backupVolumeDir)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	hash := sha256.New()
+	if err := ssh.RunCommandWithInputOverSSHSession(
+		ctx,
+		sshClient,
+		fmt.Sprintf("sudo install -D -m 0600 /dev/stdin %s.tmp", snapshotPath),
+		io.TeeReader(rc, hash),
+	); err != nil {
+		return fmt.Errorf("unable to transfer a snapshot: %w", err)
+	}
+	if actualHash := hex.EncodeToString(hash.Sum(nil)); expectedHash != "" && actualHash != expectedHash {
+		return fmt.Errorf("a hash of a snapshot %q doesn't match the expected one %q", actualHash, expectedHash)
+	}
+	if err := ssh.RunCommandOverSSHSession(
+		ctx,
+		sshClient,
+		fmt.Sprintf("sudo mv %s.tmp %s", snapshotPath, snapshotPath),
+	); err != nil {
+		return fmt.Errorf("unable to place a snapshot: %w", err)
+	}
+	return nil
+}
+
+func openSnapshot(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	source *kubernetesimalv1alpha1.EtcdSnapshotSource,
+	backupVolumeDir string,
+) (io.ReadCloser, string, error) {
+	switch {
+	case source.EtcdBackupRef != nil:
+		var backup kubernetesimalv1alpha1.EtcdBackup
+		key := types.NamespacedName{
+			Namespace: obj.GetNamespace(),
+			Name:      source.EtcdBackupRef.Name,
+		}
+		if err := c.Get(ctx, key, &backup); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, "", errors.NewRequeueError("waiting for an EtcdBackup created").
+					Wrap(err).
+					WithDelay(10 * time.Second)
+			}
+			return nil, "", fmt.Errorf("unable to get EtcdBackup %s: %w", key, err)
+		}
+		switch backup.Status.Phase {
+		case kubernetesimalv1alpha1.EtcdBackupPhaseCompleted:
+		case kubernetesimalv1alpha1.EtcdBackupPhaseFailed:
+			return nil, "", fmt.Errorf("EtcdBackup %s was failed", key)
+		default:
+			return nil, "", errors.NewRequeueError("waiting for an EtcdBackup completed").WithDelay(10 * time.Second)
+		}
+
+		sink, err := k8s_etcdbackup.GetSink(ctx, c, backup.Namespace, &backup.Spec.Sink, backupVolumeDir)
+		if err != nil {
+			return nil, "", fmt.Errorf("unable to prepare a sink of EtcdBackup %s: %w", key, err)
+		}
+		rc, err := sink.Open(ctx, k8s_etcdbackup.NewSnapshotKey(&backup, &backup.Spec))
+		if err != nil {
+			return nil, "", fmt.Errorf("unable to open a snapshot of EtcdBackup %s: %w", key, err)
+		}
+		return rc, backup.Status.Hash, nil
+
+	case source.URL != "":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.URL, nil)
+		if err != nil {
+			return nil, "", fmt.Errorf("unable to create a request to %s: %w", source.URL, err)
+		}
+		resp, err := snapshotHTTPClient.Do(req)
+		if err != nil {
+			return nil, "", fmt.Errorf("unable to download a snapshot from %s: %w", source.URL, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, "", fmt.Errorf("unable to download a snapshot from %s: %s", source.URL, resp.Status)
+		}
+		return resp.Body, source.SHA256, nil
+
+	default:
+		return nil, "", fmt.Errorf("no snapshot source is specified")
+	}
+}
+
+func getSnapshotRevision(ctx context.Context, client *cryptossh.Client) (int64, error) {
+	out, err := ssh.OutputCommandOverSSHSession(
+		ctx,
+		client,
+		fmt.Sprintf("sudo /usr/local/bin/etcdutl snapshot status %s --write-out=json", snapshotPath),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("unable to get a status of a snapshot: %w", err)
+	}
+	var snapshotStatus struct {
+		Revision int64 `json:"revision"`
+	}
+	if err := json.Unmarshal(out, &snapshotStatus); err != nil {
+		return 0, fmt.Errorf("unable to parse a status of a snapshot: %w", err)
+	}
+	return snapshotStatus.Revision, nil
+}
@@ -62,13 +62,18 @@ func reconcilePeerService(
 	c client.Client,
 	scheme *runtime.Scheme,
 	obj client.Object,
-	_ *kubernetesimalv1alpha1.EtcdNodeSpec,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
 	_ *kubernetesimalv1alpha1.EtcdNodeStatus,
 ) (*corev1.LocalObjectReference, error) {
 	var span trace.Span
 	ctx, span = tracing.FromContext(ctx).Start(ctx, "reconcileService")
 	defer span.End()
 
+	sshContainerPort := int32(serviceContainerPortSSH)
+	if spec.SSH != nil && spec.SSH.Port != nil {
+		sshContainerPort = *spec.SSH.Port
+	}
+
 	if service, err := k8s_service.Reconcile(
 		ctx,
 		obj,
@@ -79,7 +84,7 @@ func reconcilePeerService(
 		k8s_service.WithType(corev1.ServiceTypeNodePort),
 		k8s_service.WithPort(serviceNameEtcd, servicePortEtcd, serviceContainerPortEtcd),
 		k8s_service.WithPort(serviceNamePeer, servicePortPeer, serviceContainerPortPeer),
-		k8s_service.WithPort(serviceNameSSH, servicePortSSH, serviceContainerPortSSH),
+		k8s_service.WithPort(serviceNameSSH, servicePortSSH, sshContainerPort),
 		k8s_service.WithSelector("app.kubernetes.io/name", "virtualmachineimage"),
 		k8s_service.WithSelector("app.kubernetes.io/instance", newVirtualMachineInstanceName(obj)),
 		k8s_service.WithSelector("app.kubernetes.io/part-of", "etcd"),
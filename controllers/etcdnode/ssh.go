@@ -0,0 +1,215 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdnode
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	k8s_object "github.com/kkohtaka/kubernetesimal/k8s/object"
+	k8s_secret "github.com/kkohtaka/kubernetesimal/k8s/secret"
+	"github.com/kkohtaka/kubernetesimal/observability/tracing"
+	"github.com/kkohtaka/kubernetesimal/ssh"
+)
+
+const (
+	sshHostKeyKeyPrivateKey = "ssh-privatekey"
+	sshHostKeyKeyPublicKey  = "ssh-publickey"
+)
+
+func newSSHHostKeyName(obj client.Object) string {
+	return "ssh-hostkey-" + obj.GetName()
+}
+
+// reconcileSSHHostKey prepares a Secret which holds an SSH host key of a virtual machine, and returns a reference to
+// its public key.
+func reconcileSSHHostKey(
+	ctx context.Context,
+	c client.Client,
+	scheme *runtime.Scheme,
+	obj client.Object,
+	_ *kubernetesimalv1alpha1.EtcdNodeSpec,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+) (*corev1.SecretKeySelector, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "reconcileSSHHostKey")
+	defer span.End()
+
+	if status.SSHHostKeyRef != nil {
+		if name := status.SSHHostKeyRef.LocalObjectReference.Name; name != newSSHHostKeyName(obj) {
+			return nil, fmt.Errorf("invalid Secret name %s to store an SSH host key", name)
+		}
+
+		var hostKey corev1.Secret
+		if err := c.Get(
+			ctx,
+			types.NamespacedName{Namespace: obj.GetNamespace(), Name: status.SSHHostKeyRef.Name},
+			&hostKey,
+		); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("unable to get a Secret for an SSH host key: %w", err)
+			}
+		} else {
+			_, hasPrivateKey := hostKey.Data[sshHostKeyKeyPrivateKey]
+			_, hasPublicKey := hostKey.Data[status.SSHHostKeyRef.Key]
+			if hasPrivateKey && hasPublicKey {
+				return status.SSHHostKeyRef, nil
+			}
+		}
+	}
+
+	privateKey, publicKey, err := ssh.GenerateKeyPair(func(o *ssh.KeyPairOption) {
+		o.Type = ssh.KeyTypeEd25519
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create an SSH host key: %w", err)
+	}
+	if secret, err := k8s_secret.CreateOnlyIfNotExist(
+		ctx,
+		obj,
+		c,
+		newSSHHostKeyName(obj),
+		obj.GetNamespace(),
+		k8s_object.WithOwner(obj, scheme),
+		k8s_secret.WithDataWithKey(sshHostKeyKeyPrivateKey, privateKey),
+		k8s_secret.WithDataWithKey(sshHostKeyKeyPublicKey, publicKey),
+	); err != nil {
+		return nil, fmt.Errorf("unable to prepare a Secret for an SSH host key: %w", err)
+	} else {
+		return &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: secret.Name,
+			},
+			Key: sshHostKeyKeyPublicKey,
+		}, nil
+	}
+}
+
+// getSSHHostKey returns a private and a public SSH host key of a virtual machine.
+func getSSHHostKey(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+) ([]byte, []byte, error) {
+	if status.SSHHostKeyRef == nil {
+		return nil, nil, errors.NewRequeueError("waiting for an SSH host key prepared")
+	}
+
+	var hostKey corev1.Secret
+	if err := c.Get(
+		ctx,
+		types.NamespacedName{Namespace: obj.GetNamespace(), Name: status.SSHHostKeyRef.Name},
+		&hostKey,
+	); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, errors.NewRequeueError("waiting for an SSH host key prepared").Wrap(err)
+		}
+		return nil, nil, fmt.Errorf("unable to get a Secret for an SSH host key: %w", err)
+	}
+	return hostKey.Data[sshHostKeyKeyPrivateKey], bytes.TrimSpace(hostKey.Data[status.SSHHostKeyRef.Key]), nil
+}
+
+// newSSHHosts returns an SSH server of a virtual machine exposed by the specified Service, and jump hosts which a
+// connection to the virtual machine goes through.
+func newSSHHosts(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+	privateKey []byte,
+	peerService *corev1.Service,
+) (*ssh.Host, []*ssh.Host, error) {
+	var port int32
+	for i := range peerService.Spec.Ports {
+		if peerService.Spec.Ports[i].Name == serviceNameSSH {
+			port = peerService.Spec.Ports[i].Port
+			break
+		}
+	}
+	if port == 0 {
+		return nil, nil, errors.NewRequeueError("waiting for an SSH port of the etcd peer Service prepared")
+	}
+
+	_, hostKey, err := getSSHHostKey(ctx, c, obj, status)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	host := &ssh.Host{
+		Address:    peerService.Spec.ClusterIP,
+		Port:       int(port),
+		PrivateKey: privateKey,
+		HostKey:    hostKey,
+		User:       getLoginUser(spec),
+	}
+	if spec.SSH == nil {
+		return host, nil, nil
+	}
+
+	var jumpHosts []*ssh.Host
+	for i := range spec.SSH.JumpHosts {
+		jumpHost := &spec.SSH.JumpHosts[i]
+		h := &ssh.Host{
+			Address:    jumpHost.Address,
+			User:       jumpHost.User,
+			PrivateKey: privateKey,
+		}
+		if jumpHost.Port != nil {
+			h.Port = int(*jumpHost.Port)
+		}
+		if jumpHost.PrivateKeyRef != nil {
+			if h.PrivateKey, err = k8s_secret.GetValueFromSecretKeySelector(
+				ctx,
+				c,
+				obj.GetNamespace(),
+				jumpHost.PrivateKeyRef,
+			); err != nil {
+				return nil, nil, fmt.Errorf("unable to get an SSH private key of a jump host %s: %w", jumpHost.Address, err)
+			}
+		}
+		if h.HostKey, err = k8s_secret.GetValueFromSecretKeySelector(
+			ctx,
+			c,
+			obj.GetNamespace(),
+			&jumpHost.HostKeyRef,
+		); err != nil {
+			return nil, nil, fmt.Errorf("unable to get a host key of a jump host %s: %w", jumpHost.Address, err)
+		}
+		jumpHosts = append(jumpHosts, h)
+	}
+	return host, jumpHosts, nil
+}
@@ -0,0 +1,185 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdnode
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"go.opentelemetry.io/otel/trace"
+	cryptossh "golang.org/x/crypto/ssh"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	"github.com/kkohtaka/kubernetesimal/controller/etcdclient"
+	"github.com/kkohtaka/kubernetesimal/observability/tracing"
+	"github.com/kkohtaka/kubernetesimal/ssh"
+)
+
+const (
+	etcdBinaryPath      = "/usr/local/bin/etcd"
+	etcdConfigPath      = "/etc/etcd/etcd.env"
+	etcdSystemdUnitPath = "/etc/systemd/system/etcd.service"
+
+	initialClusterStateNew      = "new"
+	initialClusterStateExisting = "existing"
+)
+
+// configureEtcdMember renders a systemd unit and a configuration file of etcd, and places them on an etcd member over
+// SSH. A member which joins an existing etcd cluster is added to the cluster with the etcd API in advance, so that it
+// starts with the initial cluster including itself.
+func configureEtcdMember(
+	ctx context.Context,
+	c client.Client,
+	sshClient *cryptossh.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+) error {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "configureEtcdMember")
+	defer span.End()
+
+	address, err := getMemberAdvertiseAddress(ctx, c, obj, status)
+	if err != nil {
+		return err
+	}
+	name := status.PeerServiceRef.Name
+	peerURL := newMemberURL(address, serviceContainerPortPeer)
+
+	initialCluster := name + "=" + peerURL
+	initialClusterState := initialClusterStateNew
+	if !spec.AsFirstNode {
+		etcdClient, err := etcdclient.New(
+			ctx,
+			c,
+			obj.GetNamespace(),
+			&spec.ServiceRef,
+			etcdclient.CredentialsFromEtcdNodeSpec(spec),
+		)
+		if err != nil {
+			return err
+		}
+		defer etcdClient.Close()
+
+		if initialCluster, err = addEtcdMember(ctx, etcdClient, name, peerURL, joinsAsLearner(spec)); err != nil {
+			return err
+		}
+		initialClusterState = initialClusterStateExisting
+	}
+
+	// In the Required mode of peer mTLS, etcd members accept only peers presenting the peer certificate identity.
+	var peerCertAllowedCN string
+	if spec.PeerMTLS == kubernetesimalv1alpha1.EtcdPeerMTLSModeRequired {
+		if peerCertAllowedCN, err = getPeerCommonName(ctx, c, obj, spec); err != nil {
+			return err
+		}
+		if peerCertAllowedCN == "" {
+			return errors.NewRequeueError("waiting for a peer certificate prepared")
+		}
+	}
+
+	configBuf := bytes.Buffer{}
+	configTmpl, err := template.New("etcd.env.tmpl").Funcs(sprig.FuncMap()).ParseFS(
+		cloudConfigTemplates,
+		"templates/etcd.env.tmpl",
+	)
+	if err != nil {
+		return fmt.Errorf("unable to parse a template of etcd.env: %w", err)
+	}
+	if err := configTmpl.Execute(
+		&configBuf,
+		&struct {
+			Name                string
+			DataDir             string
+			ListenClientURL     string
+			ClientURL           string
+			ListenPeerURL       string
+			PeerURL             string
+			InitialCluster      string
+			InitialClusterState string
+			PKIDir              string
+			PeerCertAllowedCN   string
+			ConfigEnv           []etcdEnvVar
+		}{
+			Name:                name,
+			DataDir:             etcdDataDir,
+			ListenClientURL:     newMemberURL("0.0.0.0", serviceContainerPortEtcd),
+			ClientURL:           newMemberURL(address, serviceContainerPortEtcd),
+			ListenPeerURL:       newMemberURL("0.0.0.0", serviceContainerPortPeer),
+			PeerURL:             peerURL,
+			InitialCluster:      initialCluster,
+			InitialClusterState: initialClusterState,
+			PKIDir:              etcdPKIDir,
+			PeerCertAllowedCN:   peerCertAllowedCN,
+			ConfigEnv:           newEtcdConfigEnv(spec),
+		},
+	); err != nil {
+		return fmt.Errorf("unable to render etcd.env from a template: %w", err)
+	}
+
+	unitBuf := bytes.Buffer{}
+	unitTmpl, err := template.New("etcd.service.tmpl").Funcs(sprig.FuncMap()).ParseFS(
+		cloudConfigTemplates,
+		"templates/etcd.service.tmpl",
+	)
+	if err != nil {
+		return fmt.Errorf("unable to parse a template of etcd.service: %w", err)
+	}
+	if err := unitTmpl.Execute(
+		&unitBuf,
+		&struct {
+			ConfigPath string
+			EtcdPath   string
+		}{
+			ConfigPath: etcdConfigPath,
+			EtcdPath:   etcdBinaryPath,
+		},
+	); err != nil {
+		return fmt.Errorf("unable to render etcd.service from a template: %w", err)
+	}
+
+	for _, file := range []struct {
+		path    string
+		content []byte
+	}{
+		{path: etcdConfigPath, content: configBuf.Bytes()},
+		{path: etcdSystemdUnitPath, content: unitBuf.Bytes()},
+	} {
+		if err := ssh.RunCommandWithInputOverSSHSession(
+			ctx,
+			sshClient,
+			fmt.Sprintf("sudo install -D -m 0644 /dev/stdin %s", file.path),
+			bytes.NewReader(bytes.TrimLeft(file.content, "\n")),
+		); err != nil {
+			return fmt.Errorf("unable to place %s: %w", file.path, err)
+		}
+	}
+	return nil
+}
@@ -37,6 +37,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -49,6 +50,7 @@ import (
 	"github.com/kkohtaka/kubernetesimal/controller/finalizer"
 	k8s_object "github.com/kkohtaka/kubernetesimal/k8s/object"
 	k8s_secret "github.com/kkohtaka/kubernetesimal/k8s/secret"
+	k8s_vm "github.com/kkohtaka/kubernetesimal/k8s/vm"
 	k8s_vmi "github.com/kkohtaka/kubernetesimal/k8s/vmi"
 	"github.com/kkohtaka/kubernetesimal/observability/tracing"
 )
@@ -60,6 +62,16 @@ var (
 
 const (
 	defaultEtcdadmReleaseURL = "https://github.com/kubernetes-sigs/etcdadm/releases/download"
+
+	defaultEtcdReleaseURL = "https://github.com/etcd-io/etcd/releases/download"
+
+	// etcdadmCacheDir is a directory where etcdadm looks for release tarballs of etcd before downloading them.
+	etcdadmCacheDir = "/var/cache/etcdadm/etcd"
+
+	etcdDataDir = "/var/lib/etcd"
+
+	// etcdDataLabel is a label of a filesystem of a data volume of etcd.
+	etcdDataLabel = "etcd-data"
 )
 
 var (
@@ -68,6 +80,11 @@ var (
 	defaultEtcdVersion = "3.5.1"
 )
 
+const (
+	// clusterLabelKey is a key of a label that identifies an etcd cluster which a VirtualMachineInstance belongs to.
+	clusterLabelKey = "etcdnode.kubernetesimal.kkohtaka.org/cluster"
+)
+
 func newUserDataName(obj client.Object) string {
 	return "userdata-" + obj.GetName()
 }
@@ -83,6 +100,7 @@ func reconcileUserData(
 	obj client.Object,
 	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
 	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+	defaultArtifactMirrorURL string,
 ) (*corev1.LocalObjectReference, error) {
 	var span trace.Span
 	ctx, span = tracing.FromContext(ctx).Start(ctx, "reconcileUserData")
@@ -114,17 +132,27 @@ func reconcileUserData(
 		return nil, fmt.Errorf("unable to get a CA certificate: %w", err)
 	}
 
-	caPrivateKey, err := k8s_secret.GetValueFromSecretKeySelector(
-		ctx,
-		c,
-		obj.GetNamespace(),
-		&spec.CAPrivateKeyRef,
-	)
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			return nil, errors.NewRequeueError("waiting for a CA private key prepared").Wrap(err)
+	// The CA private key is placed on a virtual machine only if etcdadm signs certificates of an etcd member.
+	var caPrivateKey string
+	if spec.MemberCertificateSigner != kubernetesimalv1alpha1.EtcdCertificateSignerController {
+		v, err := k8s_secret.GetValueFromSecretKeySelector(
+			ctx,
+			c,
+			obj.GetNamespace(),
+			&spec.CAPrivateKeyRef,
+		)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, errors.NewRequeueError("waiting for a CA private key prepared").Wrap(err)
+			}
+			return nil, fmt.Errorf("unable to get a CA private key: %w", err)
 		}
-		return nil, fmt.Errorf("unable to get a CA private key: %w", err)
+		caPrivateKey = base64.StdEncoding.EncodeToString(v)
+	}
+
+	sshHostPrivateKey, sshHostPublicKey, err := getSSHHostKey(ctx, c, obj, status)
+	if err != nil {
+		return nil, err
 	}
 
 	var loginPassword string
@@ -182,11 +210,119 @@ func reconcileUserData(
 		return nil, errors.NewRequeueError("waiting for a cluster IP of the etcd peer Service prepared")
 	}
 
+	// In the Required mode of peer mTLS, etcd members accept only peers presenting the peer certificate identity.
+	var peerCertAllowedCN string
+	if spec.PeerMTLS == kubernetesimalv1alpha1.EtcdPeerMTLSModeRequired {
+		if peerCertAllowedCN, err = getPeerCommonName(ctx, c, obj, spec); err != nil {
+			return nil, err
+		}
+		if peerCertAllowedCN == "" {
+			return nil, errors.NewRequeueError("waiting for a peer certificate prepared")
+		}
+	}
+
 	etcdVersion := spec.Version
 	if etcdVersion == "" {
 		etcdVersion = defaultEtcdVersion
 	}
 
+	etcdadmReleaseURL, etcdReleaseURL := defaultEtcdadmReleaseURL, defaultEtcdReleaseURL
+	var etcdadmSHA256, etcdSHA256 string
+	if spec.Artifacts != nil {
+		etcdadmSHA256 = spec.Artifacts.EtcdadmSHA256
+		etcdSHA256 = spec.Artifacts.EtcdSHA256
+	}
+	// Release artifacts from a mirror, including the artifact server of the controller, are installed only if they're
+	// verified with checksums. Without checksums, release artifacts are downloaded from GitHub instead of the artifact
+	// server of the controller.
+	withEtcdadm := spec.BootstrapDriver != kubernetesimalv1alpha1.EtcdBootstrapDriverSystemd
+	withChecksums := etcdSHA256 != "" && (!withEtcdadm || etcdadmSHA256 != "")
+	var mirrorURL string
+	switch {
+	case spec.Artifacts != nil && spec.Artifacts.MirrorURL != "":
+		mirrorURL = spec.Artifacts.MirrorURL
+		if !withChecksums {
+			return nil, fmt.Errorf("checksums of release artifacts must be specified to download them from %s", mirrorURL)
+		}
+	case spec.Artifacts != nil && spec.Artifacts.DisableDefaultMirror:
+	case withChecksums:
+		mirrorURL = defaultArtifactMirrorURL
+	}
+	if mirrorURL != "" {
+		etcdadmReleaseURL = strings.TrimSuffix(mirrorURL, "/") + "/etcdadm"
+		etcdReleaseURL = strings.TrimSuffix(mirrorURL, "/") + "/etcd"
+	}
+
+	// With the Systemd bootstrap driver, etcd binaries are installed directly instead of etcdadm, and etcd is started
+	// as a systemd unit which the controller configures. etcdutl is installed on every node anyway since any node can
+	// be restored from a snapshot when an etcd cluster is recovered from a backup.
+	withSystemd := spec.BootstrapDriver == kubernetesimalv1alpha1.EtcdBootstrapDriverSystemd
+	etcdBinaries := []string{"etcdutl"}
+	if withSystemd {
+		etcdBinaries = []string{"etcd", "etcdctl", "etcdutl"}
+	}
+
+	// etcdadm doesn't support peer mTLS settings or arbitrary settings of etcd, so scripts update the configuration
+	// file which etcdadm generates with them. With the Systemd bootstrap driver, the controller renders them into the
+	// configuration file directly.
+	var etcdEnv []etcdEnvVar
+	if !withSystemd {
+		if peerCertAllowedCN != "" {
+			etcdEnv = append(etcdEnv,
+				newEtcdEnvVar("peer-client-cert-auth", "true"),
+				newEtcdEnvVar("peer-cert-allowed-cn", peerCertAllowedCN),
+			)
+		}
+		etcdEnv = append(etcdEnv, newEtcdConfigEnv(spec)...)
+	}
+
+	extraSANs := strings.Join(
+		[]string{
+			peerService.Spec.ClusterIP,
+			fmt.Sprintf("%s.%s.svc", peerService.Name, peerService.Namespace),
+			fmt.Sprintf("%s.%s", peerService.Name, peerService.Namespace),
+			service.Spec.ClusterIP,
+			fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace),
+			fmt.Sprintf("%s.%s", service.Name, service.Namespace),
+		},
+		",",
+	)
+
+	installBinariesScriptBuf := bytes.Buffer{}
+	installBinariesScriptTmpl, err := template.New("install-binaries.sh.tmpl").Funcs(sprig.FuncMap()).ParseFS(
+		cloudConfigTemplates,
+		"templates/install-binaries.sh.tmpl",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse a template of install-binaries.sh: %w", err)
+	}
+	if err := installBinariesScriptTmpl.Execute(
+		&installBinariesScriptBuf,
+		&struct {
+			EtcdadmReleaseURL string
+			EtcdadmVersion    string
+			EtcdadmSHA256     string
+			EtcdReleaseURL    string
+			EtcdVersion       string
+			EtcdSHA256        string
+			EtcdCacheDir      string
+			EtcdBinaries      []string
+			WithEtcdadm       bool
+		}{
+			EtcdadmReleaseURL: etcdadmReleaseURL,
+			EtcdadmVersion:    defaultEtcdadmVersion,
+			EtcdadmSHA256:     etcdadmSHA256,
+			EtcdReleaseURL:    etcdReleaseURL,
+			EtcdVersion:       etcdVersion,
+			EtcdSHA256:        etcdSHA256,
+			EtcdCacheDir:      etcdadmCacheDir + "/v" + etcdVersion,
+			EtcdBinaries:      etcdBinaries,
+			WithEtcdadm:       !withSystemd,
+		},
+	); err != nil {
+		return nil, fmt.Errorf("unable to render install-binaries.sh from a template: %w", err)
+	}
+
 	startClusterScriptBuf := bytes.Buffer{}
 	startClusterScriptTmpl, err := template.New("start-cluster.sh.tmpl").Funcs(sprig.FuncMap()).ParseFS(
 		cloudConfigTemplates,
@@ -198,27 +334,19 @@ func reconcileUserData(
 	if err := startClusterScriptTmpl.Execute(
 		&startClusterScriptBuf,
 		&struct {
-			EtcdadmReleaseURL string
-			EtcdadmVersion    string
-			EtcdVersion       string
-			ServiceName       string
-			ExtraSANs         string
+			EtcdVersion    string
+			EtcdReleaseURL string
+			ServiceName    string
+			ExtraSANs      string
+			EtcdEnv        []etcdEnvVar
+			WithSystemd    bool
 		}{
-			EtcdadmReleaseURL: defaultEtcdadmReleaseURL,
-			EtcdadmVersion:    defaultEtcdadmVersion,
-			EtcdVersion:       etcdVersion,
-			ServiceName:       peerService.Name,
-			ExtraSANs: strings.Join(
-				[]string{
-					peerService.Spec.ClusterIP,
-					fmt.Sprintf("%s.%s.svc", peerService.Name, peerService.Namespace),
-					fmt.Sprintf("%s.%s", peerService.Name, peerService.Namespace),
-					service.Spec.ClusterIP,
-					fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace),
-					fmt.Sprintf("%s.%s", service.Name, service.Namespace),
-				},
-				",",
-			),
+			EtcdVersion:    etcdVersion,
+			EtcdReleaseURL: etcdReleaseURL,
+			ServiceName:    peerService.Name,
+			ExtraSANs:      extraSANs,
+			EtcdEnv:        etcdEnv,
+			WithSystemd:    withSystemd,
 		},
 	); err != nil {
 		return nil, fmt.Errorf("unable to render start-cluster.sh from a template: %w", err)
@@ -235,34 +363,63 @@ func reconcileUserData(
 	if err := joinClusterScriptTmpl.Execute(
 		&joinClusterScriptBuf,
 		&struct {
-			EtcdadmReleaseURL  string
-			EtcdadmVersion     string
 			EtcdVersion        string
+			EtcdReleaseURL     string
 			ServiceName        string
 			ExtraSANs          string
 			EtcdClientEndpoint string
+			EtcdEnv            []etcdEnvVar
+			WithSystemd        bool
 		}{
-			EtcdadmReleaseURL: defaultEtcdadmReleaseURL,
-			EtcdadmVersion:    defaultEtcdadmVersion,
-			EtcdVersion:       etcdVersion,
-			ServiceName:       peerService.Name,
-			ExtraSANs: strings.Join(
-				[]string{
-					peerService.Spec.ClusterIP,
-					fmt.Sprintf("%s.%s.svc", peerService.Name, peerService.Namespace),
-					fmt.Sprintf("%s.%s", peerService.Name, peerService.Namespace),
-					service.Spec.ClusterIP,
-					fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace),
-					fmt.Sprintf("%s.%s", service.Name, service.Namespace),
-				},
-				",",
-			),
+			EtcdVersion:        etcdVersion,
+			EtcdReleaseURL:     etcdReleaseURL,
+			ServiceName:        peerService.Name,
+			ExtraSANs:          extraSANs,
 			EtcdClientEndpoint: fmt.Sprintf("https://%s:%d", service.Spec.ClusterIP, servicePortEtcd),
+			EtcdEnv:            etcdEnv,
+			WithSystemd:        withSystemd,
 		},
 	); err != nil {
 		return nil, fmt.Errorf("unable to render join-cluster.sh from a template: %w", err)
 	}
 
+	var restoreClusterScript string
+	if spec.AsFirstNode && spec.SnapshotSource != nil {
+		restoreClusterScriptBuf := bytes.Buffer{}
+		restoreClusterScriptTmpl, err := template.New("restore-cluster.sh.tmpl").Funcs(sprig.FuncMap()).ParseFS(
+			cloudConfigTemplates,
+			"templates/restore-cluster.sh.tmpl",
+		)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse a template of restore-cluster.sh: %w", err)
+		}
+		if err := restoreClusterScriptTmpl.Execute(
+			&restoreClusterScriptBuf,
+			&struct {
+				EtcdVersion    string
+				EtcdReleaseURL string
+				ServiceName    string
+				ExtraSANs      string
+				SnapshotPath   string
+				DataDir        string
+				EtcdEnv        []etcdEnvVar
+				WithSystemd    bool
+			}{
+				EtcdVersion:    etcdVersion,
+				EtcdReleaseURL: etcdReleaseURL,
+				ServiceName:    peerService.Name,
+				ExtraSANs:      extraSANs,
+				SnapshotPath:   snapshotPath,
+				DataDir:        etcdDataDir,
+				EtcdEnv:        etcdEnv,
+				WithSystemd:    withSystemd,
+			},
+		); err != nil {
+			return nil, fmt.Errorf("unable to render restore-cluster.sh from a template: %w", err)
+		}
+		restoreClusterScript = base64.StdEncoding.EncodeToString(restoreClusterScriptBuf.Bytes())
+	}
+
 	leaveClusterScriptBuf := bytes.Buffer{}
 	leaveClusterScriptTmpl, err := template.New("leave-cluster.sh.tmpl").Funcs(sprig.FuncMap()).ParseFS(
 		cloudConfigTemplates,
@@ -274,46 +431,50 @@ func reconcileUserData(
 	if err := leaveClusterScriptTmpl.Execute(
 		&leaveClusterScriptBuf,
 		&struct {
-			EtcdadmReleaseURL string
-			EtcdadmVersion    string
-			EtcdVersion       string
+			DataDir     string
+			WithSystemd bool
 		}{
-			EtcdadmReleaseURL: defaultEtcdadmReleaseURL,
-			EtcdadmVersion:    defaultEtcdadmVersion,
-			EtcdVersion:       defaultEtcdVersion,
+			DataDir:     etcdDataDir,
+			WithSystemd: withSystemd,
 		},
 	); err != nil {
 		return nil, fmt.Errorf("unable to render leave-cluster.sh from a template: %w", err)
 	}
 
-	cloudInitBuf := bytes.Buffer{}
-	cloudInitTmpl, err := template.New("cloud-init.tmpl").Funcs(sprig.FuncMap()).ParseFS(
-		cloudConfigTemplates,
-		"templates/cloud-init.tmpl",
-	)
-	if err != nil {
-		return nil, fmt.Errorf("unable to parse a template of cloud-init: %w", err)
+	var dataDevice string
+	if status.DataVolumeClaimRef != nil {
+		dataDevice = "/dev/disk/by-id/virtio-" + k8s_vmi.DiskSerialForData
 	}
-	if err := cloudInitTmpl.Execute(
-		&cloudInitBuf,
-		&struct {
-			LoginPassword               string
-			AuthorizedKeys              []string
-			StartClusterScript          string
-			JoinClusterScript           string
-			LeaveClusterScript          string
-			CACertificate, CAPrivateKey string
-		}{
-			LoginPassword:      loginPassword,
-			AuthorizedKeys:     []string{string(publicKey)},
-			StartClusterScript: base64.StdEncoding.EncodeToString(startClusterScriptBuf.Bytes()),
-			JoinClusterScript:  base64.StdEncoding.EncodeToString(joinClusterScriptBuf.Bytes()),
-			LeaveClusterScript: base64.StdEncoding.EncodeToString(leaveClusterScriptBuf.Bytes()),
-			CACertificate:      base64.StdEncoding.EncodeToString(caCertificate),
-			CAPrivateKey:       base64.StdEncoding.EncodeToString(caPrivateKey),
-		},
-	); err != nil {
-		return nil, fmt.Errorf("unable to render a cloud-config from a template: %w", err)
+
+	data := &bootstrapData{
+		LoginPassword:         loginPassword,
+		AuthorizedKeys:        []string{string(publicKey)},
+		SSHHostPrivateKey:     string(sshHostPrivateKey),
+		SSHHostPublicKey:      string(sshHostPublicKey),
+		InstallBinariesScript: base64.StdEncoding.EncodeToString(installBinariesScriptBuf.Bytes()),
+		StartClusterScript:    base64.StdEncoding.EncodeToString(startClusterScriptBuf.Bytes()),
+		JoinClusterScript:     base64.StdEncoding.EncodeToString(joinClusterScriptBuf.Bytes()),
+		RestoreClusterScript:  restoreClusterScript,
+		LeaveClusterScript:    base64.StdEncoding.EncodeToString(leaveClusterScriptBuf.Bytes()),
+		CACertificate:         base64.StdEncoding.EncodeToString(caCertificate),
+		CAPrivateKey:          caPrivateKey,
+		DataDevice:            dataDevice,
+		DataLabel:             etcdDataLabel,
+		DataDir:               etcdDataDir,
+	}
+
+	var userData []byte
+	if bootstrapFormatOf(spec) == kubernetesimalv1alpha1.EtcdBootstrapFormatIgnition {
+		if spec.ExtraCloudConfig != nil {
+			return nil, fmt.Errorf("an extra cloud-config can't be merged into an Ignition config")
+		}
+		if userData, err = newIgnitionConfig(getLoginUser(spec), data); err != nil {
+			return nil, fmt.Errorf("unable to render an Ignition config: %w", err)
+		}
+	} else {
+		if userData, err = newCloudConfig(ctx, c, obj, spec, data); err != nil {
+			return nil, err
+		}
 	}
 
 	if secret, err := k8s_secret.CreateOnlyIfNotExist(
@@ -323,7 +484,7 @@ func reconcileUserData(
 		newUserDataName(obj),
 		obj.GetNamespace(),
 		k8s_object.WithOwner(obj, scheme),
-		k8s_secret.WithDataWithKey("userdata", cloudInitBuf.Bytes()),
+		k8s_secret.WithDataWithKey("userdata", userData),
 	); err != nil {
 		return nil, fmt.Errorf("unable to create Secret: %w", err)
 	} else {
@@ -333,6 +494,95 @@ func reconcileUserData(
 	}
 }
 
+// newDefaultAffinity returns an affinity which makes a VirtualMachineInstance prefer to be scheduled on a host and a
+// zone different from ones of the other VirtualMachineInstances of the same cluster.
+func newDefaultAffinity(spec *kubernetesimalv1alpha1.EtcdNodeSpec) *corev1.Affinity {
+	selector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			clusterLabelKey: spec.ServiceRef.Name,
+		},
+	}
+	return &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				{
+					Weight: 100,
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						LabelSelector: selector,
+						TopologyKey:   corev1.LabelHostname,
+					},
+				},
+				{
+					Weight: 50,
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						LabelSelector: selector,
+						TopologyKey:   corev1.LabelTopologyZone,
+					},
+				},
+			},
+		},
+	}
+}
+
+// newVirtualMachineInstanceOptions returns options to configure a VirtualMachineInstance of an etcd node.
+func newVirtualMachineInstanceOptions(
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+) []k8s_object.ObjectOption {
+	opts := []k8s_object.ObjectOption{
+		k8s_object.WithLabel("app.kubernetes.io/name", "virtualmachineimage"),
+		k8s_object.WithLabel("app.kubernetes.io/instance", newVirtualMachineInstanceName(obj)),
+		k8s_object.WithLabel("app.kubernetes.io/part-of", "etcd"),
+		k8s_object.WithLabel(clusterLabelKey, spec.ServiceRef.Name),
+		k8s_vmi.WithEphemeralVolumeSource(spec.ImagePersistentVolumeClaimRef.Name),
+		k8s_vmi.WithReadinessTCPProbe(&corev1.TCPSocketAction{
+			Port: intstr.FromInt(serviceContainerPortSSH),
+		}),
+	}
+	if bootstrapFormatOf(spec) == kubernetesimalv1alpha1.EtcdBootstrapFormatIgnition {
+		opts = append(opts, k8s_vmi.WithConfigDriveUserDataSecret(status.UserDataRef))
+	} else {
+		opts = append(opts, k8s_vmi.WithUserDataSecret(status.UserDataRef))
+	}
+	if len(spec.NodeSelector) > 0 {
+		opts = append(opts, k8s_vmi.WithNodeSelector(spec.NodeSelector))
+	}
+	if len(spec.Tolerations) > 0 {
+		opts = append(opts, k8s_vmi.WithTolerations(spec.Tolerations))
+	}
+	if spec.Affinity != nil {
+		opts = append(opts, k8s_vmi.WithAffinity(spec.Affinity))
+	} else {
+		opts = append(opts, k8s_vmi.WithAffinity(newDefaultAffinity(spec)))
+	}
+	if len(spec.TopologySpreadConstraints) > 0 {
+		opts = append(opts, k8s_vmi.WithTopologySpreadConstraints(spec.TopologySpreadConstraints))
+	}
+	if status.DataVolumeClaimRef != nil {
+		opts = append(opts, k8s_vmi.WithDataVolumeSource(status.DataVolumeClaimRef.Name))
+	}
+	if resources := spec.Resources; resources != nil {
+		if resources.Memory != nil {
+			opts = append(opts, k8s_vmi.WithMemory(*resources.Memory))
+		}
+		if resources.HugepagesPageSize != "" {
+			opts = append(opts, k8s_vmi.WithHugepages(resources.HugepagesPageSize))
+		}
+		if resources.Cores != nil || resources.CPUModel != "" {
+			var cores uint32 = 1
+			if resources.Cores != nil {
+				cores = *resources.Cores
+			}
+			opts = append(opts, k8s_vmi.WithCPU(cores, resources.CPUModel))
+		}
+		if resources.DedicatedCPUPlacement {
+			opts = append(opts, k8s_vmi.WithDedicatedCPUPlacement())
+		}
+	}
+	return opts
+}
+
 func reconcileVirtualMachineInstance(
 	ctx context.Context,
 	c client.Client,
@@ -345,25 +595,82 @@ func reconcileVirtualMachineInstance(
 	ctx, span = tracing.FromContext(ctx).Start(ctx, "reconcileVirtualMachineInstance")
 	defer span.End()
 
+	if spec.InstanceType != nil {
+		return nil, fmt.Errorf("an instance type can be used only with a run strategy")
+	}
+
 	if _, vmi, err := k8s_vmi.CreateOnlyIfNotExist(
 		ctx,
 		c,
 		newVirtualMachineInstanceName(obj),
 		obj.GetNamespace(),
-		k8s_object.WithLabel("app.kubernetes.io/name", "virtualmachineimage"),
+		append(
+			newVirtualMachineInstanceOptions(obj, spec, status),
+			k8s_object.WithOwner(obj, scheme),
+		)...,
+	); err != nil {
+		return nil, fmt.Errorf("unable to create VirtualMachineInstance: %w", err)
+	} else {
+		return &corev1.LocalObjectReference{
+			Name: vmi.Name,
+		}, nil
+	}
+}
+
+// reconcileVirtualMachine creates a VirtualMachine which keeps a VirtualMachineInstance of an etcd node running.
+// KubeVirt names the VirtualMachineInstance after the VirtualMachine.
+func reconcileVirtualMachine(
+	ctx context.Context,
+	c client.Client,
+	scheme *runtime.Scheme,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+) (*corev1.LocalObjectReference, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "reconcileVirtualMachine")
+	defer span.End()
+
+	vmi, err := k8s_vmi.New(
+		newVirtualMachineInstanceName(obj),
+		obj.GetNamespace(),
+		newVirtualMachineInstanceOptions(obj, spec, status)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to prepare a template of VirtualMachineInstance: %w", err)
+	}
+
+	opts := []k8s_object.ObjectOption{
+		k8s_object.WithLabel("app.kubernetes.io/name", "virtualmachine"),
 		k8s_object.WithLabel("app.kubernetes.io/instance", newVirtualMachineInstanceName(obj)),
 		k8s_object.WithLabel("app.kubernetes.io/part-of", "etcd"),
 		k8s_object.WithOwner(obj, scheme),
-		k8s_vmi.WithEphemeralVolumeSource(spec.ImagePersistentVolumeClaimRef.Name),
-		k8s_vmi.WithUserDataSecret(status.UserDataRef),
-		k8s_vmi.WithReadinessTCPProbe(&corev1.TCPSocketAction{
-			Port: intstr.FromInt(serviceContainerPortSSH),
-		}),
+		k8s_vm.WithRunStrategy(kubevirtv1.VirtualMachineRunStrategy(*spec.RunStrategy)),
+	}
+	if instanceType := spec.InstanceType; instanceType != nil {
+		// KubeVirt rejects a VirtualMachine whose template conflicts with an instance type, so that resources of the
+		// template are left for the instance type.
+		vmi.Spec.Domain.Resources = kubevirtv1.ResourceRequirements{}
+		vmi.Spec.Domain.CPU = nil
+		vmi.Spec.Domain.Memory = nil
+		opts = append(opts, k8s_vm.WithInstancetype(instanceType.Name, instanceType.Kind))
+		if instanceType.PreferenceName != "" {
+			opts = append(opts, k8s_vm.WithPreference(instanceType.PreferenceName, instanceType.PreferenceKind))
+		}
+	}
+	opts = append(opts, k8s_vm.WithTemplate(vmi))
+
+	if _, vm, err := k8s_vm.CreateOnlyIfNotExist(
+		ctx,
+		c,
+		newVirtualMachineInstanceName(obj),
+		obj.GetNamespace(),
+		opts...,
 	); err != nil {
-		return nil, fmt.Errorf("unable to create VirtualMachineInstance: %w", err)
+		return nil, fmt.Errorf("unable to create VirtualMachine: %w", err)
 	} else {
 		return &corev1.LocalObjectReference{
-			Name: vmi.Name,
+			Name: vm.Name,
 		}, nil
 	}
 }
@@ -378,6 +685,25 @@ func finalizeVirtualMachineInstance(
 	ctx, span = tracing.FromContext(ctx).Start(ctx, "finalizeVirtualMachineInstance")
 	defer span.End()
 
+	// Delete a VirtualMachine at first so that it doesn't restart a VirtualMachineInstance being deleted.
+	if status.VirtualMachineRef != nil {
+		logger := log.FromContext(ctx).WithValues(
+			"object", status.VirtualMachineRef.Name,
+			"resource", "VirtualMachine",
+		)
+		if err := finalizer.FinalizeObject(
+			log.IntoContext(ctx, logger),
+			client,
+			obj.GetNamespace(),
+			status.VirtualMachineRef.Name,
+			&kubevirtv1.VirtualMachine{},
+		); err != nil {
+			return status, err
+		}
+		status.VirtualMachineRef = nil
+		logger.Info("VirtualMachine was finalized.")
+	}
+
 	if status.VirtualMachineInstanceRef == nil {
 		return status, nil
 	}
@@ -401,3 +727,40 @@ func finalizeVirtualMachineInstance(
 	logger.Info("VirtualMachineInstance was finalized.")
 	return status, nil
 }
+
+// newCloudConfig renders a cloud-config from bootstrap data, and merges an extra cloud-config which the spec refers to
+// into it.
+func newCloudConfig(
+	ctx context.Context,
+	c client.Client,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+	data *bootstrapData,
+) ([]byte, error) {
+	cloudInitBuf := bytes.Buffer{}
+	cloudInitTmpl, err := template.New("cloud-init.tmpl").Funcs(sprig.FuncMap()).ParseFS(
+		cloudConfigTemplates,
+		"templates/cloud-init.tmpl",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse a template of cloud-init: %w", err)
+	}
+	if err := cloudInitTmpl.Execute(&cloudInitBuf, data); err != nil {
+		return nil, fmt.Errorf("unable to render a cloud-config from a template: %w", err)
+	}
+
+	userData := cloudInitBuf.Bytes()
+	extraCloudConfig, err := getExtraCloudConfig(ctx, c, obj, spec)
+	if err != nil {
+		return nil, err
+	}
+	if extraCloudConfig != nil {
+		if userData, err = mergeCloudConfig(userData, extraCloudConfig); err != nil {
+			return nil, fmt.Errorf("unable to merge an extra cloud-config: %w", err)
+		}
+	}
+	if err := validateCloudConfig(userData); err != nil {
+		return nil, fmt.Errorf("unable to validate a cloud-config: %w", err)
+	}
+	return userData, nil
+}
@@ -0,0 +1,114 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdnode
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/finalizer"
+	k8s_object "github.com/kkohtaka/kubernetesimal/k8s/object"
+	k8s_persistentvolumeclaim "github.com/kkohtaka/kubernetesimal/k8s/persistentvolumeclaim"
+	"github.com/kkohtaka/kubernetesimal/observability/tracing"
+)
+
+func newDataVolumeClaimName(obj client.Object) string {
+	return "data-" + obj.GetName()
+}
+
+func reconcileDataVolumeClaim(
+	ctx context.Context,
+	c client.Client,
+	scheme *runtime.Scheme,
+	obj client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+	_ *kubernetesimalv1alpha1.EtcdNodeStatus,
+) (*corev1.LocalObjectReference, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "reconcileDataVolumeClaim")
+	defer span.End()
+
+	if spec.DataVolumeClaimTemplate == nil {
+		return nil, nil
+	}
+
+	if claim, err := k8s_persistentvolumeclaim.CreateOnlyIfNotExist(
+		ctx,
+		c,
+		newDataVolumeClaimName(obj),
+		obj.GetNamespace(),
+		k8s_object.WithLabels(spec.DataVolumeClaimTemplate.Labels),
+		k8s_object.WithAnnotations(spec.DataVolumeClaimTemplate.Annotations),
+		k8s_object.WithOwner(obj, scheme),
+		k8s_persistentvolumeclaim.WithSpec(&spec.DataVolumeClaimTemplate.Spec),
+	); err != nil {
+		return nil, fmt.Errorf("unable to create PersistentVolumeClaim: %w", err)
+	} else {
+		return &corev1.LocalObjectReference{
+			Name: claim.Name,
+		}, nil
+	}
+}
+
+func finalizeDataVolumeClaim(
+	ctx context.Context,
+	client client.Client,
+	obj client.Object,
+	status *kubernetesimalv1alpha1.EtcdNodeStatus,
+) (*kubernetesimalv1alpha1.EtcdNodeStatus, error) {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "finalizeDataVolumeClaim")
+	defer span.End()
+
+	if status.DataVolumeClaimRef == nil {
+		return status, nil
+	}
+
+	logger := log.FromContext(ctx).WithValues(
+		"object", status.DataVolumeClaimRef.Name,
+		"resource", "PersistentVolumeClaim",
+	)
+	ctx = log.IntoContext(ctx, logger)
+
+	if err := finalizer.FinalizeObject(
+		ctx,
+		client,
+		obj.GetNamespace(),
+		status.DataVolumeClaimRef.Name,
+		&corev1.PersistentVolumeClaim{},
+	); err != nil {
+		return status, err
+	}
+	status.DataVolumeClaimRef = nil
+	logger.Info("PersistentVolumeClaim was finalized.")
+	return status, nil
+}
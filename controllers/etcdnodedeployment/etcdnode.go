@@ -0,0 +1,99 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdnodedeployment
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	"github.com/kkohtaka/kubernetesimal/controller/etcdclient"
+)
+
+// getLeavingEtcdNodes returns EtcdNodes of an EtcdNodeDeployment which are being deleted and whose etcd members
+// haven't left the etcd cluster yet.
+func getLeavingEtcdNodes(
+	ctx context.Context,
+	c client.Client,
+	deployment client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeDeploymentSpec,
+) ([]*kubernetesimalv1alpha1.EtcdNode, error) {
+	selector, err := metav1.LabelSelectorAsSelector(spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert a label selector: %w", err)
+	}
+
+	var nodeList kubernetesimalv1alpha1.EtcdNodeList
+	if err := c.List(
+		ctx,
+		&nodeList,
+		client.InNamespace(deployment.GetNamespace()),
+		client.MatchingLabelsSelector{Selector: selector},
+	); err != nil {
+		return nil, fmt.Errorf("unable to list EtcdNodes: %w", err)
+	}
+
+	var nodes []*kubernetesimalv1alpha1.EtcdNode
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		if node.DeletionTimestamp != nil && !node.Status.IsMemberFinalized() {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+// listEtcdVoters returns voting members of the etcd cluster of an EtcdNodeDeployment with their health.
+func listEtcdVoters(
+	ctx context.Context,
+	c client.Client,
+	deployment client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeDeploymentSpec,
+) ([]etcdclient.Voter, error) {
+	templateSpec := &spec.Template.Spec
+	etcdClient, err := etcdclient.New(
+		ctx,
+		c,
+		deployment.GetNamespace(),
+		&templateSpec.ServiceRef,
+		etcdclient.CredentialsFromEtcdNodeSpec(templateSpec),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer etcdClient.Close()
+
+	voters, err := etcdclient.ListVoters(ctx, etcdClient)
+	if err != nil {
+		return nil, errors.NewRequeueError("waiting for etcd members listed").
+			Wrap(err).
+			WithDelay(memberRemovalRetryDelay)
+	}
+	return voters, nil
+}
@@ -32,6 +32,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -46,12 +47,20 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	"github.com/kkohtaka/kubernetesimal/controller/etcdclient"
+	"github.com/kkohtaka/kubernetesimal/controller/quorum"
 	"github.com/kkohtaka/kubernetesimal/hash"
 	k8s_etcdnodeset "github.com/kkohtaka/kubernetesimal/k8s/etcdnodeset"
 	k8s_object "github.com/kkohtaka/kubernetesimal/k8s/object"
 	"github.com/kkohtaka/kubernetesimal/observability/tracing"
 )
 
+const (
+	// memberRemovalRetryDelay is a delay to retry removing an etcd member while another member is leaving.
+	memberRemovalRetryDelay = 10 * time.Second
+)
+
 func reconcileEtcdNodeSets(
 	ctx context.Context,
 	c client.Client,
@@ -70,6 +79,14 @@ func reconcileEtcdNodeSets(
 		return nil, err
 	}
 
+	if spec.Paused {
+		// Don't create or scale any EtcdNodeSets while the EtcdNodeDeployment is paused.
+		logger.V(4).Info("EtcdNodeDeployment is paused.")
+		newSet := findNewEtcdNodeSet(spec, sets)
+		_, oldSets := findOldEtcdNodeSets(spec, sets)
+		return syncRolloutStatus(ctx, deployment, status, append(oldSets, newSet), newSet), nil
+	}
+
 	newSet, oldSets, newRevision, collision, err := getAllEtcdNodeSetsAndSyncRevision(
 		ctx,
 		c,
@@ -103,7 +120,15 @@ func reconcileEtcdNodeSets(
 	}
 
 	// Scale down, if we can.
-	scaledDown, err := reconcileOldEtcdNodeSets(ctx, c, spec, allSets, filterActiveEtcdNodeSets(oldSets), newSet)
+	scaledDown, err := reconcileOldEtcdNodeSets(
+		ctx,
+		c,
+		deployment,
+		spec,
+		allSets,
+		filterActiveEtcdNodeSets(oldSets),
+		newSet,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -191,6 +216,14 @@ func reconcileNewEtcdNodeSet(
 		scaled, _, err := scaleEtcdNodeSet(ctx, c, spec, newSet, *(spec.Replicas))
 		return scaled, err
 	}
+	if isRollingOut(allSets, newSet) && !isEtcdNodeSetAvailable(newSet) {
+		log.FromContext(ctx).V(4).Info(
+			"Replacing the next etcd member is postponed until the previously replaced one becomes available.",
+			"etcdNodeSet", client.ObjectKeyFromObject(newSet).String(),
+			"availableReplicas", newSet.Status.AvailableReplicas,
+		)
+		return false, nil
+	}
 	newReplicasCount, err := newEtcdNodeSetNewReplicas(spec, allSets, *newSet.Spec.Replicas)
 	if err != nil {
 		return false, err
@@ -202,6 +235,7 @@ func reconcileNewEtcdNodeSet(
 func reconcileOldEtcdNodeSets(
 	ctx context.Context,
 	c client.Client,
+	deployment client.Object,
 	spec *kubernetesimalv1alpha1.EtcdNodeDeploymentSpec,
 	allSets, oldSets []*kubernetesimalv1alpha1.EtcdNodeSet,
 	newSet *kubernetesimalv1alpha1.EtcdNodeSet,
@@ -232,20 +266,66 @@ func reconcileOldEtcdNodeSets(
 		return false, nil
 	}
 
-	oldSets, cleanupCount, err := cleanupUnhealthyReplicas(ctx, c, spec, oldSets, maxScaledDown)
+	// Remove etcd members one by one so that the etcd cluster keeps its quorum. The next member is removed after the
+	// previous one has left the etcd cluster.
+	if leavingNodes, err := getLeavingEtcdNodes(ctx, c, deployment, spec); err != nil {
+		return false, err
+	} else if len(leavingNodes) > 0 {
+		return false, errors.NewRequeueError(
+			fmt.Sprintf("waiting for an etcd member of EtcdNode %s finalized", leavingNodes[0].Name),
+		).WithDelay(memberRemovalRetryDelay)
+	}
+	for _, set := range allSets {
+		if set.Status.ActiveReplicas > *(set.Spec.Replicas) {
+			logger.V(4).Info(
+				"EtcdNodeSet is being scaled down.",
+				"etcdNodeSet", client.ObjectKeyFromObject(set).String(),
+			)
+			return false, nil
+		}
+	}
+	if maxScaledDown > 1 {
+		maxScaledDown = 1
+	}
+
+	// Voting members are counted with the etcd cluster itself since an EtcdNode which isn't available may run a
+	// healthy etcd member and vice versa.
+	voters, err := listEtcdVoters(ctx, c, deployment, spec)
 	if err != nil {
-		return false, nil
+		return false, err
+	}
+
+	// An unhealthy member can be removed only if the etcd cluster has its quorum.
+	cleanupCount := int32(0)
+	if quorum.CanRemoveMember(len(voters), etcdclient.CountHealthyVoters(voters), false) {
+		oldSets, cleanupCount, err = cleanupUnhealthyReplicas(ctx, c, spec, oldSets, maxScaledDown)
+		if err != nil {
+			return false, err
+		}
+		logger.V(4).Info(
+			"Unhealthy replicas are cleaned up.",
+			"cleanupCount", cleanupCount,
+		)
+		if cleanupCount > 0 {
+			return true, nil
+		}
+	}
+
+	// A healthy old member is removed only after the member replacing it becomes available.
+	if !isEtcdNodeSetAvailable(newSet) {
+		logger.V(4).Info(
+			"Removing an old etcd member is postponed until new etcd members become available.",
+			"etcdNodeSet", client.ObjectKeyFromObject(newSet).String(),
+			"availableReplicas", newSet.Status.AvailableReplicas,
+		)
+		return cleanupCount > 0, nil
 	}
-	logger.V(4).Info(
-		"Unhealthy replicas are cleaned up.",
-		"cleanupCount", cleanupCount,
-	)
 
 	// Scale down old EtcdNodeSets, need check maxUnavailable to ensure we can scale down
 	allSets = append(oldSets, newSet)
-	scaledDownCount, err := scaleDownOldReplicaSetsForRollingUpdate(ctx, c, spec, allSets, oldSets)
+	scaledDownCount, err := scaleDownOldReplicaSetsForRollingUpdate(ctx, c, spec, allSets, oldSets, voters)
 	if err != nil {
-		return false, nil
+		return false, err
 	}
 	logger.V(4).Info(
 		"Old EtcdNodeSets are scaled down.",
@@ -776,6 +856,27 @@ func newEtcdNodeSetNewReplicas(
 	return currentSpecReplicas + scaleUpCount, nil
 }
 
+// isRollingOut returns whether EtcdNodeSets other than the new one still have EtcdNodes, i.e. etcd members are being
+// replaced with ones of the new EtcdNodeSet.
+func isRollingOut(allSets []*kubernetesimalv1alpha1.EtcdNodeSet, newSet *kubernetesimalv1alpha1.EtcdNodeSet) bool {
+	for _, set := range allSets {
+		if set == nil || set.UID == newSet.UID {
+			continue
+		}
+		if *(set.Spec.Replicas) > 0 || set.Status.Replicas > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isEtcdNodeSetAvailable returns whether all EtcdNodes of an EtcdNodeSet are available as observed by the latest
+// generation of the EtcdNodeSet.
+func isEtcdNodeSetAvailable(set *kubernetesimalv1alpha1.EtcdNodeSet) bool {
+	return set.Status.ObservedGeneration >= set.Generation &&
+		set.Status.AvailableReplicas >= *(set.Spec.Replicas)
+}
+
 // getReplicaCountForEtcdNodeSets returns the sum of Replicas of the given EtcdNodeSets.
 func getReplicaCountForEtcdNodeSets(replicaSets []*kubernetesimalv1alpha1.EtcdNodeSet) int32 {
 	totalReplicas := int32(0)
@@ -975,6 +1076,7 @@ func scaleDownOldReplicaSetsForRollingUpdate(
 	c client.Client,
 	spec *kubernetesimalv1alpha1.EtcdNodeDeploymentSpec,
 	allSets, oldSets []*kubernetesimalv1alpha1.EtcdNodeSet,
+	voters []etcdclient.Voter,
 ) (int32, error) {
 	logger := log.FromContext(ctx)
 
@@ -993,10 +1095,22 @@ func scaleDownOldReplicaSetsForRollingUpdate(
 		"count", availableNodesCount,
 	)
 
+	// A healthy member can be removed only if healthy members left form a quorum of the shrunk etcd cluster.
+	healthyVoters := etcdclient.CountHealthyVoters(voters)
+	if !quorum.CanRemoveMember(len(voters), healthyVoters, true) {
+		logger.V(4).Info(
+			"An etcd member can't be removed without losing a quorum.",
+			"voters", len(voters),
+			"healthyVoters", healthyVoters,
+		)
+		return 0, nil
+	}
+
 	sort.Sort(etcdNodeSetsByCreationTimestamp(oldSets))
 
 	totalScaledDown := int32(0)
-	totalScaleDownCount := availableNodesCount - minAvailable
+	// Remove etcd members one by one.
+	totalScaleDownCount := int32(integer.IntMin(int(availableNodesCount-minAvailable), 1))
 	for _, targetSet := range oldSets {
 		if totalScaledDown >= totalScaleDownCount {
 			// No further scaling required.
@@ -58,6 +58,7 @@ type Reconciler struct {
 //+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdnodedeployments/finalizers,verbs=update
 //+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdnodesets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdnodesets/status,verbs=get
+//+kubebuilder:rbac:groups=kubernetesimal.kkohtaka.org,resources=etcdnodes,verbs=get;list;watch
 
 func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx).WithValues("etcdnodedeployment", req.NamespacedName)
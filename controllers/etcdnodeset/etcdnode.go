@@ -110,10 +110,31 @@ func reconcileEtcdNodes(
 					k8s_etcdnode.WithCAPrivateKeyRef(templateSpec.CAPrivateKeyRef),
 					k8s_etcdnode.WithClientCertificateRef(templateSpec.ClientCertificateRef),
 					k8s_etcdnode.WithClientPrivateKeyRef(templateSpec.ClientPrivateKeyRef),
+					k8s_etcdnode.WithPeerCertificateRef(templateSpec.PeerCertificateRef),
+					k8s_etcdnode.WithPeerPrivateKeyRef(templateSpec.PeerPrivateKeyRef),
 					k8s_etcdnode.WithSSHPrivateKeyRef(templateSpec.SSHPrivateKeyRef),
 					k8s_etcdnode.WithSSHPublicKeyRef(templateSpec.SSHPublicKeyRef),
 					k8s_etcdnode.WithServiceRef(templateSpec.ServiceRef),
 					k8s_etcdnode.AsFirstNode(templateSpec.AsFirstNode),
+					k8s_etcdnode.WithSnapshotSource(templateSpec.SnapshotSource),
+					k8s_etcdnode.WithDataVolumeClaimTemplate(templateSpec.DataVolumeClaimTemplate),
+					k8s_etcdnode.WithRunStrategy(templateSpec.RunStrategy),
+					k8s_etcdnode.WithResources(templateSpec.Resources),
+					k8s_etcdnode.WithInstanceType(templateSpec.InstanceType),
+					k8s_etcdnode.WithNodeSelector(templateSpec.NodeSelector),
+					k8s_etcdnode.WithTolerations(templateSpec.Tolerations),
+					k8s_etcdnode.WithAffinity(templateSpec.Affinity),
+					k8s_etcdnode.WithTopologySpreadConstraints(templateSpec.TopologySpreadConstraints),
+					k8s_etcdnode.WithMemberCertificateSigner(templateSpec.MemberCertificateSigner),
+					k8s_etcdnode.WithPeerMTLS(templateSpec.PeerMTLS),
+					k8s_etcdnode.WithSSH(templateSpec.SSH),
+					k8s_etcdnode.WithArtifacts(templateSpec.Artifacts),
+					k8s_etcdnode.WithBootstrapDriver(templateSpec.BootstrapDriver),
+					k8s_etcdnode.WithConfig(templateSpec.Config),
+					k8s_etcdnode.WithExtraArgs(templateSpec.ExtraArgs),
+					k8s_etcdnode.WithExtraCloudConfig(templateSpec.ExtraCloudConfig),
+					k8s_etcdnode.WithBootstrap(templateSpec.Bootstrap),
+					k8s_etcdnode.WithJoin(templateSpec.Join),
 				); err != nil {
 					errCh <- err
 				} else {
@@ -140,54 +161,38 @@ func reconcileEtcdNodes(
 	} else if diff > 0 {
 		logger.V(2).Info("Too many replicas", "need", *(spec.Replicas), "deleting", diff)
 
-		nodesToDelete, err := getEtcdNodesToDelete(ctx, c, set, filteredNodes, filteredNodes, diff)
+		// Remove etcd members one by one so that the etcd cluster keeps its quorum. The next EtcdNode is deleted after
+		// the deletion of this one is observed.
+		nodesToDelete, err := getEtcdNodesToDelete(ctx, c, set, filteredNodes, filteredNodes, 1)
 		if err != nil {
 			return nil, fmt.Errorf("unable to get EtcdNodes to delete: %w", err)
 		}
+		targetNode := nodesToDelete[0]
+
+		if err := ensureEtcdNodeRemovable(ctx, c, set, spec, targetNode); err != nil {
+			return nil, err
+		}
 
 		if err := expectations.ExpectDeletions(key, getEtcdNodeKeys(nodesToDelete)); err != nil {
 			return nil, fmt.Errorf("unable to increment deletion expectations: %w", err)
 		}
 
-		var (
-			wg     sync.WaitGroup
-			errCh  = make(chan error, diff)
-			nodeCh = make(chan *kubernetesimalv1alpha1.EtcdNode, diff)
-		)
-		wg.Add(diff)
-		for _, node := range nodesToDelete {
-			go func(targetNode *kubernetesimalv1alpha1.EtcdNode) {
-				defer wg.Done()
-				if err := c.Delete(ctx, targetNode, &client.DeleteOptions{}); err != nil {
-					nodeKey := client.ObjectKeyFromObject(targetNode).String()
-					expectations.DeletionObserved(key, nodeKey)
-					if !apierrors.IsNotFound(err) {
-						logger.V(2).Info("Failed to delete", "etcdNode", nodeKey)
-						errCh <- err
-					}
-				} else {
-					nodeCh <- targetNode
-				}
-			}(node)
-		}
-		wg.Wait()
-		close(errCh)
-		close(nodeCh)
-
-		for deletedNode := range nodeCh {
-			for i, node := range filteredNodes {
-				if node.UID == deletedNode.UID {
-					filteredNodes = append(filteredNodes[:i], filteredNodes[i+1:]...)
-					break
-				}
+		if err := c.Delete(ctx, targetNode, &client.DeleteOptions{}); err != nil {
+			nodeKey := client.ObjectKeyFromObject(targetNode).String()
+			expectations.DeletionObserved(key, nodeKey)
+			if !apierrors.IsNotFound(err) {
+				logger.V(2).Info("Failed to delete", "etcdNode", nodeKey)
+				return nil, err
 			}
+		} else {
+			logger.Info("EtcdNode was deleted.", "node", client.ObjectKeyFromObject(targetNode))
 		}
-		select {
-		case err := <-errCh:
-			if err != nil {
-				return nil, err
+
+		for i, node := range filteredNodes {
+			if node.UID == targetNode.UID {
+				filteredNodes = append(filteredNodes[:i], filteredNodes[i+1:]...)
+				break
 			}
-		default:
 		}
 	}
 	return syncStatus(ctx, set, spec, status, filteredNodes), nil
@@ -356,6 +361,10 @@ func getEtcdNodesToDelete(
 			client.ObjectKey{Namespace: node.Namespace, Name: node.Status.VirtualMachineInstanceRef.Name},
 			&vmi,
 		); err != nil {
+			if apierrors.IsNotFound(err) {
+				// A VirtualMachineInstance may be being restarted by a VirtualMachine.
+				continue
+			}
 			return nil, fmt.Errorf("unable to get VirtualMachineInstance: %w", err)
 		}
 
@@ -376,6 +385,10 @@ func getEtcdNodesToDelete(
 			client.ObjectKey{Namespace: node.Namespace, Name: node.Status.VirtualMachineInstanceRef.Name},
 			&vmi,
 		); err != nil {
+			if apierrors.IsNotFound(err) {
+				// A VirtualMachineInstance may be being restarted by a VirtualMachine.
+				continue
+			}
 			return nil, fmt.Errorf("unable to get VirtualMachineInstance: %w", err)
 		}
 
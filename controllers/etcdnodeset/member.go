@@ -0,0 +1,155 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdnodeset
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	"github.com/kkohtaka/kubernetesimal/controller/errors"
+	"github.com/kkohtaka/kubernetesimal/controller/etcdclient"
+	"github.com/kkohtaka/kubernetesimal/controller/quorum"
+	"github.com/kkohtaka/kubernetesimal/observability/tracing"
+)
+
+const (
+	// memberRemovalRetryDelay is a delay to retry removing an etcd member while it can't be removed safely.
+	memberRemovalRetryDelay = 10 * time.Second
+)
+
+// getClusterEtcdNodes returns EtcdNodes composing the same etcd cluster as EtcdNodes with the spec, including ones
+// being deleted.
+func getClusterEtcdNodes(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	spec *kubernetesimalv1alpha1.EtcdNodeSpec,
+) ([]*kubernetesimalv1alpha1.EtcdNode, error) {
+	var nodeList kubernetesimalv1alpha1.EtcdNodeList
+	if err := c.List(ctx, &nodeList, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("unable to list EtcdNodes: %w", err)
+	}
+
+	var nodes []*kubernetesimalv1alpha1.EtcdNode
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		if node.Spec.ServiceRef.Name == spec.ServiceRef.Name {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+// ensureEtcdNodeRemovable returns an error to requeue unless an etcd member of the EtcdNode can be removed from its
+// etcd cluster without losing a quorum. Members are removed one by one, so a member can't be removed while another
+// member of the same cluster is leaving the cluster.
+func ensureEtcdNodeRemovable(
+	ctx context.Context,
+	c client.Client,
+	set client.Object,
+	spec *kubernetesimalv1alpha1.EtcdNodeSetSpec,
+	node *kubernetesimalv1alpha1.EtcdNode,
+) error {
+	var span trace.Span
+	ctx, span = tracing.FromContext(ctx).Start(ctx, "ensureEtcdNodeRemovable")
+	defer span.End()
+	logger := log.FromContext(ctx)
+
+	templateSpec := &spec.Template.Spec
+
+	clusterNodes, err := getClusterEtcdNodes(ctx, c, set.GetNamespace(), templateSpec)
+	if err != nil {
+		return err
+	}
+	activeClusterNodes := 0
+	for _, clusterNode := range clusterNodes {
+		if clusterNode.DeletionTimestamp == nil {
+			activeClusterNodes++
+			continue
+		}
+		if !clusterNode.Status.IsMemberFinalized() {
+			return errors.NewRequeueError(
+				fmt.Sprintf("waiting for an etcd member of EtcdNode %s finalized", clusterNode.Name),
+			).WithDelay(memberRemovalRetryDelay)
+		}
+	}
+
+	// An EtcdNode which has never been ready hasn't run a healthy etcd member, so removing it doesn't decrease
+	// healthy members. The first EtcdNode of a cluster being bootstrapped has no other member to keep a quorum with.
+	if !node.Status.IsReadyOnce() || (node.Spec.AsFirstNode && activeClusterNodes <= 1) {
+		return nil
+	}
+
+	etcdClient, err := etcdclient.New(
+		ctx,
+		c,
+		set.GetNamespace(),
+		&templateSpec.ServiceRef,
+		etcdclient.CredentialsFromEtcdNodeSpec(templateSpec),
+	)
+	if err != nil {
+		return err
+	}
+	defer etcdClient.Close()
+
+	voters, err := etcdclient.ListVoters(ctx, etcdClient)
+	if err != nil {
+		return errors.NewRequeueError("waiting for etcd members listed").
+			Wrap(err).
+			WithDelay(memberRemovalRetryDelay)
+	}
+
+	var isVoter, healthy bool
+	for _, v := range voters {
+		if v.Name == node.Name {
+			isVoter, healthy = true, v.Healthy
+		}
+	}
+	if !isVoter {
+		return nil
+	}
+	healthyVoters := etcdclient.CountHealthyVoters(voters)
+	if !quorum.CanRemoveMember(len(voters), healthyVoters, healthy) {
+		return errors.NewRequeueError(
+			fmt.Sprintf(
+				"waiting for an etcd member of EtcdNode %s become removable without losing a quorum",
+				node.Name,
+			),
+		).WithDelay(memberRemovalRetryDelay)
+	}
+	logger.V(2).Info(
+		"An etcd member can be removed.",
+		"etcdNode", node.Name,
+		"voters", len(voters),
+		"healthyVoters", healthyVoters,
+	)
+	return nil
+}
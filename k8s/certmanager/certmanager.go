@@ -0,0 +1,240 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package certmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	k8s_object "github.com/kkohtaka/kubernetesimal/k8s/object"
+)
+
+// cert-manager objects are handled as unstructured objects so that the controller doesn't depend on cert-manager
+// being installed unless a cert-manager issuer is used.
+
+const (
+	// GroupName is the API group of cert-manager.
+	GroupName = "cert-manager.io"
+
+	// SecretKeyCACertificate is a key of a Secret issued by cert-manager which holds a CA certificate of the issuer.
+	SecretKeyCACertificate = "ca.crt"
+)
+
+var (
+	// CertificateGroupVersionKind is a GroupVersionKind of cert-manager Certificate.
+	CertificateGroupVersionKind = schema.GroupVersionKind{Group: GroupName, Version: "v1", Kind: "Certificate"}
+	// IssuerGroupVersionKind is a GroupVersionKind of cert-manager Issuer.
+	IssuerGroupVersionKind = schema.GroupVersionKind{Group: GroupName, Version: "v1", Kind: "Issuer"}
+)
+
+// NewCertificate returns an empty cert-manager Certificate.
+func NewCertificate() *unstructured.Unstructured {
+	var certificate unstructured.Unstructured
+	certificate.SetGroupVersionKind(CertificateGroupVersionKind)
+	return &certificate
+}
+
+func newIssuer() *unstructured.Unstructured {
+	var issuer unstructured.Unstructured
+	issuer.SetGroupVersionKind(IssuerGroupVersionKind)
+	return &issuer
+}
+
+func asCertificate(o runtime.Object) (*unstructured.Unstructured, error) {
+	u, ok := o.(*unstructured.Unstructured)
+	if !ok || u.GroupVersionKind() != CertificateGroupVersionKind {
+		return nil, errors.New("not a instance of Certificate")
+	}
+	return u, nil
+}
+
+func WithCommonName(commonName string) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		certificate, err := asCertificate(o)
+		if err != nil {
+			return err
+		}
+		return unstructured.SetNestedField(certificate.Object, commonName, "spec", "commonName")
+	}
+}
+
+func WithSecretName(secretName string) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		certificate, err := asCertificate(o)
+		if err != nil {
+			return err
+		}
+		return unstructured.SetNestedField(certificate.Object, secretName, "spec", "secretName")
+	}
+}
+
+func WithIssuerRef(name, kind, group string) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		certificate, err := asCertificate(o)
+		if err != nil {
+			return err
+		}
+		return unstructured.SetNestedStringMap(
+			certificate.Object,
+			map[string]string{
+				"name":  name,
+				"kind":  kind,
+				"group": group,
+			},
+			"spec", "issuerRef",
+		)
+	}
+}
+
+func WithIsCA(isCA bool) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		certificate, err := asCertificate(o)
+		if err != nil {
+			return err
+		}
+		return unstructured.SetNestedField(certificate.Object, isCA, "spec", "isCA")
+	}
+}
+
+// WithUsages sets key usages of a certificate, e.g. "client auth" or "server auth".
+func WithUsages(usages ...string) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		certificate, err := asCertificate(o)
+		if err != nil {
+			return err
+		}
+		return unstructured.SetNestedStringSlice(certificate.Object, usages, "spec", "usages")
+	}
+}
+
+// WithPrivateKey sets an algorithm, e.g. "RSA" or "ECDSA", and a size of a private key of a certificate.
+func WithPrivateKey(algorithm string, size int64) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		certificate, err := asCertificate(o)
+		if err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedField(
+			certificate.Object,
+			algorithm,
+			"spec", "privateKey", "algorithm",
+		); err != nil {
+			return err
+		}
+		return unstructured.SetNestedField(certificate.Object, size, "spec", "privateKey", "size")
+	}
+}
+
+// WithCASecretName makes an Issuer sign certificates with a CA stored in a Secret with the specified name.
+func WithCASecretName(secretName string) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		issuer, ok := o.(*unstructured.Unstructured)
+		if !ok || issuer.GroupVersionKind() != IssuerGroupVersionKind {
+			return errors.New("not a instance of Issuer")
+		}
+		return unstructured.SetNestedField(issuer.Object, secretName, "spec", "ca", "secretName")
+	}
+}
+
+// IsReady returns whether a cert-manager object has a Ready condition whose status is True.
+func IsReady(o *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(o.Object, "status", "conditions")
+	for _, condition := range conditions {
+		c, ok := condition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if c["type"] == "Ready" {
+			return c["status"] == "True"
+		}
+	}
+	return false
+}
+
+func ReconcileCertificate(
+	ctx context.Context,
+	c client.Client,
+	name, namespace string,
+	opts ...k8s_object.ObjectOption,
+) (*unstructured.Unstructured, error) {
+	return reconcile(ctx, c, NewCertificate(), name, namespace, opts...)
+}
+
+func ReconcileIssuer(
+	ctx context.Context,
+	c client.Client,
+	name, namespace string,
+	opts ...k8s_object.ObjectOption,
+) (*unstructured.Unstructured, error) {
+	return reconcile(ctx, c, newIssuer(), name, namespace, opts...)
+}
+
+func reconcile(
+	ctx context.Context,
+	c client.Client,
+	o *unstructured.Unstructured,
+	name, namespace string,
+	opts ...k8s_object.ObjectOption,
+) (*unstructured.Unstructured, error) {
+	kind := o.GetKind()
+	o.SetName(name)
+	o.SetNamespace(namespace)
+
+	opRes, err := ctrl.CreateOrUpdate(ctx, c, o, func() error {
+		for _, fn := range opts {
+			if err := fn(o); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create or update %s %s/%s: %w", kind, namespace, name, err)
+	}
+
+	logger := log.FromContext(ctx).WithValues(
+		"namespace", namespace,
+		"name", name,
+	)
+	switch opRes {
+	case controllerutil.OperationResultCreated:
+		logger.Info(kind + " was created.")
+	case controllerutil.OperationResultUpdated:
+		logger.Info(kind + " was updated.")
+	case controllerutil.OperationResultNone:
+		logger.V(4).Info(kind + " was unchanged.")
+	}
+
+	return o, nil
+}
@@ -0,0 +1,214 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package etcdbackup
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
+	k8s_object "github.com/kkohtaka/kubernetesimal/k8s/object"
+	k8s_secret "github.com/kkohtaka/kubernetesimal/k8s/secret"
+	"github.com/kkohtaka/kubernetesimal/snapshot"
+)
+
+const (
+	s3AccessKeyIDKey     = "accessKeyID"
+	s3SecretAccessKeyKey = "secretAccessKey"
+
+	// mountInfoPath is a path of a file which lists mount points of the current process.
+	mountInfoPath = "/proc/self/mountinfo"
+)
+
+func WithEtcdRef(etcdRef corev1.LocalObjectReference) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		backup, ok := o.(*kubernetesimalv1alpha1.EtcdBackup)
+		if !ok {
+			return errors.New("not a instance of EtcdBackup")
+		}
+		backup.Spec.EtcdRef = etcdRef
+		return nil
+	}
+}
+
+func WithSink(sink *kubernetesimalv1alpha1.EtcdBackupSink) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		backup, ok := o.(*kubernetesimalv1alpha1.EtcdBackup)
+		if !ok {
+			return errors.New("not a instance of EtcdBackup")
+		}
+		sink.DeepCopyInto(&backup.Spec.Sink)
+		return nil
+	}
+}
+
+func Create(
+	ctx context.Context,
+	c client.Client,
+	opts ...k8s_object.ObjectOption,
+) (*kubernetesimalv1alpha1.EtcdBackup, error) {
+	var backup kubernetesimalv1alpha1.EtcdBackup
+	for _, fn := range opts {
+		if err := fn(&backup); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.Create(ctx, &backup); err != nil {
+		return nil, fmt.Errorf("unable to create EtcdBackup %s: %w", k8s_object.ObjectName(&backup.ObjectMeta), err)
+	}
+
+	logger := log.FromContext(ctx).WithValues(
+		"namespace", backup.Namespace,
+		"name", backup.Name,
+	)
+	logger.Info("EtcdBackup was created")
+
+	return &backup, nil
+}
+
+// NewSnapshotKey returns a key of a snapshot of an EtcdBackup in its sink.
+func NewSnapshotKey(obj client.Object, spec *kubernetesimalv1alpha1.EtcdBackupSpec) string {
+	return fmt.Sprintf("%s/%s.db", spec.EtcdRef.Name, obj.GetName())
+}
+
+// GetSink returns a Sink that an EtcdBackup stores a snapshot into. PersistentVolumeClaims are expected to be mounted
+// under backupVolumeDir with their names, and an error is returned if a claim is not mounted there so that a snapshot
+// doesn't land in the filesystem of the container.
+func GetSink(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	sink *kubernetesimalv1alpha1.EtcdBackupSink,
+	backupVolumeDir string,
+) (snapshot.Sink, error) {
+	switch {
+	case sink.PersistentVolumeClaim != nil:
+		var pvc corev1.PersistentVolumeClaim
+		key := types.NamespacedName{
+			Namespace: namespace,
+			Name:      sink.PersistentVolumeClaim.ClaimName,
+		}
+		if err := c.Get(ctx, key, &pvc); err != nil {
+			return nil, fmt.Errorf("unable to get PersistentVolumeClaim %s: %w", key, err)
+		}
+		volumeDir := filepath.Join(backupVolumeDir, sink.PersistentVolumeClaim.ClaimName)
+		if mounted, err := isMountPoint(volumeDir); err != nil {
+			return nil, fmt.Errorf("unable to check if PersistentVolumeClaim %s is mounted: %w", key, err)
+		} else if !mounted {
+			return nil, fmt.Errorf("PersistentVolumeClaim %s is not mounted on %s", key, volumeDir)
+		}
+		return snapshot.NewFileSink(
+			filepath.Join(
+				volumeDir,
+				filepath.Clean("/"+sink.PersistentVolumeClaim.Path),
+			),
+		), nil
+	case sink.S3 != nil:
+		s3 := sink.S3
+		accessKeyID, err := k8s_secret.GetValueFromSecretKeySelector(
+			ctx,
+			c,
+			namespace,
+			&corev1.SecretKeySelector{
+				LocalObjectReference: s3.CredentialsSecretRef,
+				Key:                  s3AccessKeyIDKey,
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get an access key ID: %w", err)
+		}
+		secretAccessKey, err := k8s_secret.GetValueFromSecretKeySelector(
+			ctx,
+			c,
+			namespace,
+			&corev1.SecretKeySelector{
+				LocalObjectReference: s3.CredentialsSecretRef,
+				Key:                  s3SecretAccessKeyKey,
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get a secret access key: %w", err)
+		}
+		return snapshot.NewS3Sink(
+			s3.Endpoint,
+			s3.Bucket,
+			string(accessKeyID),
+			string(secretAccessKey),
+			func(o *snapshot.S3SinkOption) {
+				if s3.Region != "" {
+					o.Region = s3.Region
+				}
+				o.Prefix = s3.Prefix
+				o.InsecureSkipTLSVerify = s3.InsecureSkipTLSVerify
+			},
+		)
+	default:
+		return nil, fmt.Errorf("no sink is specified")
+	}
+}
+
+// mountPointUnescaper unescapes octal escape sequences of mount points in mountinfo.
+var mountPointUnescaper = strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`)
+
+// isMountPoint returns true if a path is a mount point of the current process.
+func isMountPoint(path string) (bool, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false, fmt.Errorf("unable to get an absolute path of %s: %w", path, err)
+	}
+
+	f, err := os.Open(mountInfoPath)
+	if err != nil {
+		return false, fmt.Errorf("unable to open %s: %w", mountInfoPath, err)
+	}
+	defer f.Close()
+
+	// See https://man7.org/linux/man-pages/man5/proc.5.html for the format of mountinfo.
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		if mountPointUnescaper.Replace(fields[4]) == path {
+			return true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("unable to read %s: %w", mountInfoPath, err)
+	}
+	return false, nil
+}
@@ -116,6 +116,28 @@ func WithClientPrivateKeyRef(ClientPrivateKeyRef corev1.SecretKeySelector) k8s_o
 	}
 }
 
+func WithPeerCertificateRef(peerCertificateRef corev1.SecretKeySelector) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		node.Spec.PeerCertificateRef = peerCertificateRef
+		return nil
+	}
+}
+
+func WithPeerPrivateKeyRef(peerPrivateKeyRef corev1.SecretKeySelector) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		node.Spec.PeerPrivateKeyRef = peerPrivateKeyRef
+		return nil
+	}
+}
+
 func WithSSHPrivateKeyRef(sshPrivateKeyRef corev1.SecretKeySelector) k8s_object.ObjectOption {
 	return func(o runtime.Object) error {
 		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
@@ -160,6 +182,236 @@ func AsFirstNode(asFirstNode bool) k8s_object.ObjectOption {
 	}
 }
 
+func WithSnapshotSource(source *kubernetesimalv1alpha1.EtcdSnapshotSource) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		node.Spec.SnapshotSource = source.DeepCopy()
+		return nil
+	}
+}
+
+func WithDataVolumeClaimTemplate(template *corev1.PersistentVolumeClaimTemplate) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		node.Spec.DataVolumeClaimTemplate = template.DeepCopy()
+		return nil
+	}
+}
+
+func WithRunStrategy(strategy *kubernetesimalv1alpha1.EtcdNodeRunStrategy) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		node.Spec.RunStrategy = strategy
+		return nil
+	}
+}
+
+func WithResources(resources *kubernetesimalv1alpha1.EtcdNodeResources) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		node.Spec.Resources = resources.DeepCopy()
+		return nil
+	}
+}
+
+func WithInstanceType(instanceType *kubernetesimalv1alpha1.EtcdNodeInstanceType) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		node.Spec.InstanceType = instanceType.DeepCopy()
+		return nil
+	}
+}
+
+func WithNodeSelector(nodeSelector map[string]string) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		if nodeSelector == nil {
+			node.Spec.NodeSelector = nil
+			return nil
+		}
+		node.Spec.NodeSelector = make(map[string]string, len(nodeSelector))
+		for k, v := range nodeSelector {
+			node.Spec.NodeSelector[k] = v
+		}
+		return nil
+	}
+}
+
+func WithTolerations(tolerations []corev1.Toleration) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		if tolerations == nil {
+			node.Spec.Tolerations = nil
+			return nil
+		}
+		node.Spec.Tolerations = make([]corev1.Toleration, len(tolerations))
+		for i := range tolerations {
+			tolerations[i].DeepCopyInto(&node.Spec.Tolerations[i])
+		}
+		return nil
+	}
+}
+
+func WithAffinity(affinity *corev1.Affinity) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		node.Spec.Affinity = affinity.DeepCopy()
+		return nil
+	}
+}
+
+func WithTopologySpreadConstraints(constraints []corev1.TopologySpreadConstraint) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		if constraints == nil {
+			node.Spec.TopologySpreadConstraints = nil
+			return nil
+		}
+		node.Spec.TopologySpreadConstraints = make([]corev1.TopologySpreadConstraint, len(constraints))
+		for i := range constraints {
+			constraints[i].DeepCopyInto(&node.Spec.TopologySpreadConstraints[i])
+		}
+		return nil
+	}
+}
+
+func WithMemberCertificateSigner(signer kubernetesimalv1alpha1.EtcdCertificateSigner) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		node.Spec.MemberCertificateSigner = signer
+		return nil
+	}
+}
+
+func WithPeerMTLS(mode kubernetesimalv1alpha1.EtcdPeerMTLSMode) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		node.Spec.PeerMTLS = mode
+		return nil
+	}
+}
+
+func WithSSH(ssh *kubernetesimalv1alpha1.EtcdNodeSSHSpec) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		node.Spec.SSH = ssh
+		return nil
+	}
+}
+
+func WithArtifacts(artifacts *kubernetesimalv1alpha1.EtcdArtifactsSpec) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		node.Spec.Artifacts = artifacts
+		return nil
+	}
+}
+
+func WithBootstrapDriver(driver kubernetesimalv1alpha1.EtcdBootstrapDriver) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		node.Spec.BootstrapDriver = driver
+		return nil
+	}
+}
+
+func WithConfig(config *kubernetesimalv1alpha1.EtcdConfig) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		node.Spec.Config = config
+		return nil
+	}
+}
+
+func WithExtraArgs(extraArgs map[string]string) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		node.Spec.ExtraArgs = extraArgs
+		return nil
+	}
+}
+
+func WithExtraCloudConfig(source *kubernetesimalv1alpha1.EtcdCloudConfigSource) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		node.Spec.ExtraCloudConfig = source
+		return nil
+	}
+}
+
+func WithBootstrap(bootstrap *kubernetesimalv1alpha1.EtcdNodeBootstrapSpec) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		node.Spec.Bootstrap = bootstrap
+		return nil
+	}
+}
+
+func WithJoin(join *kubernetesimalv1alpha1.EtcdJoinSpec) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		node, ok := o.(*kubernetesimalv1alpha1.EtcdNode)
+		if !ok {
+			return errors.New("not a instance of EtcdNode")
+		}
+		node.Spec.Join = join
+		return nil
+	}
+}
+
 func Create(
 	ctx context.Context,
 	c client.Client,
@@ -98,6 +98,17 @@ func WithRevisionHistoryLimit(limit *int32) k8s_object.ObjectOption {
 	}
 }
 
+func WithPaused(paused bool) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		deployment, ok := o.(*kubernetesimalv1alpha1.EtcdNodeDeployment)
+		if !ok {
+			return errors.New("not a instance of EtcdNodeDeployment")
+		}
+		deployment.Spec.Paused = paused
+		return nil
+	}
+}
+
 func Create(
 	ctx context.Context,
 	c client.Client,
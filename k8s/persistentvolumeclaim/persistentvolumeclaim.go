@@ -0,0 +1,89 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package persistentvolumeclaim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	k8s_object "github.com/kkohtaka/kubernetesimal/k8s/object"
+)
+
+func WithSpec(spec *corev1.PersistentVolumeClaimSpec) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		claim, ok := o.(*corev1.PersistentVolumeClaim)
+		if !ok {
+			return errors.New("not a instance of PersistentVolumeClaim")
+		}
+		spec.DeepCopyInto(&claim.Spec)
+		return nil
+	}
+}
+
+// CreateOnlyIfNotExist creates a PersistentVolumeClaim if it doesn't exist. Since most of fields of
+// PersistentVolumeClaimSpec are immutable, an existing PersistentVolumeClaim is never updated.
+func CreateOnlyIfNotExist(
+	ctx context.Context,
+	c client.Client,
+	name, namespace string,
+	opts ...k8s_object.ObjectOption,
+) (*corev1.PersistentVolumeClaim, error) {
+	var claim corev1.PersistentVolumeClaim
+	claim.Name = name
+	claim.Namespace = namespace
+	for _, fn := range opts {
+		if err := fn(&claim); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.Create(ctx, &claim); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			if err := c.Get(ctx, client.ObjectKeyFromObject(&claim), &claim); err != nil {
+				return nil, err
+			}
+			return &claim, nil
+		}
+		return nil, fmt.Errorf(
+			"unable to create PersistentVolumeClaim %s: %w",
+			k8s_object.ObjectName(&claim.ObjectMeta),
+			err,
+		)
+	}
+
+	logger := log.FromContext(ctx).WithValues(
+		"namespace", claim.Namespace,
+		"name", claim.Name,
+	)
+	logger.Info("PersistentVolumeClaim was created")
+
+	return &claim, nil
+}
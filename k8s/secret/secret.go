@@ -26,7 +26,7 @@ package k8s
 
 import (
 	"context"
-	"crypto/rsa"
+	"crypto"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
@@ -43,6 +43,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	k8s_object "github.com/kkohtaka/kubernetesimal/k8s/object"
+	"github.com/kkohtaka/kubernetesimal/pki"
 )
 
 func WithType(typ corev1.SecretType) k8s_object.ObjectOption {
@@ -191,7 +192,7 @@ func GetPrivateKeyFromSecretKeySelector(
 	c client.Client,
 	namespace string,
 	selector *corev1.SecretKeySelector,
-) (*rsa.PrivateKey, error) {
+) (crypto.Signer, error) {
 	var secret corev1.Secret
 	key := types.NamespacedName{
 		Namespace: namespace,
@@ -201,12 +202,7 @@ func GetPrivateKeyFromSecretKeySelector(
 		return nil, fmt.Errorf("unable to get Secret for a private key: %w", err)
 	}
 
-	p, _ := pem.Decode(secret.Data[corev1.TLSPrivateKeyKey])
-	if p == nil {
-		return nil, fmt.Errorf("no PEM block found")
-	}
-
-	privateKey, err := x509.ParsePKCS1PrivateKey(p.Bytes)
+	privateKey, err := pki.ParsePrivateKey(secret.Data[corev1.TLSPrivateKeyKey])
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse a private key: %w", err)
 	}
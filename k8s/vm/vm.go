@@ -0,0 +1,170 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	k8s_object "github.com/kkohtaka/kubernetesimal/k8s/object"
+)
+
+func WithRunStrategy(strategy kubevirtv1.VirtualMachineRunStrategy) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		vm, ok := o.(*kubevirtv1.VirtualMachine)
+		if !ok {
+			return errors.New("not a instance of VirtualMachine")
+		}
+		vm.Spec.Running = nil
+		vm.Spec.RunStrategy = &strategy
+		return nil
+	}
+}
+
+// WithTemplate sets labels, annotations and a spec of a VirtualMachineInstance as a template of VirtualMachineInstances
+// created by a VirtualMachine.
+func WithTemplate(vmi *kubevirtv1.VirtualMachineInstance) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		vm, ok := o.(*kubevirtv1.VirtualMachine)
+		if !ok {
+			return errors.New("not a instance of VirtualMachine")
+		}
+		vm.Spec.Template = &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      vmi.GetLabels(),
+				Annotations: vmi.GetAnnotations(),
+			},
+			Spec: *vmi.Spec.DeepCopy(),
+		}
+		return nil
+	}
+}
+
+func WithInstancetype(name, kind string) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		vm, ok := o.(*kubevirtv1.VirtualMachine)
+		if !ok {
+			return errors.New("not a instance of VirtualMachine")
+		}
+		vm.Spec.Instancetype = &kubevirtv1.InstancetypeMatcher{
+			Name: name,
+			Kind: kind,
+		}
+		return nil
+	}
+}
+
+func WithPreference(name, kind string) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		vm, ok := o.(*kubevirtv1.VirtualMachine)
+		if !ok {
+			return errors.New("not a instance of VirtualMachine")
+		}
+		vm.Spec.Preference = &kubevirtv1.PreferenceMatcher{
+			Name: name,
+			Kind: kind,
+		}
+		return nil
+	}
+}
+
+func CreateOnlyIfNotExist(
+	ctx context.Context,
+	c client.Client,
+	name, namespace string,
+	opts ...k8s_object.ObjectOption,
+) (controllerutil.OperationResult, *kubevirtv1.VirtualMachine, error) {
+	var vm kubevirtv1.VirtualMachine
+	vm.Name = name
+	vm.Namespace = namespace
+
+	if err := c.Get(ctx, client.ObjectKeyFromObject(&vm), &vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return Reconcile(ctx, c, name, namespace, opts...)
+		} else {
+			return controllerutil.OperationResultNone, nil, err
+		}
+	}
+
+	logger := log.FromContext(ctx).WithValues(
+		"namespace", vm.Namespace,
+		"name", vm.Name,
+	)
+	logger.V(4).Info("VirtualMachine already exists")
+
+	return controllerutil.OperationResultNone, &vm, nil
+}
+
+func Reconcile(
+	ctx context.Context,
+	c client.Client,
+	name, namespace string,
+	opts ...k8s_object.ObjectOption,
+) (controllerutil.OperationResult, *kubevirtv1.VirtualMachine, error) {
+	var vm kubevirtv1.VirtualMachine
+	vm.Name = name
+	vm.Namespace = namespace
+
+	opRes, err := ctrl.CreateOrUpdate(ctx, c, &vm, func() error {
+		for _, fn := range opts {
+			if err := fn(&vm); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return controllerutil.OperationResultNone, nil, fmt.Errorf(
+			"unable to create or update VirtualMachine %s: %w",
+			k8s_object.ObjectName(&vm.ObjectMeta),
+			err,
+		)
+	}
+
+	logger := log.FromContext(ctx).WithValues(
+		"namespace", vm.Namespace,
+		"name", vm.Name,
+	)
+	switch opRes {
+	case controllerutil.OperationResultCreated:
+		logger.Info("VirtualMachine was created.")
+	case controllerutil.OperationResultUpdated:
+		logger.Info("VirtualMachine was updated.")
+	case controllerutil.OperationResultNone:
+		logger.V(4).Info("VirtualMachine was unchanged.")
+	}
+
+	return opRes, &vm, nil
+}
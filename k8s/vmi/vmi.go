@@ -46,6 +46,11 @@ import (
 const (
 	DiskKeyForBoot      = "boot"
 	DiskKeyForCloudInit = "cloud-init"
+	DiskKeyForData      = "data"
+
+	// DiskSerialForData is a serial number of a data disk. A guest OS can find the disk at
+	// /dev/disk/by-id/virtio-<serial>.
+	DiskSerialForData = "etcd-data"
 )
 
 var (
@@ -108,7 +113,59 @@ func WithEphemeralVolumeSource(claimName string) k8s_object.ObjectOption {
 	}
 }
 
+func WithDataVolumeSource(claimName string) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		vmi, ok := o.(*kubevirtv1.VirtualMachineInstance)
+		if !ok {
+			return errors.New("not a instance of VirtualMachineInstance")
+		}
+		for _, v := range vmi.Spec.Volumes {
+			if v.Name == DiskKeyForData {
+				return fmt.Errorf("data volume is already set")
+			}
+		}
+		vmi.Spec.Volumes = append(vmi.Spec.Volumes, kubevirtv1.Volume{
+			Name: DiskKeyForData,
+			VolumeSource: kubevirtv1.VolumeSource{
+				PersistentVolumeClaim: &kubevirtv1.PersistentVolumeClaimVolumeSource{
+					PersistentVolumeClaimVolumeSource: corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: claimName,
+					},
+				},
+			},
+		})
+		vmi.Spec.Domain.Devices.Disks = append(vmi.Spec.Domain.Devices.Disks, kubevirtv1.Disk{
+			Name:   DiskKeyForData,
+			Serial: DiskSerialForData,
+			DiskDevice: kubevirtv1.DiskDevice{
+				Disk: &kubevirtv1.DiskTarget{
+					Bus: kubevirtv1.DiskBusVirtio,
+				},
+			},
+		})
+		return nil
+	}
+}
+
 func WithUserDataSecret(userDataRef *corev1.LocalObjectReference) k8s_object.ObjectOption {
+	return withCloudInitVolumeSource(kubevirtv1.VolumeSource{
+		CloudInitNoCloud: &kubevirtv1.CloudInitNoCloudSource{
+			UserDataSecretRef: userDataRef,
+		},
+	})
+}
+
+// WithConfigDriveUserDataSecret attaches user-data through a config drive, where Ignition of Fedora CoreOS and Flatcar
+// Container Linux reads its config from.
+func WithConfigDriveUserDataSecret(userDataRef *corev1.LocalObjectReference) k8s_object.ObjectOption {
+	return withCloudInitVolumeSource(kubevirtv1.VolumeSource{
+		CloudInitConfigDrive: &kubevirtv1.CloudInitConfigDriveSource{
+			UserDataSecretRef: userDataRef,
+		},
+	})
+}
+
+func withCloudInitVolumeSource(source kubevirtv1.VolumeSource) k8s_object.ObjectOption {
 	return func(o runtime.Object) error {
 		vmi, ok := o.(*kubevirtv1.VirtualMachineInstance)
 		if !ok {
@@ -120,12 +177,8 @@ func WithUserDataSecret(userDataRef *corev1.LocalObjectReference) k8s_object.Obj
 			}
 		}
 		vmi.Spec.Volumes = append(vmi.Spec.Volumes, kubevirtv1.Volume{
-			Name: DiskKeyForCloudInit,
-			VolumeSource: kubevirtv1.VolumeSource{
-				CloudInitNoCloud: &kubevirtv1.CloudInitNoCloudSource{
-					UserDataSecretRef: userDataRef,
-				},
-			},
+			Name:         DiskKeyForCloudInit,
+			VolumeSource: source,
 		})
 		vmi.Spec.Domain.Devices.Disks = append(vmi.Spec.Domain.Devices.Disks, kubevirtv1.Disk{
 			Name: DiskKeyForCloudInit,
@@ -139,6 +192,132 @@ func WithUserDataSecret(userDataRef *corev1.LocalObjectReference) k8s_object.Obj
 	}
 }
 
+func WithMemory(memory resource.Quantity) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		vmi, ok := o.(*kubevirtv1.VirtualMachineInstance)
+		if !ok {
+			return errors.New("not a instance of VirtualMachineInstance")
+		}
+		if vmi.Spec.Domain.Resources.Requests == nil {
+			vmi.Spec.Domain.Resources.Requests = make(corev1.ResourceList)
+		}
+		vmi.Spec.Domain.Resources.Requests[corev1.ResourceMemory] = memory
+		return nil
+	}
+}
+
+func WithHugepages(pageSize string) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		vmi, ok := o.(*kubevirtv1.VirtualMachineInstance)
+		if !ok {
+			return errors.New("not a instance of VirtualMachineInstance")
+		}
+		if vmi.Spec.Domain.Memory == nil {
+			vmi.Spec.Domain.Memory = &kubevirtv1.Memory{}
+		}
+		vmi.Spec.Domain.Memory.Hugepages = &kubevirtv1.Hugepages{
+			PageSize: pageSize,
+		}
+		return nil
+	}
+}
+
+func WithCPU(cores uint32, model string) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		vmi, ok := o.(*kubevirtv1.VirtualMachineInstance)
+		if !ok {
+			return errors.New("not a instance of VirtualMachineInstance")
+		}
+		if vmi.Spec.Domain.CPU == nil {
+			vmi.Spec.Domain.CPU = &kubevirtv1.CPU{}
+		}
+		vmi.Spec.Domain.CPU.Cores = cores
+		vmi.Spec.Domain.CPU.Model = model
+		return nil
+	}
+}
+
+// WithDedicatedCPUPlacement allocates dedicated physical CPUs to a VirtualMachineInstance. Since it requires the
+// Guaranteed QoS class, limits of CPU and memory are set to the same amount as requests.
+// It should be applied after resources of a VirtualMachineInstance are set.
+func WithDedicatedCPUPlacement() k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		vmi, ok := o.(*kubevirtv1.VirtualMachineInstance)
+		if !ok {
+			return errors.New("not a instance of VirtualMachineInstance")
+		}
+		if vmi.Spec.Domain.CPU == nil {
+			vmi.Spec.Domain.CPU = &kubevirtv1.CPU{}
+		}
+		vmi.Spec.Domain.CPU.DedicatedCPUPlacement = true
+
+		cores := vmi.Spec.Domain.CPU.Cores
+		if cores == 0 {
+			cores = 1
+		}
+		resources := &vmi.Spec.Domain.Resources
+		if resources.Requests == nil {
+			resources.Requests = make(corev1.ResourceList)
+		}
+		resources.Requests[corev1.ResourceCPU] = *resource.NewQuantity(int64(cores), resource.DecimalSI)
+		resources.Limits = resources.Requests.DeepCopy()
+		return nil
+	}
+}
+
+func WithNodeSelector(nodeSelector map[string]string) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		vmi, ok := o.(*kubevirtv1.VirtualMachineInstance)
+		if !ok {
+			return errors.New("not a instance of VirtualMachineInstance")
+		}
+		vmi.Spec.NodeSelector = make(map[string]string, len(nodeSelector))
+		for k, v := range nodeSelector {
+			vmi.Spec.NodeSelector[k] = v
+		}
+		return nil
+	}
+}
+
+func WithTolerations(tolerations []corev1.Toleration) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		vmi, ok := o.(*kubevirtv1.VirtualMachineInstance)
+		if !ok {
+			return errors.New("not a instance of VirtualMachineInstance")
+		}
+		vmi.Spec.Tolerations = make([]corev1.Toleration, len(tolerations))
+		for i := range tolerations {
+			tolerations[i].DeepCopyInto(&vmi.Spec.Tolerations[i])
+		}
+		return nil
+	}
+}
+
+func WithAffinity(affinity *corev1.Affinity) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		vmi, ok := o.(*kubevirtv1.VirtualMachineInstance)
+		if !ok {
+			return errors.New("not a instance of VirtualMachineInstance")
+		}
+		vmi.Spec.Affinity = affinity.DeepCopy()
+		return nil
+	}
+}
+
+func WithTopologySpreadConstraints(constraints []corev1.TopologySpreadConstraint) k8s_object.ObjectOption {
+	return func(o runtime.Object) error {
+		vmi, ok := o.(*kubevirtv1.VirtualMachineInstance)
+		if !ok {
+			return errors.New("not a instance of VirtualMachineInstance")
+		}
+		vmi.Spec.TopologySpreadConstraints = make([]corev1.TopologySpreadConstraint, len(constraints))
+		for i := range constraints {
+			constraints[i].DeepCopyInto(&vmi.Spec.TopologySpreadConstraints[i])
+		}
+		return nil
+	}
+}
+
 func WithReadinessTCPProbe(tcpAction *corev1.TCPSocketAction) k8s_object.ObjectOption {
 	return func(o runtime.Object) error {
 		vmi, ok := o.(*kubevirtv1.VirtualMachineInstance)
@@ -154,6 +333,22 @@ func WithReadinessTCPProbe(tcpAction *corev1.TCPSocketAction) k8s_object.ObjectO
 	}
 }
 
+// New returns a VirtualMachineInstance configured with options without creating it.
+func New(
+	name, namespace string,
+	opts ...k8s_object.ObjectOption,
+) (*kubevirtv1.VirtualMachineInstance, error) {
+	vmi := newDefaultVirtualMachineInstance()
+	vmi.Name = name
+	vmi.Namespace = namespace
+	for _, fn := range opts {
+		if err := fn(&vmi); err != nil {
+			return nil, err
+		}
+	}
+	return &vmi, nil
+}
+
 func CreateOnlyIfNotExist(
 	ctx context.Context,
 	c client.Client,
@@ -38,15 +38,20 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
 	kubernetesimalv1alpha1 "github.com/kkohtaka/kubernetesimal/api/v1alpha1"
 	"github.com/kkohtaka/kubernetesimal/controller/expectations"
 	"github.com/kkohtaka/kubernetesimal/controllers/etcd"
+	"github.com/kkohtaka/kubernetesimal/controllers/etcdbackup"
+	"github.com/kkohtaka/kubernetesimal/controllers/etcdbackupschedule"
 	"github.com/kkohtaka/kubernetesimal/controllers/etcdnode"
 	"github.com/kkohtaka/kubernetesimal/controllers/etcdnodedeployment"
 	"github.com/kkohtaka/kubernetesimal/controllers/etcdnodeset"
+	"github.com/kkohtaka/kubernetesimal/net/http"
 	"github.com/kkohtaka/kubernetesimal/observability/tracing"
 	//+kubebuilder:scaffold:imports
 )
@@ -73,6 +78,10 @@ func main() {
 		probeAddr              string
 		otlpAddr, otlpGRPCAddr string
 		configFile             string
+		backupVolumeDir        string
+		artifactAddr           string
+		artifactDir            string
+		artifactServerURL      string
 	)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -85,6 +94,17 @@ func main() {
 		"The controller will load its initial configuration from this file. "+
 			"Omit this flag to use the default configuration values. "+
 			"Command-line flags override configuration from this file.")
+	flag.StringVar(&backupVolumeDir, "backup-volume-dir", "/var/lib/kubernetesimal/backups",
+		"The directory where PersistentVolumeClaims for etcd backups are mounted with their names.")
+	flag.StringVar(&artifactAddr, "artifact-server-bind-address", "",
+		"The address the artifact server binds to. "+
+			"Omit this flag to disable the artifact server.")
+	flag.StringVar(&artifactDir, "artifact-dir", "/var/lib/kubernetesimal/artifacts",
+		"The directory where pre-cached release artifacts served by the artifact server are placed.")
+	flag.StringVar(&artifactServerURL, "artifact-server-url", "",
+		"The URL virtual machines reach the artifact server at. "+
+			"It's used as a mirror of release artifacts for EtcdNodes that don't specify their own mirror "+
+			"but specify checksums of release artifacts.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -96,9 +116,13 @@ func main() {
 	var err error
 	ctrlConfig := kubernetesimalv1alpha1.KubernetesimalConfig{}
 	ctrlOpts := ctrl.Options{
-		Scheme:                 scheme,
-		MetricsBindAddress:     metricsAddr,
-		Port:                   9443,
+		Scheme: scheme,
+		Metrics: metricsserver.Options{
+			BindAddress: metricsAddr,
+		},
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port: 9443,
+		}),
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "b197ccb6.kkohtaka.org",
@@ -133,9 +157,10 @@ func main() {
 	}
 
 	if err = (&etcd.Reconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Tracer: provider.Tracer("etcd-controller"),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("etcd-controller"),
+		Tracer:   provider.Tracer("etcd-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Etcd")
 		os.Exit(1)
@@ -152,10 +177,20 @@ func main() {
 		setupLog.Error(err, "unable to create webhook", "webhook", "Etcd")
 		os.Exit(1)
 	}
+	if err = (&kubernetesimalv1alpha1.EtcdBackup{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "EtcdBackup")
+		os.Exit(1)
+	}
+	if err = (&kubernetesimalv1alpha1.EtcdBackupSchedule{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "EtcdBackupSchedule")
+		os.Exit(1)
+	}
 	if err = (&etcdnode.Reconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Tracer: provider.Tracer("etcdnode-controller"),
+		Client:                   mgr.GetClient(),
+		Scheme:                   mgr.GetScheme(),
+		Tracer:                   provider.Tracer("etcdnode-controller"),
+		BackupVolumeDir:          backupVolumeDir,
+		DefaultArtifactMirrorURL: artifactServerURL,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "EtcdNode")
 		os.Exit(1)
@@ -187,8 +222,32 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "EtcdNodeDeployment")
 		os.Exit(1)
 	}
+	if err = (&etcdbackup.Reconciler{
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		Tracer:          provider.Tracer("etcdbackup-reconciler"),
+		BackupVolumeDir: backupVolumeDir,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "EtcdBackup")
+		os.Exit(1)
+	}
+	if err = (&etcdbackupschedule.Reconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Tracer: provider.Tracer("etcdbackupschedule-reconciler"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "EtcdBackupSchedule")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
+	if artifactAddr != "" {
+		if err := mgr.Add(http.NewArtifactServer(artifactAddr, artifactDir)); err != nil {
+			setupLog.Error(err, "unable to set up artifact server")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -0,0 +1,103 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package http
+
+import (
+	"context"
+	"errors"
+	"net"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	artifactServerReadHeaderTimeout = 10 * time.Second
+	artifactServerShutdownTimeout   = 30 * time.Second
+)
+
+// ArtifactServer serves pre-cached release artifacts in a directory over HTTP so that virtual machines without egress
+// to the internet can download them.
+type ArtifactServer struct {
+	bindAddress string
+	dir         string
+}
+
+// NewArtifactServer returns an ArtifactServer which serves files under dir on bindAddress.
+func NewArtifactServer(bindAddress, dir string) *ArtifactServer {
+	return &ArtifactServer{
+		bindAddress: bindAddress,
+		dir:         dir,
+	}
+}
+
+// Start implements manager.Runnable. It serves artifacts until ctx is done.
+func (s *ArtifactServer) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithValues("address", s.bindAddress, "dir", s.dir)
+
+	listener, err := net.Listen("tcp", s.bindAddress)
+	if err != nil {
+		return err
+	}
+
+	fileServer := nethttp.FileServer(nethttp.Dir(s.dir))
+	srv := &nethttp.Server{
+		Handler: nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			// Directory listings aren't served since guests only download artifacts by their exact paths.
+			if strings.HasSuffix(r.URL.Path, "/") {
+				nethttp.NotFound(w, r)
+				return
+			}
+			fileServer.ServeHTTP(w, r)
+		}),
+		ReadHeaderTimeout: artifactServerReadHeaderTimeout,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info("Starting an artifact server.")
+		errCh <- srv.Serve(listener)
+	}()
+
+	select {
+	case <-ctx.Done():
+		logger.Info("Shutting down an artifact server.")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), artifactServerShutdownTimeout)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	case err := <-errCh:
+		if errors.Is(err, nethttp.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Every replica of the manager serves artifacts.
+func (s *ArtifactServer) NeedLeaderElection() bool {
+	return false
+}
@@ -26,20 +26,32 @@ package pki
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/rand"
-	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"fmt"
 	"math/big"
+	"net"
 	"time"
 )
 
+const (
+	// memberCertificateValidity is how long certificates of etcd members are valid, which is the same as ones issued by
+	// etcdadm.
+	memberCertificateValidity = 365 * 24 * time.Hour
+)
+
 // CreateCACertificateAndPrivateKey creates a pair of self-signed certificate and private key for certificate authority
-// with the specified common name.
-func CreateCACertificateAndPrivateKey(name string) ([]byte, []byte, error) {
+// with the specified common name and key algorithm.
+func CreateCACertificateAndPrivateKey(name string, algorithm KeyAlgorithm) ([]byte, []byte, error) {
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
 	ca := &x509.Certificate{
-		SerialNumber: big.NewInt(2019),
+		SerialNumber: serialNumber,
 		Subject: pkix.Name{
 			CommonName: name,
 		},
@@ -54,12 +66,12 @@ func CreateCACertificateAndPrivateKey(name string) ([]byte, []byte, error) {
 		BasicConstraintsValid: true,
 	}
 
-	caPrivKey, err := rsa.GenerateKey(rand.Reader, 4096)
+	caPrivKey, err := GeneratePrivateKey(algorithm)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	caBytes, err := x509.CreateCertificate(rand.Reader, ca, ca, &caPrivKey.PublicKey, caPrivKey)
+	caBytes, err := x509.CreateCertificate(rand.Reader, ca, ca, caPrivKey.Public(), caPrivKey)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -72,25 +84,28 @@ func CreateCACertificateAndPrivateKey(name string) ([]byte, []byte, error) {
 		return nil, nil, err
 	}
 
-	caPrivKeyPEM := new(bytes.Buffer)
-	if err := pem.Encode(caPrivKeyPEM, &pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(caPrivKey),
-	}); err != nil {
+	caPrivKeyPEM, err := EncodePrivateKey(caPrivKey)
+	if err != nil {
 		return nil, nil, err
 	}
 
-	return caPEM.Bytes(), caPrivKeyPEM.Bytes(), nil
+	return caPEM.Bytes(), caPrivKeyPEM, nil
 }
 
-// CreateClientCertificateAndPrivateKey creates a pair of client certificate and private key signed by the specified CA.
+// CreateClientCertificateAndPrivateKey creates a pair of client certificate and private key with the specified key
+// algorithm signed by the specified CA.
 func CreateClientCertificateAndPrivateKey(
 	name string,
+	algorithm KeyAlgorithm,
 	caCert *x509.Certificate,
-	caPrivKey *rsa.PrivateKey,
+	caPrivKey crypto.Signer,
 ) ([]byte, []byte, error) {
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
 	cert := &x509.Certificate{
-		SerialNumber: big.NewInt(2019),
+		SerialNumber: serialNumber,
 		Subject: pkix.Name{
 			CommonName: name,
 		},
@@ -98,19 +113,19 @@ func CreateClientCertificateAndPrivateKey(
 			CommonName: name,
 		},
 		NotBefore:             time.Now(),
-		NotAfter:              time.Now().AddDate(10, 0, 0),
+		NotAfter:              capNotAfter(time.Now().AddDate(10, 0, 0), caCert),
 		IsCA:                  false,
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
 		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
 		BasicConstraintsValid: true,
 	}
 
-	certPrivKey, err := rsa.GenerateKey(rand.Reader, 4096)
+	certPrivKey, err := GeneratePrivateKey(algorithm)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	certBytes, err := x509.CreateCertificate(rand.Reader, cert, caCert, &certPrivKey.PublicKey, caPrivKey)
+	certBytes, err := x509.CreateCertificate(rand.Reader, cert, caCert, certPrivKey.Public(), caPrivKey)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -123,13 +138,170 @@ func CreateClientCertificateAndPrivateKey(
 		return nil, nil, err
 	}
 
-	certPrivKeyPEM := new(bytes.Buffer)
-	if err := pem.Encode(certPrivKeyPEM, &pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(certPrivKey),
+	certPrivKeyPEM, err := EncodePrivateKey(certPrivKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return certPEM.Bytes(), certPrivKeyPEM, nil
+}
+
+// RenewCertificateAndPrivateKey creates a pair of certificate and private key which inherits a subject, alternative
+// names, key usages and a key algorithm from the specified certificate, signed by the specified CA. The renewed
+// certificate is valid for the same duration as the specified one.
+func RenewCertificateAndPrivateKey(
+	cert *x509.Certificate,
+	caCert *x509.Certificate,
+	caPrivKey crypto.Signer,
+) ([]byte, []byte, error) {
+	algorithm, err := KeyAlgorithmOf(cert.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	return signCertificateAndPrivateKey(
+		algorithm,
+		&x509.Certificate{
+			Subject:     cert.Subject,
+			DNSNames:    cert.DNSNames,
+			IPAddresses: cert.IPAddresses,
+			NotBefore:   now,
+			NotAfter:    now.Add(cert.NotAfter.Sub(cert.NotBefore)),
+			ExtKeyUsage: cert.ExtKeyUsage,
+			KeyUsage:    cert.KeyUsage,
+		},
+		caCert,
+		caPrivKey,
+	)
+}
+
+// CreateServerCertificateAndPrivateKey creates a pair of server certificate and private key of an etcd member signed
+// by the specified CA. The certificate is valid for the specified DNS names and IP addresses, and its private key has
+// the same algorithm as the CA.
+func CreateServerCertificateAndPrivateKey(
+	name string,
+	dnsNames []string,
+	ipAddresses []net.IP,
+	caCert *x509.Certificate,
+	caPrivKey crypto.Signer,
+) ([]byte, []byte, error) {
+	algorithm, err := KeyAlgorithmOf(caCert.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	return signCertificateAndPrivateKey(
+		algorithm,
+		&x509.Certificate{
+			Subject: pkix.Name{
+				CommonName: name,
+			},
+			DNSNames:    dnsNames,
+			IPAddresses: ipAddresses,
+			NotBefore:   now,
+			NotAfter:    now.Add(memberCertificateValidity),
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+			KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		},
+		caCert,
+		caPrivKey,
+	)
+}
+
+// CreatePeerCertificateAndPrivateKey creates a pair of certificate and private key for peer communication of an etcd
+// member signed by the specified CA. The certificate is used both to serve and to connect to peers, and is valid for
+// the specified DNS names and IP addresses. Its private key has the same algorithm as the CA.
+func CreatePeerCertificateAndPrivateKey(
+	name string,
+	dnsNames []string,
+	ipAddresses []net.IP,
+	caCert *x509.Certificate,
+	caPrivKey crypto.Signer,
+) ([]byte, []byte, error) {
+	algorithm, err := KeyAlgorithmOf(caCert.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	return signCertificateAndPrivateKey(
+		algorithm,
+		&x509.Certificate{
+			Subject: pkix.Name{
+				CommonName: name,
+			},
+			DNSNames:    dnsNames,
+			IPAddresses: ipAddresses,
+			NotBefore:   now,
+			NotAfter:    now.Add(memberCertificateValidity),
+			ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+			KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		},
+		caCert,
+		caPrivKey,
+	)
+}
+
+// signCertificateAndPrivateKey creates a private key with the specified algorithm and a certificate from the
+// specified template signed by the specified CA.
+func signCertificateAndPrivateKey(
+	algorithm KeyAlgorithm,
+	cert *x509.Certificate,
+	caCert *x509.Certificate,
+	caPrivKey crypto.Signer,
+) ([]byte, []byte, error) {
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		return nil, nil, err
+	}
+	cert.SerialNumber = serialNumber
+	cert.BasicConstraintsValid = true
+	cert.NotAfter = capNotAfter(cert.NotAfter, caCert)
+
+	certPrivKey, err := GeneratePrivateKey(algorithm)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, cert, caCert, certPrivKey.Public(), caPrivKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certPEM := new(bytes.Buffer)
+	if err := pem.Encode(certPEM, &pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: certBytes,
 	}); err != nil {
 		return nil, nil, err
 	}
 
-	return certPEM.Bytes(), certPrivKeyPEM.Bytes(), nil
+	certPrivKeyPEM, err := EncodePrivateKey(certPrivKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return certPEM.Bytes(), certPrivKeyPEM, nil
+}
+
+// capNotAfter returns the specified expiry of a certificate, or the expiry of the CA signing it if the CA expires
+// earlier, since a certificate isn't trusted after the CA expires.
+func capNotAfter(notAfter time.Time, caCert *x509.Certificate) time.Time {
+	if caCert.NotAfter.Before(notAfter) {
+		return caCert.NotAfter
+	}
+	return notAfter
+}
+
+// ParseCertificate parses the first PEM-encoded certificate in the specified data.
+func ParseCertificate(data []byte) (*x509.Certificate, error) {
+	p, _ := pem.Decode(data)
+	if p == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	return x509.ParseCertificate(p.Bytes)
+}
+
+// newSerialNumber returns a random serial number so that certificates issued by the same CA don't share the same one.
+func newSerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
 }
@@ -0,0 +1,118 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package pki
+
+import (
+	"crypto/x509"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenewCertificateAndPrivateKey(t *testing.T) {
+	caCertPEM, caPrivKeyPEM, err := CreateCACertificateAndPrivateKey("ca", KeyAlgorithmRSA4096)
+	require.NoError(t, err)
+	caCert, err := ParseCertificate(caCertPEM)
+	require.NoError(t, err)
+	caPrivKey, err := ParsePrivateKey(caPrivKeyPEM)
+	require.NoError(t, err)
+
+	now := time.Now()
+	current := &x509.Certificate{
+		Subject:     caCert.Subject,
+		DNSNames:    []string{"etcd.default.svc"},
+		IPAddresses: []net.IP{net.ParseIP("10.0.0.1")},
+		NotBefore:   now.Add(-365 * 24 * time.Hour),
+		NotAfter:    now.Add(24 * time.Hour),
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+	}
+	current.Subject.CommonName = "server"
+	current.PublicKey = caCert.PublicKey
+
+	certPEM, _, err := RenewCertificateAndPrivateKey(current, caCert, caPrivKey)
+	require.NoError(t, err)
+	renewed, err := ParseCertificate(certPEM)
+	require.NoError(t, err)
+
+	assert.Equal(t, "server", renewed.Subject.CommonName)
+	assert.Equal(t, current.DNSNames, renewed.DNSNames)
+	assert.True(t, renewed.IPAddresses[0].Equal(current.IPAddresses[0]))
+	assert.Equal(t, current.ExtKeyUsage, renewed.ExtKeyUsage)
+	assert.WithinDuration(t, now.Add(366*24*time.Hour), renewed.NotAfter, time.Minute)
+	assert.NoError(t, renewed.CheckSignatureFrom(caCert))
+	assert.NotEqual(t, caCert.SerialNumber, renewed.SerialNumber)
+}
+
+func TestCreateServerCertificateAndPrivateKey(t *testing.T) {
+	caCertPEM, caPrivKeyPEM, err := CreateCACertificateAndPrivateKey("ca", KeyAlgorithmRSA4096)
+	require.NoError(t, err)
+	caCert, err := ParseCertificate(caCertPEM)
+	require.NoError(t, err)
+	caPrivKey, err := ParsePrivateKey(caPrivKeyPEM)
+	require.NoError(t, err)
+
+	certPEM, _, err := CreateServerCertificateAndPrivateKey(
+		"member",
+		[]string{"member", "etcd.default.svc"},
+		[]net.IP{net.ParseIP("10.0.0.1")},
+		caCert,
+		caPrivKey,
+	)
+	require.NoError(t, err)
+	cert, err := ParseCertificate(certPEM)
+	require.NoError(t, err)
+
+	assert.NoError(t, cert.CheckSignatureFrom(caCert))
+	assert.NoError(t, cert.VerifyHostname("etcd.default.svc"))
+	assert.NoError(t, cert.VerifyHostname("10.0.0.1"))
+	assert.Error(t, cert.VerifyHostname("10.0.0.2"))
+	assert.Contains(t, cert.ExtKeyUsage, x509.ExtKeyUsageServerAuth)
+}
+
+func TestCreateClientCertificateAndPrivateKeyWithExpiringCA(t *testing.T) {
+	caCertPEM, caPrivKeyPEM, err := CreateCACertificateAndPrivateKey("ca", KeyAlgorithmRSA4096)
+	require.NoError(t, err)
+	caCert, err := ParseCertificate(caCertPEM)
+	require.NoError(t, err)
+	caPrivKey, err := ParsePrivateKey(caPrivKeyPEM)
+	require.NoError(t, err)
+	caCert.NotAfter = time.Now().Add(24 * time.Hour).Truncate(time.Second)
+
+	certPEM, _, err := CreateClientCertificateAndPrivateKey("client", KeyAlgorithmRSA4096, caCert, caPrivKey)
+	require.NoError(t, err)
+	cert, err := ParseCertificate(certPEM)
+	require.NoError(t, err)
+	assert.True(t, caCert.NotAfter.Equal(cert.NotAfter))
+
+	certPEM, _, err = RenewCertificateAndPrivateKey(cert, caCert, caPrivKey)
+	require.NoError(t, err)
+	renewed, err := ParseCertificate(certPEM)
+	require.NoError(t, err)
+	assert.True(t, caCert.NotAfter.Equal(renewed.NotAfter))
+}
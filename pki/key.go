@@ -0,0 +1,140 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package pki
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// KeyAlgorithm is an algorithm of a private key of a certificate.
+type KeyAlgorithm string
+
+const (
+	KeyAlgorithmRSA2048   KeyAlgorithm = "RSA2048"
+	KeyAlgorithmRSA3072   KeyAlgorithm = "RSA3072"
+	KeyAlgorithmRSA4096   KeyAlgorithm = "RSA4096"
+	KeyAlgorithmECDSAP256 KeyAlgorithm = "ECDSAP256"
+	KeyAlgorithmECDSAP384 KeyAlgorithm = "ECDSAP384"
+
+	// DefaultKeyAlgorithm is a key algorithm used if no algorithm is specified.
+	DefaultKeyAlgorithm = KeyAlgorithmRSA4096
+)
+
+// GeneratePrivateKey generates a private key with the specified algorithm.
+func GeneratePrivateKey(algorithm KeyAlgorithm) (crypto.Signer, error) {
+	switch algorithm {
+	case KeyAlgorithmRSA2048:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case KeyAlgorithmRSA3072:
+		return rsa.GenerateKey(rand.Reader, 3072)
+	case KeyAlgorithmRSA4096, "":
+		return rsa.GenerateKey(rand.Reader, 4096)
+	case KeyAlgorithmECDSAP256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case KeyAlgorithmECDSAP384:
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported key algorithm %s", algorithm)
+	}
+}
+
+// KeyAlgorithmOf returns an algorithm of a private key which composes a pair with the specified public key.
+func KeyAlgorithmOf(publicKey crypto.PublicKey) (KeyAlgorithm, error) {
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		switch k.N.BitLen() {
+		case 2048:
+			return KeyAlgorithmRSA2048, nil
+		case 3072:
+			return KeyAlgorithmRSA3072, nil
+		case 4096:
+			return KeyAlgorithmRSA4096, nil
+		}
+		return "", fmt.Errorf("unsupported RSA key size %d", k.N.BitLen())
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return KeyAlgorithmECDSAP256, nil
+		case elliptic.P384():
+			return KeyAlgorithmECDSAP384, nil
+		}
+		return "", fmt.Errorf("unsupported ECDSA curve %s", k.Curve.Params().Name)
+	default:
+		return "", fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// EncodePrivateKey encodes the specified private key in PEM. RSA private keys are encoded in PKCS #1 to keep
+// compatibility with ones issued before, and others are encoded in PKCS #8.
+func EncodePrivateKey(privateKey crypto.Signer) ([]byte, error) {
+	if k, ok := privateKey.(*rsa.PrivateKey); ok {
+		return pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(k),
+		}), nil
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal a private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: der,
+	}), nil
+}
+
+// ParsePrivateKey parses the first PEM-encoded private key in the specified data. The private key can be encoded in
+// PKCS #1, PKCS #8 or SEC 1.
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	p, _ := pem.Decode(data)
+	if p == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	switch p.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(p.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(p.Bytes)
+	case "PRIVATE KEY":
+		k, err := x509.ParsePKCS8PrivateKey(p.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := k.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", k)
+		}
+		return signer, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %s", p.Type)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package pki
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivateKey(t *testing.T) {
+	for _, algorithm := range []KeyAlgorithm{
+		KeyAlgorithmRSA2048,
+		KeyAlgorithmECDSAP256,
+		KeyAlgorithmECDSAP384,
+	} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			privateKey, err := GeneratePrivateKey(algorithm)
+			require.NoError(t, err)
+			privateKeyPEM, err := EncodePrivateKey(privateKey)
+			require.NoError(t, err)
+
+			parsed, err := ParsePrivateKey(privateKeyPEM)
+			require.NoError(t, err)
+			parsedAlgorithm, err := KeyAlgorithmOf(parsed.Public())
+			require.NoError(t, err)
+			assert.Equal(t, algorithm, parsedAlgorithm)
+		})
+	}
+
+	_, err := GeneratePrivateKey("Ed25519")
+	assert.Error(t, err)
+}
+
+func TestCreateClientCertificateAndPrivateKeyWithECDSA(t *testing.T) {
+	caCertPEM, caPrivKeyPEM, err := CreateCACertificateAndPrivateKey("ca", KeyAlgorithmECDSAP256)
+	require.NoError(t, err)
+	caCert, err := ParseCertificate(caCertPEM)
+	require.NoError(t, err)
+	caPrivKey, err := ParsePrivateKey(caPrivKeyPEM)
+	require.NoError(t, err)
+
+	certPEM, privKeyPEM, err := CreateClientCertificateAndPrivateKey("client", KeyAlgorithmECDSAP384, caCert, caPrivKey)
+	require.NoError(t, err)
+	cert, err := ParseCertificate(certPEM)
+	require.NoError(t, err)
+	privKey, err := ParsePrivateKey(privKeyPEM)
+	require.NoError(t, err)
+
+	assert.NoError(t, cert.CheckSignatureFrom(caCert))
+	assert.Equal(t, cert.PublicKey, privKey.Public())
+	algorithm, err := KeyAlgorithmOf(cert.PublicKey)
+	require.NoError(t, err)
+	assert.Equal(t, KeyAlgorithmECDSAP384, algorithm)
+}
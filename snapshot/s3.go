@@ -0,0 +1,256 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package snapshot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	s3DefaultRegion = "us-east-1"
+
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+	s3SigningAlgorithm = "AWS4-HMAC-SHA256"
+)
+
+type S3SinkOption struct {
+	Region                string
+	Prefix                string
+	InsecureSkipTLSVerify bool
+	Now                   func() time.Time
+}
+
+func newDefaultS3SinkOption() *S3SinkOption {
+	return &S3SinkOption{
+		Region: s3DefaultRegion,
+		Now:    time.Now,
+	}
+}
+
+type s3Sink struct {
+	endpoint        *url.URL
+	bucket          string
+	accessKeyID     string
+	secretAccessKey string
+
+	opts   *S3SinkOption
+	client *http.Client
+}
+
+var _ Sink = &s3Sink{}
+
+// NewS3Sink returns a Sink that stores snapshots into a bucket of an S3-compatible object storage.
+// Requests are signed with AWS Signature Version 4 and sent with path-style URLs.
+func NewS3Sink(
+	endpoint, bucket, accessKeyID, secretAccessKey string,
+	opts ...func(*S3SinkOption),
+) (Sink, error) {
+	o := newDefaultS3SinkOption()
+	for _, fn := range opts {
+		fn(o)
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse an endpoint %q: %w", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme of an endpoint %q", endpoint)
+	}
+	if bucket == "" {
+		return nil, fmt.Errorf("a bucket must be specified")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if o.InsecureSkipTLSVerify {
+		transport.TLSClientConfig = &tls.Config{
+			InsecureSkipVerify: true,
+		}
+	}
+
+	return &s3Sink{
+		endpoint:        u,
+		bucket:          bucket,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		opts:            o,
+		client:          &http.Client{Transport: transport},
+	}, nil
+}
+
+func (s *s3Sink) objectKey(key string) string {
+	return strings.TrimPrefix(path.Join(s.opts.Prefix, key), "/")
+}
+
+func (s *s3Sink) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	u.Path = path.Join("/", u.Path, s.bucket, s.objectKey(key))
+	u.RawPath = ""
+	return &u
+}
+
+func (s *s3Sink) Save(ctx context.Context, key string, r io.Reader, size int64) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), r)
+	if err != nil {
+		return "", fmt.Errorf("unable to create a request: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := s.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", newS3Error(resp)
+	}
+	return fmt.Sprintf("s3://%s/%s", s.bucket, s.objectKey(key)), nil
+}
+
+func (s *s3Sink) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key).String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create a request: %w", err)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, newS3Error(resp)
+	}
+	return resp.Body, nil
+}
+
+func (s *s3Sink) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key).String(), nil)
+	if err != nil {
+		return fmt.Errorf("unable to create a request: %w", err)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return newS3Error(resp)
+	}
+}
+
+func (s *s3Sink) do(req *http.Request) (*http.Response, error) {
+	s.sign(req, s.opts.Now().UTC())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to send a request to %s: %w", req.URL.Host, err)
+	}
+	return resp, nil
+}
+
+// sign signs a request with AWS Signature Version 4. A payload isn't included in a signature so that a snapshot can be
+// streamed without being read twice.
+func (s *s3Sink) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join(
+		[]string{
+			req.Method,
+			req.URL.EscapedPath(),
+			req.URL.Query().Encode(),
+			"host:" + req.URL.Host,
+			"x-amz-content-sha256:" + s3UnsignedPayload,
+			"x-amz-date:" + amzDate,
+			"",
+			signedHeaders,
+			s3UnsignedPayload,
+		},
+		"\n",
+	)
+
+	scope := strings.Join([]string{date, s.opts.Region, "s3", "aws4_request"}, "/")
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join(
+		[]string{
+			s3SigningAlgorithm,
+			amzDate,
+			scope,
+			hex.EncodeToString(canonicalRequestHash[:]),
+		},
+		"\n",
+	)
+
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), date)
+	key = hmacSHA256(key, s.opts.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set(
+		"Authorization",
+		fmt.Sprintf(
+			"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+			s3SigningAlgorithm,
+			s.accessKeyID,
+			scope,
+			signedHeaders,
+			signature,
+		),
+	)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func newS3Error(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("unexpected response from %s: %s: %s", resp.Request.URL.Host, resp.Status, string(body))
+}
@@ -0,0 +1,42 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package snapshot
+
+import (
+	"context"
+	"io"
+)
+
+// Sink is a destination where etcd snapshots are stored.
+type Sink interface {
+	// Save stores a snapshot read from r with the specified key and returns a URL of the stored snapshot.
+	Save(ctx context.Context, key string, r io.Reader, size int64) (string, error)
+
+	// Open opens a snapshot stored with the specified key.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete deletes a snapshot stored with the specified key. It succeeds even if the snapshot doesn't exist.
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,128 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package snapshot_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kkohtaka/kubernetesimal/snapshot"
+)
+
+type fakeS3Server struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *fakeS3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access-key-id/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		s.objects[r.URL.Path] = data
+	case http.MethodGet:
+		data, ok := s.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	case http.MethodDelete:
+		delete(s.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestSink(t *testing.T) {
+	server := httptest.NewServer(&fakeS3Server{objects: map[string][]byte{}})
+	defer server.Close()
+
+	s3Sink, err := snapshot.NewS3Sink(
+		server.URL,
+		"backups",
+		"access-key-id",
+		"secret-access-key",
+		func(o *snapshot.S3SinkOption) {
+			o.Prefix = "etcd"
+		},
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		sink         snapshot.Sink
+		wantLocation string
+	}{
+		{
+			name:         "a file sink",
+			sink:         snapshot.NewFileSink(t.TempDir()),
+			wantLocation: "file://",
+		},
+		{
+			name:         "an S3 sink",
+			sink:         s3Sink,
+			wantLocation: "s3://backups/etcd/default/snapshot.db",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			data := []byte("snapshot")
+
+			location, err := tt.sink.Save(ctx, "default/snapshot.db", bytes.NewReader(data), int64(len(data)))
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(location, tt.wantLocation))
+
+			r, err := tt.sink.Open(ctx, "default/snapshot.db")
+			require.NoError(t, err)
+			got, err := io.ReadAll(r)
+			r.Close()
+			require.NoError(t, err)
+			assert.Equal(t, data, got)
+
+			assert.NoError(t, tt.sink.Delete(ctx, "default/snapshot.db"))
+			assert.NoError(t, tt.sink.Delete(ctx, "default/snapshot.db"))
+
+			_, err = tt.sink.Open(ctx, "default/snapshot.db")
+			assert.Error(t, err)
+		})
+	}
+}
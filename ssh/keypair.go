@@ -25,6 +25,10 @@ SOFTWARE.
 package ssh
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -35,26 +39,79 @@ import (
 	"golang.org/x/crypto/ssh"
 )
 
+// KeyType is a type of an SSH key.
+type KeyType string
+
+const (
+	KeyTypeRSA     KeyType = "rsa"
+	KeyTypeECDSA   KeyType = "ecdsa"
+	KeyTypeEd25519 KeyType = "ed25519"
+)
+
+// KeyPairOption is an option to generate an SSH key-pair. BitSize is a key size for RSA keys, or a curve size for
+// ECDSA keys, and is ignored for Ed25519 keys.
 type KeyPairOption struct {
+	Type    KeyType
 	BitSize int
 	Random  io.Reader
 }
 
 func newDefaultKeyPairOption() *KeyPairOption {
 	return &KeyPairOption{
+		Type:    KeyTypeRSA,
 		BitSize: 4096,
 		Random:  rand.Reader,
 	}
 }
 
-func newPrivateKeyPEM(privateKey *rsa.PrivateKey) []byte {
-	der := x509.MarshalPKCS1PrivateKey(privateKey)
-	block := pem.Block{
-		Type:    "RSA PRIVATE KEY",
+func newPrivateKey(o *KeyPairOption) (crypto.Signer, error) {
+	switch o.Type {
+	case KeyTypeRSA:
+		return rsa.GenerateKey(o.Random, o.BitSize)
+	case KeyTypeECDSA:
+		switch o.BitSize {
+		case 256:
+			return ecdsa.GenerateKey(elliptic.P256(), o.Random)
+		case 384:
+			return ecdsa.GenerateKey(elliptic.P384(), o.Random)
+		case 521:
+			return ecdsa.GenerateKey(elliptic.P521(), o.Random)
+		}
+		return nil, fmt.Errorf("unsupported ECDSA curve size %d", o.BitSize)
+	case KeyTypeEd25519:
+		_, privateKey, err := ed25519.GenerateKey(o.Random)
+		return privateKey, err
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", o.Type)
+	}
+}
+
+// newPrivateKeyPEM encodes RSA private keys in PKCS #1, Ed25519 private keys in the OpenSSH format so that sshd can
+// load them as host keys, and others in PKCS #8.
+func newPrivateKeyPEM(privateKey crypto.Signer) ([]byte, error) {
+	switch k := privateKey.(type) {
+	case *rsa.PrivateKey:
+		return pem.EncodeToMemory(&pem.Block{
+			Type:    "RSA PRIVATE KEY",
+			Headers: nil,
+			Bytes:   x509.MarshalPKCS1PrivateKey(k),
+		}), nil
+	case ed25519.PrivateKey:
+		block, err := ssh.MarshalPrivateKey(k, "")
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(block), nil
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{
+		Type:    "PRIVATE KEY",
 		Headers: nil,
 		Bytes:   der,
-	}
-	return pem.EncodeToMemory(&block)
+	}), nil
 }
 
 func GenerateKeyPair(opts ...func(*KeyPairOption)) ([]byte, []byte, error) {
@@ -63,15 +120,20 @@ func GenerateKeyPair(opts ...func(*KeyPairOption)) ([]byte, []byte, error) {
 		fn(o)
 	}
 
-	privateKey, err := rsa.GenerateKey(o.Random, o.BitSize)
+	privateKey, err := newPrivateKey(o)
 	if err != nil {
 		return nil, nil, fmt.Errorf("couldn't generate a private key: %w", err)
 	}
 
-	publicKey, err := ssh.NewPublicKey(&privateKey.PublicKey)
+	publicKey, err := ssh.NewPublicKey(privateKey.Public())
 	if err != nil {
 		return nil, nil, fmt.Errorf("couldn't retrieve a public key from a private key: %w", err)
 	}
 
-	return newPrivateKeyPEM(privateKey), ssh.MarshalAuthorizedKey(publicKey), nil
+	privateKeyPEM, err := newPrivateKeyPEM(privateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't encode a private key: %w", err)
+	}
+
+	return privateKeyPEM, ssh.MarshalAuthorizedKey(publicKey), nil
 }
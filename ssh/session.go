@@ -28,33 +28,170 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"net"
+	"strconv"
 
 	"golang.org/x/crypto/ssh"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-func StartSSHConnection(_ context.Context, privateKey []byte, address string, port int) (*ssh.Client, func(), error) {
-	signer, err := ssh.ParsePrivateKey(privateKey)
+const (
+	// DefaultUser is a user who logs in to an SSH server if no user is specified.
+	DefaultUser = "fedora"
+	// DefaultPort is a port of an SSH server used if no port is specified.
+	DefaultPort = 22
+)
+
+// Host is an SSH server to connect to.
+type Host struct {
+	Address string
+	Port    int
+	User    string
+	// PrivateKey is a PEM-encoded private key to log in to the server.
+	PrivateKey []byte
+	// HostKey is a public host key which the server must present, in the authorized_keys format.
+	HostKey []byte
+}
+
+// StartSSHConnection starts an SSH connection to the specified host. If jump hosts are specified, the connection goes
+// through them in order like ProxyJump of OpenSSH. Dialing and handshakes are aborted when the context is done.
+func StartSSHConnection(ctx context.Context, host *Host, jumpHosts ...*Host) (*ssh.Client, func(), error) {
+	var clients []*ssh.Client
+	closer := func() {
+		for i := len(clients) - 1; i >= 0; i-- {
+			clients[i].Close()
+		}
+	}
+
+	var via *ssh.Client
+	for _, h := range append(append([]*Host{}, jumpHosts...), host) {
+		client, err := dial(ctx, via, h)
+		if err != nil {
+			closer()
+			return nil, nil, err
+		}
+		clients = append(clients, client)
+		via = client
+	}
+	return via, closer, nil
+}
+
+// dial connects to an SSH server directly, or through the specified client if it's not nil.
+func dial(ctx context.Context, via *ssh.Client, host *Host) (*ssh.Client, error) {
+	config, err := newClientConfig(host)
+	if err != nil {
+		return nil, err
+	}
+
+	port := host.Port
+	if port == 0 {
+		port = DefaultPort
+	}
+	address := net.JoinHostPort(host.Address, strconv.Itoa(port))
+
+	var conn net.Conn
+	if via == nil {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = via.DialContext(ctx, "tcp", address)
+	}
 	if err != nil {
-		return nil, nil, fmt.Errorf("unable to parse private key: %w", err)
+		return nil, fmt.Errorf("could not dial %s: %w", address, err)
 	}
 
-	config := &ssh.ClientConfig{
-		User: "fedora",
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	c, chans, reqs, err := ssh.NewClientConn(conn, address, config)
+	if !stop() {
+		if err == nil {
+			c.Close()
+		}
+		return nil, fmt.Errorf("could not complete an SSH handshake with %s: %w", address, ctx.Err())
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("could not complete an SSH handshake with %s: %w", address, err)
+	}
+	return ssh.NewClient(c, chans, reqs), nil
+}
+
+func newClientConfig(host *Host) (*ssh.ClientConfig, error) {
+	signer, err := ssh.ParsePrivateKey(host.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse private key: %w", err)
+	}
+
+	hostPublicKey, _, _, _, err := ssh.ParseAuthorizedKey(host.HostKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse host key: %w", err)
+	}
+	// Only the algorithm of the host key is negotiated so that the server doesn't present a host key of another type.
+	hostKeyAlgorithms := []string{hostPublicKey.Type()}
+	if hostPublicKey.Type() == ssh.KeyAlgoRSA {
+		hostKeyAlgorithms = []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256}
+	}
+
+	user := host.User
+	if user == "" {
+		user = DefaultUser
+	}
+
+	return &ssh.ClientConfig{
+		User: user,
 		Auth: []ssh.AuthMethod{
 			ssh.PublicKeys(signer),
 		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		HostKeyCallback:   ssh.FixedHostKey(hostPublicKey),
+		HostKeyAlgorithms: hostKeyAlgorithms,
+	}, nil
+}
+
+func RunCommandOverSSHSession(ctx context.Context, client *ssh.Client, cmd string) error {
+	logger := log.FromContext(ctx)
+
+	session, err := client.NewSession()
+	if err != nil {
+		return fmt.Errorf("could not create SSH session: %w", err)
 	}
+	defer session.Close()
 
-	client, err := ssh.Dial("tcp", fmt.Sprintf("%s:%d", address, port), config)
+	var out, errOut bytes.Buffer
+	session.Stdout = &out
+	session.Stderr = &errOut
+	if err := session.Run(cmd); err != nil {
+		logger.Error(err, "Could not complete a command", "cmd", cmd, "errOut", errOut.String())
+		return fmt.Errorf("unable to complete a command: %w", err)
+	}
+	logger.V(4).Info("Succeeded in completing a command", "cmd", cmd, "out", out.String())
+	return nil
+}
+
+// OutputCommandOverSSHSession runs a command and returns its standard output.
+func OutputCommandOverSSHSession(ctx context.Context, client *ssh.Client, cmd string) ([]byte, error) {
+	logger := log.FromContext(ctx)
+
+	session, err := client.NewSession()
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not dial: %w", err)
+		return nil, fmt.Errorf("could not create SSH session: %w", err)
 	}
-	return client, func() { client.Close() }, nil
+	defer session.Close()
+
+	var out, errOut bytes.Buffer
+	session.Stdout = &out
+	session.Stderr = &errOut
+	if err := session.Run(cmd); err != nil {
+		logger.Error(err, "Could not complete a command", "cmd", cmd, "errOut", errOut.String())
+		return nil, fmt.Errorf("unable to complete a command: %w", err)
+	}
+	logger.V(4).Info("Succeeded in completing a command", "cmd", cmd, "out", out.String())
+	return out.Bytes(), nil
 }
 
-func RunCommandOverSSHSession(ctx context.Context, client *ssh.Client, cmd string) error {
+// RunCommandWithInputOverSSHSession runs a command with feeding data from a reader into its standard input.
+func RunCommandWithInputOverSSHSession(ctx context.Context, client *ssh.Client, cmd string, r io.Reader) error {
 	logger := log.FromContext(ctx)
 
 	session, err := client.NewSession()
@@ -64,6 +201,7 @@ func RunCommandOverSSHSession(ctx context.Context, client *ssh.Client, cmd strin
 	defer session.Close()
 
 	var out, errOut bytes.Buffer
+	session.Stdin = r
 	session.Stdout = &out
 	session.Stderr = &errOut
 	if err := session.Run(cmd); err != nil {
@@ -0,0 +1,153 @@
+/*
+MIT License
+
+Copyright (c) 2022 Kazumasa Kohtaka
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package ssh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// MaxStepOutputLength is the maximum length of an output of a step which is kept in a result.
+const MaxStepOutputLength = 1024
+
+// Step is a step of provisioning a remote host over SSH. A step should be idempotent so that provisioning can be
+// resumed from a failed step.
+type Step struct {
+	// Name identifies the step.
+	Name string
+	// Command is a command run on a remote host.
+	Command string
+	// Func is run instead of Command if it's specified, e.g. to transfer files.
+	Func func(ctx context.Context, client *ssh.Client) error
+}
+
+// StepResult is a result of running a step.
+type StepResult struct {
+	// Name is the name of the step.
+	Name string
+	// ExitCode is an exit status of a command of the step. It's nil if the step has no command or the command didn't
+	// exit with a status.
+	ExitCode *int
+	// Output is the tail of combined standard output and standard error of a command of the step.
+	Output string
+	// Err is an error which the step failed with. It's nil if the step succeeded.
+	Err error
+}
+
+// RunSteps runs steps in order, skipping steps whose names are in completed. It returns results of steps which were
+// run, and stops at the first failed step.
+func RunSteps(
+	ctx context.Context,
+	client *ssh.Client,
+	steps []Step,
+	completed map[string]bool,
+) ([]StepResult, error) {
+	logger := log.FromContext(ctx)
+
+	var results []StepResult
+	for _, step := range steps {
+		if completed[step.Name] {
+			logger.V(4).Info("Skip a step since it's already completed", "step", step.Name)
+			continue
+		}
+
+		result := runStep(ctx, client, &step)
+		results = append(results, result)
+		if result.Err != nil {
+			return results, fmt.Errorf("unable to complete a step %s: %w", step.Name, result.Err)
+		}
+		logger.V(2).Info("Succeeded in completing a step", "step", step.Name)
+	}
+	return results, nil
+}
+
+func runStep(ctx context.Context, client *ssh.Client, step *Step) StepResult {
+	result := StepResult{
+		Name: step.Name,
+	}
+	if step.Func != nil {
+		result.Err = step.Func(ctx, client)
+		return result
+	}
+
+	session, err := client.NewSession()
+	if err != nil {
+		result.Err = fmt.Errorf("could not create SSH session: %w", err)
+		return result
+	}
+	defer session.Close()
+
+	out := &tailBuffer{max: MaxStepOutputLength}
+	session.Stdout = out
+	session.Stderr = out
+	err = session.Run(step.Command)
+	result.Output = out.String()
+
+	var exitErr *ssh.ExitError
+	switch {
+	case err == nil:
+		exitCode := 0
+		result.ExitCode = &exitCode
+	case errors.As(err, &exitErr):
+		exitCode := exitErr.ExitStatus()
+		result.ExitCode = &exitCode
+		result.Err = fmt.Errorf("command exited with status %d", exitCode)
+	default:
+		result.Err = fmt.Errorf("unable to complete a command: %w", err)
+	}
+	return result
+}
+
+// tailBuffer is a writer which keeps only the last max bytes written into it.
+type tailBuffer struct {
+	mu  sync.Mutex
+	max int
+	buf []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > b.max {
+		b.buf = b.buf[len(b.buf)-b.max:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// The head of the output might be cut in the middle of a multi-byte character.
+	return strings.ToValidUTF8(string(b.buf), "")
+}